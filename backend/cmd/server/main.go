@@ -9,6 +9,7 @@ import (
 	"github.com/airmass/backend/internal/config"
 	"github.com/airmass/backend/internal/database"
 	"github.com/airmass/backend/internal/fcm"
+	"github.com/airmass/backend/internal/payments"
 	"github.com/airmass/backend/internal/router"
 	"github.com/airmass/backend/internal/websocket"
 	"github.com/airmass/backend/internal/worker"
@@ -51,19 +52,31 @@ func main() {
 		log.Printf("Warning: Failed to initialize FCM: %v", err)
 	}
 
+	// Payment provider is shared by the API and the workers, which issue refunds
+	paymentProvider, err := payments.NewProvider(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize payment provider: %v", err)
+	}
+
 	// Initialize WebSocket hub
 	hub := websocket.NewHub()
 	go hub.Run()
 
 	// Initialize and start background workers
-	auctionWorker := worker.NewAuctionWorker(db, hub, fcmService)
+	auctionWorker := worker.NewAuctionWorker(db, hub, fcmService, paymentProvider)
 	go auctionWorker.Start(ctx)
 
 	badgeWorker := worker.NewBadgeWorker(db)
 	go badgeWorker.Start(ctx)
 
+	fraudWorker := worker.NewFraudWorker(db)
+	go fraudWorker.Start(ctx)
+
+	jobWorker := worker.NewJobWorker(db)
+	go jobWorker.Start(ctx)
+
 	// Setup router
-	r := router.SetupRouter(db, jwtService, hub, cfg)
+	r := router.SetupRouter(db, jwtService, hub, cfg, paymentProvider)
 
 	// Start server
 	log.Printf("🚀 AirMass API Server starting on port %s", cfg.Port)
@@ -3,18 +3,24 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
 
+// DefaultSecret is the placeholder used for unset secrets; it must be replaced
+// before running in release mode
+const DefaultSecret = "change-me-in-production"
+
 type Config struct {
-	Port           string
-	GinMode        string
-	DatabaseURL    string
-	JWTSecret      string
-	JWTExpiryHours int
-	UploadDir      string
-	MaxUploadSize  int64
+	Port             string
+	GinMode          string
+	DatabaseURL      string
+	JWTSecret        string
+	JWTExpiryHours   int
+	RefreshTokenDays int // Sessions end after this many days without a refresh
+	UploadDir        string
+	MaxUploadSize    int64
 
 	// Supabase
 	SupabaseProjectID  string
@@ -32,7 +38,18 @@ type Config struct {
 	FirebaseServiceAccountPath string
 
 	// App
-	PublicURL string
+	PublicURL      string
+	TrustedProxies []string // Proxies allowed to set X-Forwarded-For; none by default
+
+	// Payments
+	PaymentProvider      string // "fake" (default) until a real provider is configured; refused in release mode
+	PaymentCurrency      string
+	PaymentWebhookSecret string
+	PaymentFakeStateFile string // Keeps fake payments across restarts; empty for in-memory only
+
+	// Internal endpoints
+	JobToken            string // Sent as X-Job-Token by cron and ops tooling calling /api/jobs; empty disables it
+	EnableTestEndpoints bool   // Registers /api/test; never in release mode
 
 	// Feature Flags
 	EnablePhoneAuth bool // Set to true to enable Firebase SMS phone authentication
@@ -42,14 +59,16 @@ func Load() (*Config, error) {
 	godotenv.Load()
 
 	jwtExpiry, _ := strconv.Atoi(getEnv("JWT_EXPIRY_HOURS", "24"))
+	refreshDays, _ := strconv.Atoi(getEnv("REFRESH_TOKEN_DAYS", "30"))
 	maxUpload, _ := strconv.ParseInt(getEnv("MAX_UPLOAD_SIZE", "10485760"), 10, 64)
 
 	return &Config{
 		Port:               getEnv("PORT", "8080"),
 		GinMode:            getEnv("GIN_MODE", "debug"),
 		DatabaseURL:        getEnv("DATABASE_URL", ""),
-		JWTSecret:          getEnv("JWT_SECRET", "change-me-in-production"),
+		JWTSecret:          getEnv("JWT_SECRET", DefaultSecret),
 		JWTExpiryHours:     jwtExpiry,
+		RefreshTokenDays:   refreshDays,
 		UploadDir:          getEnv("UPLOAD_DIR", "./uploads"),
 		MaxUploadSize:      maxUpload,
 		SupabaseProjectID:  getEnv("SUPABASE_PROJECT_ID", ""),
@@ -64,13 +83,28 @@ func Load() (*Config, error) {
 		// Firebase
 		FirebaseServiceAccountPath: getEnv("FIREBASE_SERVICE_ACCOUNT_PATH", "./servicekey.json"),
 		// App
-		PublicURL: getEnv("PUBLIC_URL", "http://localhost:8080"),
+		PublicURL:      getEnv("PUBLIC_URL", "http://localhost:8080"),
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+		// Payments
+		PaymentProvider:      getEnv("PAYMENT_PROVIDER", "fake"),
+		PaymentCurrency:      getEnv("PAYMENT_CURRENCY", "ZAR"),
+		PaymentWebhookSecret: getEnv("PAYMENT_WEBHOOK_SECRET", DefaultSecret),
+		PaymentFakeStateFile: getEnv("PAYMENT_FAKE_STATE_FILE", ""),
+
+		// Internal endpoints
+		JobToken:            getEnv("JOB_TOKEN", ""),
+		EnableTestEndpoints: getEnvBool("ENABLE_TEST_ENDPOINTS", false),
 
 		// Feature Flags
 		EnablePhoneAuth: getEnvBool("ENABLE_PHONE_AUTH", false), // Disabled by default (Firebase SMS is paid)
 	}, nil
 }
 
+// IsRelease reports whether the server runs in release (production) mode
+func (c *Config) IsRelease() bool {
+	return c.GinMode == "release"
+}
+
 func getEnv(key, defaultValue string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -78,6 +112,17 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getEnvList reads a comma-separated list, skipping empty entries
+func getEnvList(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
 func getEnvBool(key string, defaultValue bool) bool {
 	if value, exists := os.LookupEnv(key); exists {
 		boolVal, err := strconv.ParseBool(value)
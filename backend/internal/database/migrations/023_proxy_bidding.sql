@@ -0,0 +1,11 @@
+-- Proxy bidding is resolved in Go (services.BiddingService) inside the bid transaction.
+-- The old SQL implementation was never called and only handled a single responder.
+DROP FUNCTION IF EXISTS process_auto_bids(UUID, DECIMAL, UUID);
+
+-- Auto-bids are always loaded per auction ordered by strength
+CREATE INDEX IF NOT EXISTS idx_auto_bids_auction_strength
+    ON auto_bids(auction_id, max_amount DESC, created_at ASC)
+    WHERE is_active = TRUE;
+
+-- Auto-bidding is live now that the engine runs on every bid
+UPDATE feature_flags SET is_enabled = TRUE WHERE feature_name = 'auto_bidding';
@@ -0,0 +1,99 @@
+-- Bid increment ladders can be overridden per category and/or per town.
+-- Tiers with neither set form the global ladder. The most specific scope that has
+-- active tiers wins: category+town, then category, then town, then global.
+-- services.BidIncrementService applies the same rules in Go.
+ALTER TABLE bid_increment_tiers ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES categories(id) ON DELETE CASCADE;
+ALTER TABLE bid_increment_tiers ADD COLUMN IF NOT EXISTS town_id UUID REFERENCES towns(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_bid_increment_tiers_scope ON bid_increment_tiers(category_id, town_id) WHERE is_active = TRUE;
+
+-- Scoped increment lookup
+CREATE OR REPLACE FUNCTION get_bid_increment(current_price DECIMAL(10,2), category_id_param UUID, town_id_param UUID)
+RETURNS DECIMAL(10,2) AS $$
+DECLARE
+    scope_category UUID;
+    scope_town UUID;
+    increment DECIMAL(10,2);
+BEGIN
+    -- Pick the most specific scope with at least one active tier
+    SELECT t.category_id, t.town_id INTO scope_category, scope_town
+    FROM bid_increment_tiers t
+    WHERE t.is_active = TRUE
+    AND (t.category_id IS NULL OR t.category_id = category_id_param)
+    AND (t.town_id IS NULL OR t.town_id = town_id_param)
+    ORDER BY (t.category_id IS NOT NULL) DESC, (t.town_id IS NOT NULL) DESC
+    LIMIT 1;
+
+    SELECT t.increment INTO increment
+    FROM bid_increment_tiers t
+    WHERE t.is_active = TRUE
+    AND t.category_id IS NOT DISTINCT FROM scope_category
+    AND t.town_id IS NOT DISTINCT FROM scope_town
+    AND current_price >= t.min_price
+    AND (t.max_price IS NULL OR current_price <= t.max_price)
+    ORDER BY t.min_price DESC
+    LIMIT 1;
+
+    -- Default to $1 if no tier found
+    RETURN COALESCE(increment, 1.00);
+END;
+$$ LANGUAGE plpgsql;
+
+-- The single-argument form keeps resolving against the global ladder
+CREATE OR REPLACE FUNCTION get_bid_increment(current_price DECIMAL(10,2))
+RETURNS DECIMAL(10,2) AS $$
+BEGIN
+    RETURN get_bid_increment(current_price, NULL, NULL);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Store the increment for the auction's own scope on every bid
+CREATE OR REPLACE FUNCTION update_auction_on_bid_v2()
+RETURNS TRIGGER AS $$
+DECLARE
+    new_increment DECIMAL(10,2);
+    auction_category UUID;
+    auction_town UUID;
+BEGIN
+    SELECT category_id, town_id INTO auction_category, auction_town
+    FROM auctions WHERE id = NEW.auction_id;
+
+    new_increment := get_bid_increment(NEW.amount, auction_category, auction_town);
+
+    UPDATE auctions
+    SET current_price = NEW.amount,
+        total_bids = total_bids + 1,
+        bid_increment = new_increment,
+        updated_at = NOW()
+    WHERE id = NEW.auction_id;
+
+    -- Mark previous winning bid as not winning
+    UPDATE bids
+    SET is_winning = FALSE
+    WHERE auction_id = NEW.auction_id
+    AND id != NEW.id
+    AND is_winning = TRUE;
+
+    -- Mark new bid as winning
+    NEW.is_winning = TRUE;
+
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Next valid bid for an auction, in the auction's scope
+CREATE OR REPLACE FUNCTION get_next_bid_amount(auction_id_param UUID)
+RETURNS DECIMAL(10,2) AS $$
+DECLARE
+    current DECIMAL(10,2);
+    auction_category UUID;
+    auction_town UUID;
+BEGIN
+    SELECT COALESCE(current_price, starting_price), category_id, town_id
+    INTO current, auction_category, auction_town
+    FROM auctions
+    WHERE id = auction_id_param;
+
+    RETURN current + get_bid_increment(current, auction_category, auction_town);
+END;
+$$ LANGUAGE plpgsql;
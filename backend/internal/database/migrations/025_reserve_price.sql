@@ -0,0 +1,28 @@
+-- Reserve price enforcement
+-- Auction status types now also include: reserve_not_met
+-- (ended with bids, but the highest bid was below the seller's reserve)
+
+-- Final hammer price, written by the auction worker when an auction ends
+ALTER TABLE auctions ADD COLUMN IF NOT EXISTS final_amount DECIMAL(10,2);
+
+-- Second-chance offers: after a reserve_not_met ending the seller can offer the item
+-- to the top bidder at a price up to the reserve
+CREATE TABLE IF NOT EXISTS second_chance_offers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    bidder_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, accepted, declined, expired
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Only one open or accepted offer per auction
+CREATE UNIQUE INDEX IF NOT EXISTS idx_second_chance_offers_auction_open
+    ON second_chance_offers(auction_id) WHERE status IN ('pending', 'accepted');
+CREATE INDEX IF NOT EXISTS idx_second_chance_offers_bidder ON second_chance_offers(bidder_id, status);
+CREATE INDEX IF NOT EXISTS idx_second_chance_offers_expiry ON second_chance_offers(expires_at) WHERE status = 'pending';
+
+-- Notification types added: reserve_not_met, second_chance_offer, second_chance_declined
@@ -0,0 +1,27 @@
+-- Make-an-offer workflow for auctions with allow_offers
+CREATE TABLE IF NOT EXISTS auction_offers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL,
+    counter_amount DECIMAL(10,2),
+    message TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    -- pending: waiting for the seller, countered: waiting for the buyer,
+    -- accepted, declined, expired
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- A buyer has at most one open offer per auction
+CREATE UNIQUE INDEX IF NOT EXISTS idx_auction_offers_open
+    ON auction_offers(auction_id, buyer_id) WHERE status IN ('pending', 'countered');
+CREATE INDEX IF NOT EXISTS idx_auction_offers_auction ON auction_offers(auction_id, status);
+CREATE INDEX IF NOT EXISTS idx_auction_offers_buyer ON auction_offers(buyer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auction_offers_seller ON auction_offers(seller_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_auction_offers_expiry ON auction_offers(expires_at) WHERE status IN ('pending', 'countered');
+
+-- Notification types added: offer_received, offer_countered, offer_accepted, offer_declined, offer_expired
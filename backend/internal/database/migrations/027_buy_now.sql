@@ -0,0 +1,7 @@
+-- Buy It Now: optional fixed price that ends the auction immediately
+ALTER TABLE auctions ADD COLUMN IF NOT EXISTS buy_now_price DECIMAL(10,2);
+
+-- Buy-now is withdrawn once bidding reaches this percentage of the buy-now price
+INSERT INTO app_settings (key, value) VALUES
+('buy_now_disable_percent', '50')
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,11 @@
+-- Drafts and scheduled auctions
+-- Auction status types now also include: scheduled
+-- (published with a future start_time; the auction worker activates it on time)
+
+-- Requested duration, so auctions that go live later still run for the length the seller chose
+ALTER TABLE auctions ADD COLUMN IF NOT EXISTS duration_hours INT;
+
+CREATE INDEX IF NOT EXISTS idx_auctions_scheduled_start
+    ON auctions(start_time) WHERE status = 'scheduled';
+
+-- Notification types added: auction_live, auction_queued
@@ -0,0 +1,15 @@
+-- Auction edit history
+-- Every field the seller changes through PUT /api/auctions/:id is recorded here and
+-- shown to bidders. Reserve price changes are logged without their values.
+CREATE TABLE IF NOT EXISTS auction_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    editor_id UUID NOT NULL REFERENCES users(id),
+    field VARCHAR(50) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    had_bids BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_auction_revisions_auction ON auction_revisions(auction_id, created_at DESC);
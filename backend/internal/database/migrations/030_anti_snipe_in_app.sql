@@ -0,0 +1,16 @@
+-- Anti-sniping moves into the bid transaction (BiddingService.ApplyAntiSnipe)
+-- The trigger extended end_time behind the application's back and had no cap, so
+-- PlaceBid could only guess whether a bid extended the auction.
+DROP TRIGGER IF EXISTS trigger_anti_snipe ON bids;
+DROP FUNCTION IF EXISTS anti_snipe_extension();
+
+-- Remember the scheduled end so the total extension can be capped
+UPDATE auctions SET original_end_time = end_time
+WHERE original_end_time IS NULL AND end_time IS NOT NULL AND status IN ('active', 'ending_soon');
+
+-- A bid inside an auction's anti_snipe_minutes window moves the end to this many minutes after the bid
+-- Extensions stop once the auction runs this many minutes past its original end time
+INSERT INTO app_settings (key, value) VALUES
+('anti_snipe_extension_minutes', '5'),
+('anti_snipe_max_extension_minutes', '60')
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,34 @@
+-- Durable auction settlement
+-- The worker settles each expired auction in one transaction (claimed with
+-- SELECT ... FOR UPDATE SKIP LOCKED so replicas never settle the same auction) and
+-- records the result here. The unique auction_id makes a second settlement impossible.
+CREATE TABLE IF NOT EXISTS auction_settlements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    auction_id UUID NOT NULL UNIQUE REFERENCES auctions(id) ON DELETE CASCADE,
+    outcome VARCHAR(20) NOT NULL, -- won, no_bids, reserve_not_met
+    winner_id UUID REFERENCES users(id),
+    final_amount DECIMAL(10,2),
+    conversation_id UUID REFERENCES conversations(id),
+    settled_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Side effects of a settlement (notifications, conversation, badges, broadcasts), written in
+-- the settlement transaction and delivered by the worker with retries. Events of one
+-- settlement are delivered in id order.
+CREATE TABLE IF NOT EXISTS settlement_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    settlement_id UUID NOT NULL REFERENCES auction_settlements(id) ON DELETE CASCADE,
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending', -- pending, done, failed
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP DEFAULT NOW(),
+    processed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_settlement_outbox_pending
+    ON settlement_outbox(next_attempt_at, id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_settlement_outbox_settlement ON settlement_outbox(settlement_id, id);
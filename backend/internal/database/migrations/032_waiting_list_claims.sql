@@ -0,0 +1,15 @@
+-- Waiting list claims
+-- When a slot frees up the next member in line is moved to slot_available with an
+-- expires_at claim window. Creating an auction in the category within the window
+-- claims the slot; otherwise the worker expires the offer and moves on.
+-- Status types now also include: claimed
+ALTER TABLE waiting_list ADD COLUMN IF NOT EXISTS auction_id UUID REFERENCES auctions(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_waiting_list_claims
+    ON waiting_list(category_id, town_id, expires_at) WHERE status = 'slot_available';
+
+-- Skip-queue purchases are looked up per seller and category
+CREATE INDEX IF NOT EXISTS idx_slot_purchases_skip_queue
+    ON slot_purchases(user_id, category_id, town_id) WHERE purchase_type = 'skip_queue' AND auction_id IS NULL;
+
+-- Notification types added: slot_expired
@@ -0,0 +1,10 @@
+-- Per category/town auction duration limits
+-- auction_duration_hours is the default length for auctions that don't choose one;
+-- sellers may pick any duration between min_duration_hours and max_duration_hours
+
+ALTER TABLE category_slots ADD COLUMN IF NOT EXISTS min_duration_hours INT DEFAULT 1;
+ALTER TABLE category_slots ADD COLUMN IF NOT EXISTS max_duration_hours INT DEFAULT 720;
+ALTER TABLE category_slots ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT NOW();
+
+-- auctions.duration_hours now only holds a duration the seller chose; NULL means the
+-- category default applies when the auction goes live
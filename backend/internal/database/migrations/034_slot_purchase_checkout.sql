@@ -0,0 +1,16 @@
+-- Slot purchase checkout
+-- slot_purchases status values: pending, completed, failed, refunded
+-- processed_at marks when a purchase was fulfilled (skip used or extra slot taken)
+
+ALTER TABLE slot_purchases ADD COLUMN IF NOT EXISTS payment_provider VARCHAR(30);
+ALTER TABLE slot_purchases ADD COLUMN IF NOT EXISTS payment_intent_id VARCHAR(100);
+ALTER TABLE slot_purchases ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMP;
+ALTER TABLE slot_purchases ADD COLUMN IF NOT EXISTS refund_reason TEXT;
+
+-- Paid purchases still waiting to be used, checked by the auction worker for refunds
+CREATE INDEX IF NOT EXISTS idx_slot_purchases_unfulfilled
+    ON slot_purchases(created_at) WHERE status = 'completed' AND auction_id IS NULL AND processed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_slot_purchases_user ON slot_purchases(user_id, created_at DESC);
+
+-- Notification types added: slot_purchase_refunded
@@ -0,0 +1,43 @@
+-- Payments ledger
+-- One row per payment collected through the payment provider, whatever it pays for.
+-- purpose values: promotion, slot_purchase
+-- status values: pending, succeeded, failed, refunded, partially_refunded
+
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    purpose VARCHAR(30) NOT NULL,
+    reference_id UUID, -- promoted_auctions.id, slot_purchases.id, ...
+    provider VARCHAR(30) NOT NULL,
+    provider_intent_id VARCHAR(100) NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    description TEXT,
+    failure_reason TEXT,
+    confirmed_at TIMESTAMP,
+    refunded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(provider, provider_intent_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payments_user ON payments(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_payments_reference ON payments(purpose, reference_id);
+
+-- Webhook events already applied, so provider retries are ignored
+CREATE TABLE IF NOT EXISTS payment_webhook_events (
+    provider VARCHAR(30) NOT NULL,
+    event_id VARCHAR(100) NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    payment_id UUID REFERENCES payments(id) ON DELETE SET NULL,
+    received_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (provider, event_id)
+);
+
+-- Promotions stay inactive until their payment is confirmed
+ALTER TABLE promoted_auctions ADD COLUMN IF NOT EXISTS payment_id UUID REFERENCES payments(id);
+-- promoted_auctions.payment_status values: pending, paid, failed, refunded
+
+ALTER TABLE slot_purchases ADD COLUMN IF NOT EXISTS payment_id UUID REFERENCES payments(id);
@@ -0,0 +1,65 @@
+-- Orders: escrow-style checkout for sold auctions
+-- An order is opened in the same transaction that sells an auction (settlement, buy-now,
+-- accepted offer, accepted second-chance offer). The buyer pays through the payments
+-- ledger (purpose 'order') before payment_due_at; the money is held until the buyer
+-- confirms receipt or release_due_at passes after the seller dispatched or handed over
+-- the item. Unpaid orders are cancelled and the item is offered to the next bidder.
+-- status values: awaiting_payment, paid, dispatched, collected, completed, cancelled, refunded
+-- source values: auction, buy_now, offer, second_chance
+-- cancel_reason values: non_payment
+-- payments.purpose values now also include: order
+-- Auction status types now also include: unpaid (winner did not pay)
+-- Notification types: payment_reminder, order_paid, order_dispatched, order_collected,
+-- order_released, order_cancelled
+
+CREATE TABLE IF NOT EXISTS orders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount DECIMAL(10,2) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'awaiting_payment',
+    payment_id UUID REFERENCES payments(id),
+    payment_due_at TIMESTAMP NOT NULL,
+    payment_reminder_sent_at TIMESTAMP,
+    paid_at TIMESTAMP,
+    dispatched_at TIMESTAMP,
+    courier VARCHAR(50),
+    tracking_number VARCHAR(100),
+    collected_at TIMESTAMP,
+    received_at TIMESTAMP,
+    release_due_at TIMESTAMP,
+    released_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    cancel_reason VARCHAR(30),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- At most one live order per auction; cancelled and refunded orders stay as history
+CREATE UNIQUE INDEX IF NOT EXISTS idx_orders_auction_open
+    ON orders(auction_id) WHERE status NOT IN ('cancelled', 'refunded');
+CREATE INDEX IF NOT EXISTS idx_orders_buyer ON orders(buyer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_seller ON orders(seller_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_orders_payment_due ON orders(payment_due_at) WHERE status = 'awaiting_payment';
+CREATE INDEX IF NOT EXISTS idx_orders_release_due ON orders(release_due_at) WHERE status IN ('dispatched', 'collected');
+
+-- Second-chance offers also go to the next bidder when the winner does not pay
+-- reason values: reserve_not_met, non_payment
+ALTER TABLE second_chance_offers ADD COLUMN IF NOT EXISTS reason VARCHAR(20) NOT NULL DEFAULT 'reserve_not_met';
+
+-- An accepted offer whose buyer then fails to pay must not block the next offer;
+-- acceptance already locks the auction row, so only pending offers need to be unique
+DROP INDEX IF EXISTS idx_second_chance_offers_auction_open;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_second_chance_offers_auction_pending
+    ON second_chance_offers(auction_id) WHERE status = 'pending';
+
+-- Buyers have this many hours to pay once an order is opened
+-- The payment reminder goes out this many hours before the deadline
+-- Held money is released to the seller this many days after dispatch or collection
+INSERT INTO app_settings (key, value) VALUES
+('order_payment_due_hours', '48'),
+('order_payment_reminder_hours', '12'),
+('order_release_days', '7')
+ON CONFLICT (key) DO NOTHING;
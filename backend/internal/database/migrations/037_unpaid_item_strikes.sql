@@ -0,0 +1,82 @@
+-- Non-paying bidder strikes and seller block lists
+-- Sellers report an unpaid item once the winner's order lapsed without payment; each
+-- report is one strike against the buyer. Buyers with too many recent strikes cannot
+-- bid, and sellers can block individual bidders from their own auctions.
+-- Notification types: unpaid_item_strike
+-- auto_bids.deactivation_reason values now also include: bidding_restricted, blocked_by_seller
+
+CREATE TABLE IF NOT EXISTS unpaid_item_strikes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- the buyer who did not pay
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    reported_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    notes TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(auction_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_unpaid_item_strikes_user ON unpaid_item_strikes(user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS seller_blocked_bidders (
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    bidder_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (seller_id, bidder_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_seller_blocked_bidders_bidder ON seller_blocked_bidders(bidder_id);
+
+-- Strikes feed the behaviour metrics and the fraud score
+ALTER TABLE user_behavior_metrics ADD COLUMN IF NOT EXISTS unpaid_strike_count INT DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION calculate_fraud_score(user_id_param UUID)
+RETURNS DECIMAL AS $$
+DECLARE
+    score DECIMAL := 0;
+    metrics RECORD;
+    signal_count INT;
+BEGIN
+    -- Get behavior metrics
+    SELECT * INTO metrics FROM user_behavior_metrics WHERE user_id = user_id_param;
+
+    IF metrics IS NULL THEN
+        RETURN 0;
+    END IF;
+
+    -- Bid cancel rate contributes up to 20 points
+    score := score + LEAST(metrics.bid_cancel_rate * 0.5, 20);
+
+    -- Same IP bidders contributes up to 30 points
+    score := score + LEAST(metrics.same_ip_bidders_count * 5, 30);
+
+    -- Rapid bid count contributes up to 15 points
+    score := score + LEAST(metrics.rapid_bid_count * 1, 15);
+
+    -- Self-bid attempts contributes up to 25 points
+    score := score + LEAST(metrics.self_bid_attempts * 10, 25);
+
+    -- Unpaid item strikes contribute up to 30 points
+    score := score + LEAST(COALESCE(metrics.unpaid_strike_count, 0) * 10, 30);
+
+    -- Shill bid probability
+    score := score + metrics.shill_bid_probability * 0.1;
+
+    -- Count unreviewed fraud signals
+    SELECT COUNT(*) INTO signal_count
+    FROM fraud_signals
+    WHERE user_id = user_id_param AND is_reviewed = FALSE;
+
+    score := score + LEAST(signal_count * 5, 20);
+
+    -- Cap at 100
+    RETURN LEAST(score, 100);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Buyers with this many strikes inside the window cannot bid
+INSERT INTO app_settings (key, value) VALUES
+('unpaid_strike_limit', '3'),
+('unpaid_strike_window_days', '365')
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,19 @@
+-- Promotion lifecycle and performance tracking
+-- The auction worker switches promotions off once they run out or their auction is no
+-- longer live, and clears auctions.is_featured when no featured/pinned promotion is
+-- left. Impressions and clicks reported through the analytics batch endpoint are
+-- counted against running promotions, in total and per day for the seller's report.
+-- Notification types: promotion_ended
+
+ALTER TABLE promoted_auctions ADD COLUMN IF NOT EXISTS ended_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_promoted_auctions_auction ON promoted_auctions(auction_id, is_active);
+CREATE INDEX IF NOT EXISTS idx_promoted_auctions_user ON promoted_auctions(user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS promotion_daily_stats (
+    promotion_id UUID NOT NULL REFERENCES promoted_auctions(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    impressions INT NOT NULL DEFAULT 0,
+    clicks INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (promotion_id, day)
+);
@@ -0,0 +1,82 @@
+-- Disputes and returns
+-- The buyer or seller of an order can open a dispute while the buyer's payment is held,
+-- or within dispute_window_days of it being released. The order moves to 'disputed',
+-- which stops the automatic release, until an admin resolves it. Resolutions refund the
+-- buyer (fully, partly or after the item is returned) or release the money to the
+-- seller, and count towards both users' transaction stats and the fraud signals of the
+-- user found at fault.
+-- Order statuses now also include: disputed
+-- Notification types: dispute_opened, dispute_message, dispute_return_sent, dispute_resolved
+-- fraud_signals.signal_type values now also include: dispute_lost
+
+CREATE TABLE IF NOT EXISTS disputes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    auction_id UUID NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+    buyer_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    seller_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    opened_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(50) NOT NULL,
+    description TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'open', -- open, awaiting_return, resolved, withdrawn
+    order_status_before VARCHAR(20) NOT NULL,   -- restored when the dispute is withdrawn
+    outcome VARCHAR(20),                        -- refund, partial_refund, return_and_refund, no_refund
+    at_fault VARCHAR(10),                       -- buyer, seller, none
+    refund_amount DECIMAL(12,2),
+    resolution_notes TEXT,
+    resolved_by UUID REFERENCES users(id),
+    resolved_at TIMESTAMP,
+    return_courier VARCHAR(100),
+    return_tracking_number VARCHAR(100),
+    return_sent_at TIMESTAMP,
+    return_received_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- One dispute at a time per order
+CREATE UNIQUE INDEX IF NOT EXISTS idx_disputes_order_open ON disputes(order_id) WHERE status IN ('open', 'awaiting_return');
+CREATE INDEX IF NOT EXISTS idx_disputes_buyer ON disputes(buyer_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_disputes_seller ON disputes(seller_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_disputes_status ON disputes(status, created_at);
+
+CREATE TABLE IF NOT EXISTS dispute_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    dispute_id UUID NOT NULL REFERENCES disputes(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    is_admin BOOLEAN DEFAULT FALSE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispute_messages_dispute ON dispute_messages(dispute_id, created_at);
+
+CREATE TABLE IF NOT EXISTS dispute_evidence (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    dispute_id UUID NOT NULL REFERENCES disputes(id) ON DELETE CASCADE,
+    uploaded_by UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    filename VARCHAR(255),
+    content_type VARCHAR(100),
+    size_bytes BIGINT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_dispute_evidence_dispute ON dispute_evidence(dispute_id, created_at);
+
+-- How long after the payment was released a dispute can still be opened
+INSERT INTO app_settings (key, value) VALUES
+('dispute_window_days', '30')
+ON CONFLICT (key) DO NOTHING;
+
+-- Lost disputes on completed orders only move successful_transactions, which must
+-- recalculate the badge too
+CREATE OR REPLACE TRIGGER trigger_update_user_badge
+    BEFORE UPDATE ON users
+    FOR EACH ROW
+    WHEN (OLD.completed_auctions IS DISTINCT FROM NEW.completed_auctions
+       OR OLD.rating IS DISTINCT FROM NEW.rating
+       OR OLD.total_transactions IS DISTINCT FROM NEW.total_transactions
+       OR OLD.successful_transactions IS DISTINCT FROM NEW.successful_transactions
+       OR OLD.avg_response_time_mins IS DISTINCT FROM NEW.avg_response_time_mins)
+    EXECUTE FUNCTION update_user_badge();
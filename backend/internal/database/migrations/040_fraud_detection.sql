@@ -0,0 +1,18 @@
+-- Fraud detection pipeline
+-- Bids record the IP they were placed from (proxy bids use the IP the auto-bid was
+-- set from). The fraud worker recalculates user_behavior_metrics for recent bidders,
+-- which runs trigger_check_fraud, and raises fraud_signals for shill bidding patterns.
+-- Admins work through unreviewed signals and confirm or dismiss them.
+-- fraud_signals.signal_type values now also include: shill_bidding, same_ip_bidders,
+-- rapid_bids, cancellation_pattern, new_account_single_seller, self_bidding
+
+ALTER TABLE bids ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45);
+ALTER TABLE auto_bids ADD COLUMN IF NOT EXISTS ip_address VARCHAR(45);
+
+CREATE INDEX IF NOT EXISTS idx_bids_ip_address ON bids(ip_address, created_at) WHERE ip_address IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_bids_bidder_created ON bids(bidder_id, created_at);
+
+ALTER TABLE fraud_signals ADD COLUMN IF NOT EXISTS review_action VARCHAR(20); -- confirmed, dismissed
+ALTER TABLE fraud_signals ADD COLUMN IF NOT EXISTS review_notes TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_fraud_signals_user_type ON fraud_signals(user_id, signal_type, created_at DESC);
@@ -0,0 +1,41 @@
+-- Activity log
+-- Logins, bids and new listings are recorded with the client's IP, user agent and
+-- device id (the optional X-Device-ID header sent by the apps), for fraud review and
+-- the admin user details. Entries are never changed; the auction worker deletes them
+-- once they are older than activity_log_retention_days.
+-- Actions: login, bid_placed, auto_bid_set, auction_created
+
+CREATE TABLE IF NOT EXISTS activity_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50),           -- auction, bid
+    entity_id UUID,
+    ip_address INET,
+    user_agent TEXT,
+    device_id VARCHAR(255),
+    metadata JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_activity_log_user ON activity_log(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_activity_log_ip ON activity_log(ip_address, created_at DESC) WHERE ip_address IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_activity_log_device ON activity_log(device_id, created_at DESC) WHERE device_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_activity_log_created ON activity_log(created_at);
+
+-- Append-only: entries can be pruned but not edited
+CREATE OR REPLACE FUNCTION prevent_activity_log_update()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'activity_log entries cannot be modified';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER trigger_activity_log_append_only
+    BEFORE UPDATE ON activity_log
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_activity_log_update();
+
+INSERT INTO app_settings (key, value) VALUES
+('activity_log_retention_days', '180')
+ON CONFLICT (key) DO NOTHING;
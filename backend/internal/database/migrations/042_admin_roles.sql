@@ -0,0 +1,13 @@
+-- Admin roles
+-- Every admin (users.is_admin) now has a role that decides which /api/admin routes
+-- they can use: super_admin (everything, including managing admins and settings),
+-- moderator (listings, users, stores, content and fraud), support (users,
+-- conversations and disputes) and finance (disputes and bid increments).
+-- The role is carried in the JWT, so a change applies from the admin's next login or
+-- token refresh. Existing admins become super admins.
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS admin_role VARCHAR(20); -- super_admin, moderator, support, finance
+
+UPDATE users SET admin_role = 'super_admin' WHERE is_admin = TRUE AND admin_role IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_users_admin_role ON users(admin_role) WHERE admin_role IS NOT NULL;
@@ -0,0 +1,42 @@
+-- Admin audit log
+-- Every successful change made through the admin routes is recorded with the admin
+-- and their role, the entity it touched, the entity before and after the change (and
+-- the fields that differ), the JSON request body, the client IP and the request id.
+-- Entries are never changed or deleted.
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id UUID,                     -- no foreign key: entries outlive the admin
+    actor_role VARCHAR(20),
+    action VARCHAR(100) NOT NULL,      -- e.g. auction.update_status, setting.update
+    entity_type VARCHAR(50),
+    entity_id VARCHAR(100),            -- uuid, or the key for settings
+    before_state JSONB,
+    after_state JSONB,
+    changes JSONB,                     -- {"field": {"before": ..., "after": ...}}
+    request_body JSONB,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status_code INT NOT NULL,
+    ip_address INET,
+    user_agent TEXT,
+    request_id VARCHAR(100),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_created ON admin_audit_log(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_actor ON admin_audit_log(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_entity ON admin_audit_log(entity_type, entity_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_log_action ON admin_audit_log(action, created_at DESC);
+
+CREATE OR REPLACE FUNCTION prevent_admin_audit_log_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'admin_audit_log entries cannot be modified or deleted';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER trigger_admin_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW
+    EXECUTE FUNCTION prevent_admin_audit_log_change();
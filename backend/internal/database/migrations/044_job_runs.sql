@@ -0,0 +1,24 @@
+-- Scheduled jobs
+-- The job worker runs each job once its interval has passed since the last run;
+-- admins (or callers with the internal job token) can also trigger a job by hand.
+-- Every run is recorded here with what it did or why it failed.
+-- Triggers: schedule, manual. Status: running, succeeded, failed
+
+CREATE TABLE IF NOT EXISTS job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL,
+    trigger VARCHAR(20) NOT NULL DEFAULT 'schedule',
+    triggered_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running',
+    result JSONB,
+    error TEXT,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job ON job_runs(job_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_job_runs_started ON job_runs(started_at DESC);
+
+INSERT INTO app_settings (key, value) VALUES
+('job_nudge_stale_stores_interval_hours', '24')
+ON CONFLICT (key) DO NOTHING;
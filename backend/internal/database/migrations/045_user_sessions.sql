@@ -0,0 +1,39 @@
+-- Device sessions and refresh tokens
+-- Each login opens a session holding the hash of its current refresh token. Refreshing
+-- rotates the token; the old hash moves to user_session_retired_tokens, and presenting
+-- a retired token again revokes the whole session (the token was stolen or replayed).
+-- Access tokens carry their session id and are rejected once the session is revoked,
+-- or when they were issued before the user's tokens_valid_after (ban, password reset).
+-- Revoke reasons: logout, signed_out_remotely, token_reuse, account_disabled, password_reset
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tokens_valid_after TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    refresh_token_hash VARCHAR(64) NOT NULL UNIQUE,   -- sha256 hex
+    device_name VARCHAR(100),
+    device_id VARCHAR(255),
+    ip_address INET,
+    user_agent TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    revoked_reason VARCHAR(50)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions(user_id, last_seen_at DESC) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_user_sessions_expires ON user_sessions(expires_at);
+
+CREATE TABLE IF NOT EXISTS user_session_retired_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES user_sessions(id) ON DELETE CASCADE,
+    retired_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_session_retired_tokens_session ON user_session_retired_tokens(session_id);
+
+INSERT INTO app_settings (key, value) VALUES
+('job_prune_sessions_interval_hours', '24')
+ON CONFLICT (key) DO NOTHING;
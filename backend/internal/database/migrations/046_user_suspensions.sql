@@ -0,0 +1,38 @@
+-- Timed suspensions and appeals
+-- Moderators suspend a user until a given time with a reason. While suspended the
+-- user can still sign in, but every authenticated request apart from their profile,
+-- sign out and the suspension endpoints is refused and their websocket connections
+-- are closed. users.suspended_until/suspension_reason mirror the current suspension
+-- so the per-request check is a single row read.
+-- The user may appeal each suspension once; approving the appeal lifts it.
+-- Appeal status: pending, approved, rejected
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspension_reason TEXT;
+
+CREATE TABLE IF NOT EXISTS user_suspensions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    suspended_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    starts_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    ends_at TIMESTAMP NOT NULL,
+    lifted_at TIMESTAMP,               -- ended early: lifted, appeal approved or replaced
+    lifted_by UUID REFERENCES users(id) ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_suspensions_user ON user_suspensions(user_id, starts_at DESC);
+
+CREATE TABLE IF NOT EXISTS suspension_appeals (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    suspension_id UUID NOT NULL UNIQUE REFERENCES user_suspensions(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    admin_response TEXT,
+    reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_suspension_appeals_status ON suspension_appeals(status, created_at);
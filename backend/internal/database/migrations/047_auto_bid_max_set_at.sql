@@ -0,0 +1,15 @@
+-- When an auto-bid's maximum was last set
+-- Equal maximums go to whoever set theirs first. created_at is when the user first
+-- auto-bid on the auction, so raising a maximum to match a rival's, or re-arming a
+-- stopped auto-bid, must not inherit it. max_set_at moves whenever the maximum changes
+-- or the auto-bid is re-armed. Existing rows keep the order they were resolved in.
+
+ALTER TABLE auto_bids ADD COLUMN IF NOT EXISTS max_set_at TIMESTAMP;
+UPDATE auto_bids SET max_set_at = COALESCE(created_at, NOW()) WHERE max_set_at IS NULL;
+ALTER TABLE auto_bids ALTER COLUMN max_set_at SET DEFAULT NOW();
+ALTER TABLE auto_bids ALTER COLUMN max_set_at SET NOT NULL;
+
+DROP INDEX IF EXISTS idx_auto_bids_auction_strength;
+CREATE INDEX IF NOT EXISTS idx_auto_bids_auction_strength
+    ON auto_bids(auction_id, max_amount DESC, max_set_at ASC)
+    WHERE is_active = TRUE;
@@ -7,22 +7,24 @@ import (
 
 	"github.com/airmass/backend/internal/database"
 	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 type AdminHandler struct {
-	db *database.DB
+	db       *database.DB
+	sessions *services.SessionService
 }
 
-func NewAdminHandler(db *database.DB) *AdminHandler {
-	return &AdminHandler{db: db}
+func NewAdminHandler(db *database.DB, sessions *services.SessionService) *AdminHandler {
+	return &AdminHandler{db: db, sessions: sessions}
 }
 
-// ListAdmins returns all users with is_admin = true
+// ListAdmins returns all users with is_admin = true and their roles
 func (h *AdminHandler) ListAdmins(c *gin.Context) {
 	rows, err := h.db.Pool.Query(context.Background(), `
-		SELECT id, email, username, full_name, is_active, created_at
+		SELECT id, email, username, full_name, COALESCE(admin_role, 'super_admin'), is_active, created_at
 		FROM users
 		WHERE is_admin = TRUE
 		ORDER BY created_at DESC
@@ -33,10 +35,10 @@ func (h *AdminHandler) ListAdmins(c *gin.Context) {
 	}
 	defer rows.Close()
 
-	var admins []models.User
+	admins := []models.AdminUser{}
 	for rows.Next() {
-		var u models.User
-		err := rows.Scan(&u.ID, &u.Email, &u.Username, &u.FullName, &u.IsActive, &u.CreatedAt)
+		var u models.AdminUser
+		err := rows.Scan(&u.ID, &u.Email, &u.Username, &u.FullName, &u.Role, &u.IsActive, &u.CreatedAt)
 		if err != nil {
 			log.Printf("Error scanning admin: %v", err)
 			continue
@@ -47,34 +49,85 @@ func (h *AdminHandler) ListAdmins(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"admins": admins})
 }
 
-// AddAdmin elevates a user to admin status
+// AddAdmin elevates a user to admin with a role (support unless given)
 func (h *AdminHandler) AddAdmin(c *gin.Context) {
-	var req struct {
-		UserID string `json:"user_id" binding:"required"`
-	}
+	var req models.AddAdminRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if req.Role == "" {
+		req.Role = models.AdminRoleSupport
+	}
 
-	uid, err := uuid.Parse(req.UserID)
+	tag, err := h.db.Pool.Exec(context.Background(),
+		"UPDATE users SET is_admin = TRUE, admin_role = $2 WHERE id = $1", req.UserID, req.Role,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User elevated to admin", "role": req.Role})
+}
+
+// UpdateAdminRole changes an admin's role. The role is carried in their tokens, so
+// they are signed out everywhere and the new role applies from their next login.
+func (h *AdminHandler) UpdateAdminRole(c *gin.Context) {
+	uid, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
 		return
 	}
 
-	_, err = h.db.Pool.Exec(context.Background(),
-		"UPDATE users SET is_admin = TRUE WHERE id = $1", uid,
+	var req models.UpdateAdminRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Role != models.AdminRoleSuperAdmin && !h.keepsASuperAdmin(c, uid) {
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx,
+		"UPDATE users SET admin_role = $2 WHERE id = $1 AND is_admin = TRUE", uid, req.Role,
 	)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin"})
+		return
+	}
+	if tag.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Admin not found"})
+		return
+	}
+	if err := h.sessions.RevokeAllTx(ctx, tx, uid, models.SessionRevokedRoleChanged); err != nil {
+		log.Printf("Error revoking sessions of admin %s: %v", uid, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update admin"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User elevated to admin"})
+	c.JSON(http.StatusOK, gin.H{"message": "Admin role updated", "role": req.Role})
 }
 
-// RemoveAdmin demotes an admin to regular user
+// RemoveAdmin demotes an admin to regular user and signs them out everywhere, so no
+// token still carrying their admin role stays valid
 func (h *AdminHandler) RemoveAdmin(c *gin.Context) {
 	userID := c.Param("id")
 	uid, err := uuid.Parse(userID)
@@ -83,17 +136,59 @@ func (h *AdminHandler) RemoveAdmin(c *gin.Context) {
 		return
 	}
 
-	_, err = h.db.Pool.Exec(context.Background(),
-		"UPDATE users SET is_admin = FALSE WHERE id = $1", uid,
+	if !h.keepsASuperAdmin(c, uid) {
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		"UPDATE users SET is_admin = FALSE, admin_role = NULL WHERE id = $1", uid,
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
 		return
 	}
+	if err := h.sessions.RevokeAllTx(ctx, tx, uid, models.SessionRevokedRoleChanged); err != nil {
+		log.Printf("Error revoking sessions of admin %s: %v", uid, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user"})
+		return
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Admin rights removed"})
 }
 
+// keepsASuperAdmin writes an error and returns false when taking super admin away
+// from uid would leave no active super admin
+func (h *AdminHandler) keepsASuperAdmin(c *gin.Context, uid uuid.UUID) bool {
+	var others int
+	err := h.db.Pool.QueryRow(context.Background(), `
+		SELECT COUNT(*) FROM users
+		WHERE is_admin = TRUE AND admin_role = 'super_admin' AND is_active = TRUE AND id <> $1`,
+		uid,
+	).Scan(&others)
+	if err != nil {
+		log.Printf("Error counting super admins: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check super admins"})
+		return false
+	}
+	if others == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "There must be at least one super admin", "code": "LAST_SUPER_ADMIN"})
+		return false
+	}
+	return true
+}
+
 // GetPlatformStats returns overall system statistics for the admin dashboard
 func (h *AdminHandler) GetPlatformStats(c *gin.Context) {
 	var totalUsers int
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/airmass/backend/internal/database"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 type AnalyticsHandler struct {
@@ -22,8 +25,9 @@ type BatchTrackRequest struct {
 }
 
 type TrackEvent struct {
-	StoreID   string                 `json:"store_id" binding:"required"`
+	StoreID   string                 `json:"store_id" binding:"required_without=AuctionID"`
 	ProductID string                 `json:"product_id"` // Optional for store events
+	AuctionID string                 `json:"auction_id"` // Auction listing events (impression, click)
 	EventType string                 `json:"event_type" binding:"required"`
 	Metadata  map[string]interface{} `json:"metadata"`
 	Timestamp time.Time              `json:"timestamp"`
@@ -65,7 +69,29 @@ func (h *AnalyticsHandler) BatchTrackImpressions(c *gin.Context) {
 	// Track if we need to update last_confirmed_at for products
 	touchedProducts := make(map[string]bool)
 
+	// Impressions and clicks of auction listings, counted against running promotions
+	auctionEvents := make(map[uuid.UUID]*promotionEventCount)
+
 	for _, event := range req.Events {
+		if event.AuctionID != "" {
+			if aID, err := uuid.Parse(event.AuctionID); err == nil {
+				counts := auctionEvents[aID]
+				if counts == nil {
+					counts = &promotionEventCount{}
+					auctionEvents[aID] = counts
+				}
+				switch event.EventType {
+				case "impression":
+					counts.impressions++
+				case "click":
+					counts.clicks++
+				}
+			}
+		}
+		if event.StoreID == "" {
+			continue
+		}
+
 		// Validate UUIDs
 		sID, err := uuid.Parse(event.StoreID)
 		if err != nil {
@@ -110,6 +136,15 @@ func (h *AnalyticsHandler) BatchTrackImpressions(c *gin.Context) {
 		}
 	}
 
+	for auctionID, counts := range auctionEvents {
+		if counts.impressions == 0 && counts.clicks == 0 {
+			continue
+		}
+		if err := trackPromotionEvents(ctx, tx, auctionID, counts); err != nil {
+			log.Printf("Error tracking promotion events for auction %s: %v", auctionID, err)
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit transaction"})
 		return
@@ -118,6 +153,47 @@ func (h *AnalyticsHandler) BatchTrackImpressions(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Events tracked successfully", "count": len(req.Events)})
 }
 
+type promotionEventCount struct {
+	impressions, clicks int
+}
+
+// trackPromotionEvents adds an auction's impressions and clicks to each of its running
+// promotions, in total and for today. Auctions without one are ignored.
+func trackPromotionEvents(ctx context.Context, tx pgx.Tx, auctionID uuid.UUID, counts *promotionEventCount) error {
+	rows, err := tx.Query(ctx, `
+		UPDATE promoted_auctions
+		SET impressions = impressions + $1, clicks = clicks + $2
+		WHERE auction_id = $3 AND is_active = true AND payment_status = 'paid' AND ends_at > NOW()
+		RETURNING id`,
+		counts.impressions, counts.clicks, auctionID,
+	)
+	if err != nil {
+		return err
+	}
+	var promotionIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err == nil {
+			promotionIDs = append(promotionIDs, id)
+		}
+	}
+	rows.Close()
+
+	for _, id := range promotionIDs {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO promotion_daily_stats (promotion_id, day, impressions, clicks)
+			VALUES ($1, CURRENT_DATE, $2, $3)
+			ON CONFLICT (promotion_id, day) DO UPDATE
+			SET impressions = promotion_daily_stats.impressions + EXCLUDED.impressions,
+				clicks = promotion_daily_stats.clicks + EXCLUDED.clicks`,
+			id, counts.impressions, counts.clicks,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // GetStoreAnalytics aggregates analytics data for a store dashboard
 func (h *AnalyticsHandler) GetStoreAnalytics(c *gin.Context) {
 	storeID := c.Param("id")
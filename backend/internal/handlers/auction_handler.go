@@ -15,6 +15,7 @@ import (
 	"github.com/airmass/backend/internal/fcm"
 	"github.com/airmass/backend/internal/middleware"
 	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
 	"github.com/airmass/backend/internal/websocket"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -26,42 +27,99 @@ type AuctionHandler struct {
 	db         *database.DB
 	hub        *websocket.Hub
 	fcmService *fcm.FCMService
+	bidding    *services.BiddingService
+	increments *services.BidIncrementService
+	slots      *services.SlotService
+	orders     *services.OrderService
+	strikes    *services.StrikeService
+	fraud      *services.FraudService
+	activity   *services.ActivityService
+
+	notificationSvc *services.NotificationService
 }
 
 // NewAuctionHandler creates a new auction handler
-func NewAuctionHandler(db *database.DB, hub *websocket.Hub, fcmService *fcm.FCMService) *AuctionHandler {
-	return &AuctionHandler{db: db, hub: hub, fcmService: fcmService}
+func NewAuctionHandler(db *database.DB, hub *websocket.Hub, fcmService *fcm.FCMService, bidding *services.BiddingService, increments *services.BidIncrementService, slots *services.SlotService, orders *services.OrderService, strikes *services.StrikeService, fraud *services.FraudService, activity *services.ActivityService, notificationSvc *services.NotificationService) *AuctionHandler {
+	return &AuctionHandler{db: db, hub: hub, fcmService: fcmService, bidding: bidding, increments: increments, slots: slots, orders: orders, strikes: strikes, fraud: fraud, activity: activity, notificationSvc: notificationSvc}
 }
 
-// BidIncrementTier represents a bid increment tier from the database
-type BidIncrementTier struct {
-	MinPrice  float64
-	MaxPrice  *float64
-	Increment float64
+const (
+	// maxScheduleAhead limits how far in the future an auction can be scheduled to start
+	maxScheduleAhead = 30 * 24 * time.Hour
+
+	// defaultAntiSnipeMinutes is the late-bid window when the seller does not choose one
+	defaultAntiSnipeMinutes = 5
+)
+
+// validateAuctionPrices checks that buy-now leaves room for bidding and covers the reserve.
+// It returns an error message, or "" when the prices are consistent.
+func validateAuctionPrices(startingPrice float64, reservePrice, buyNowPrice *float64) string {
+	if buyNowPrice == nil {
+		return ""
+	}
+	if *buyNowPrice <= startingPrice {
+		return "Buy now price must be higher than the starting price"
+	}
+	if reservePrice != nil && *buyNowPrice < *reservePrice {
+		return "Buy now price cannot be lower than the reserve price"
+	}
+	return ""
 }
 
-// GetBidIncrement calculates the bid increment based on tiered pricing
-// This is the critical tiered bid increment logic
-func (h *AuctionHandler) GetBidIncrement(currentPrice float64) float64 {
-	// Tiered bid increment rules (enforced SERVER-SIDE)
-	// $0 - $4.99 → +$1
-	// $5 - $19.99 → +$2
-	// $20 - $99.99 → +$5
-	// $100 - $499.99 → +$10
-	// $500+ → +$25
+// validateStartTime rejects start times too far ahead. Past or current times mean "start now".
+func validateStartTime(startTime *time.Time) string {
+	if startTime != nil && time.Until(*startTime) > maxScheduleAhead {
+		return "Auctions can be scheduled at most 30 days ahead"
+	}
+	return ""
+}
 
-	switch {
-	case currentPrice < 5:
-		return 1.00
-	case currentPrice < 20:
-		return 2.00
-	case currentPrice < 100:
-		return 5.00
-	case currentPrice < 500:
-		return 10.00
-	default:
-		return 25.00
+// validateDuration checks a seller-chosen duration against the category's limits.
+// It returns an error message, or "" when the duration is allowed.
+func validateDuration(cfg models.CategorySlot, hours int) string {
+	if hours < cfg.MinDurationHours || hours > cfg.MaxDurationHours {
+		return fmt.Sprintf("Duration must be between %d and %d hours in this category", cfg.MinDurationHours, cfg.MaxDurationHours)
+	}
+	return ""
+}
+
+// defaultBuyNowDisablePercent applies when the buy_now_disable_percent setting is missing
+const defaultBuyNowDisablePercent = 50.0
+
+// buyNowDisablePercent reads how far bidding may climb (as % of the buy-now price)
+// before buy-now is withdrawn
+func (h *AuctionHandler) buyNowDisablePercent(ctx context.Context) float64 {
+	var value string
+	err := h.db.Pool.QueryRow(ctx,
+		"SELECT value FROM app_settings WHERE key = 'buy_now_disable_percent'",
+	).Scan(&value)
+	if err != nil {
+		return defaultBuyNowDisablePercent
 	}
+	percent, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || percent <= 0 || percent > 100 {
+		return defaultBuyNowDisablePercent
+	}
+	return percent
+}
+
+// buyNowOpen reports whether buy-now is still offered at the current price
+func buyNowOpen(buyNowPrice *float64, currentPrice float64, totalBids int, disablePercent float64) bool {
+	if buyNowPrice == nil {
+		return false
+	}
+	if totalBids == 0 {
+		return true
+	}
+	return currentPrice < *buyNowPrice*disablePercent/100
+}
+
+// GetBidIncrement calculates the bid increment based on tiered pricing
+// This is the critical tiered bid increment logic
+func (h *AuctionHandler) GetBidIncrement(currentPrice float64, categoryID, townID uuid.UUID) float64 {
+	// Tiered bid increment rules (enforced SERVER-SIDE) come from bid_increment_tiers,
+	// with category and town overrides, so every caller agrees with the bid trigger
+	return h.increments.Increment(currentPrice, categoryID, townID)
 }
 
 // fetchFullAuction retrieves a complete auction object with all joined data
@@ -71,7 +129,7 @@ func (h *AuctionHandler) fetchFullAuction(ctx context.Context, auctionID uuid.UU
 		a.bid_increment, a.seller_id, a.winner_id, a.category_id, a.town_id, a.suburb_id, 
 		a.status, a.condition, a.start_time, a.end_time, a.original_end_time, a.anti_snipe_minutes,
 		a.total_bids, a.views, a.images, a.is_featured, a.allow_offers, 
-		a.pickup_location, a.shipping_available, a.created_at, a.updated_at,
+		a.pickup_location, a.shipping_available, a.created_at, a.updated_at, a.buy_now_price, a.duration_hours,
 		u.id, u.username, u.full_name, u.avatar_url, u.rating, u.rating_count, u.completed_auctions, u.is_verified,
 		c.name, c.icon,
 		t.name, s.name
@@ -96,7 +154,8 @@ func (h *AuctionHandler) fetchFullAuction(ctx context.Context, auctionID uuid.UU
 		&auction.CategoryID, &auction.TownID, &auction.SuburbID, &auction.Status, &auction.Condition,
 		&auction.StartTime, &auction.EndTime, &auction.OriginalEndTime, &auction.AntiSnipeMinutes,
 		&auction.TotalBids, &auction.Views, &auction.Images, &auction.IsFeatured, &auction.AllowOffers,
-		&auction.PickupLocation, &auction.ShippingAvailable, &auction.CreatedAt, &auction.UpdatedAt,
+		&auction.PickupLocation, &auction.ShippingAvailable, &auction.CreatedAt, &auction.UpdatedAt, &auction.BuyNowPrice,
+		&auction.DurationHours,
 		&seller.ID, &seller.Username, &seller.FullName, &seller.AvatarURL,
 		&sellerRating, &sellerRatingCount, &sellerCompletedAuctions, &sellerIsVerified,
 		&categoryName, &categoryIcon, &townName, &suburbName,
@@ -143,9 +202,10 @@ func (h *AuctionHandler) fetchFullAuction(ctx context.Context, auctionID uuid.UU
 	if auction.CurrentPrice != nil {
 		currentPrice = *auction.CurrentPrice
 	}
-	increment := h.GetBidIncrement(currentPrice)
+	increment := h.GetBidIncrement(currentPrice, auction.CategoryID, auction.TownID)
 	auction.BidIncrement = increment
 	auction.MinNextBid = currentPrice + increment
+	auction.BuyNowAvailable = buyNowOpen(auction.BuyNowPrice, currentPrice, auction.TotalBids, h.buyNowDisablePercent(ctx))
 
 	return &auction, nil
 }
@@ -154,11 +214,12 @@ func (h *AuctionHandler) fetchFullAuction(ctx context.Context, auctionID uuid.UU
 func (h *AuctionHandler) GetNextValidBid(auctionID uuid.UUID) (float64, float64, error) {
 	var currentPrice, startingPrice float64
 	var currentPricePtr *float64
+	var categoryID, townID uuid.UUID
 
 	err := h.db.Pool.QueryRow(context.Background(),
-		"SELECT COALESCE(current_price, starting_price), starting_price FROM auctions WHERE id = $1",
+		"SELECT COALESCE(current_price, starting_price), starting_price, category_id, town_id FROM auctions WHERE id = $1",
 		auctionID,
-	).Scan(&currentPricePtr, &startingPrice)
+	).Scan(&currentPricePtr, &startingPrice, &categoryID, &townID)
 
 	if err != nil {
 		return 0, 0, err
@@ -170,7 +231,7 @@ func (h *AuctionHandler) GetNextValidBid(auctionID uuid.UUID) (float64, float64,
 		currentPrice = startingPrice
 	}
 
-	increment := h.GetBidIncrement(currentPrice)
+	increment := h.GetBidIncrement(currentPrice, categoryID, townID)
 	nextBid := currentPrice + increment
 
 	return nextBid, increment, nil
@@ -219,12 +280,21 @@ func (h *AuctionHandler) GetAuctions(c *gin.Context) {
 		LEFT JOIN categories c ON a.category_id = c.id
 		LEFT JOIN towns t ON a.town_id = t.id
 		LEFT JOIN suburbs s ON a.suburb_id = s.id
+		LEFT JOIN LATERAL (
+			SELECT MAX(pa.boost_multiplier) AS boost, BOOL_OR(pa.promotion_type = 'pinned') AS pinned
+			FROM promoted_auctions pa
+			WHERE pa.auction_id = a.id AND pa.is_active = true AND pa.payment_status = 'paid' AND pa.ends_at > NOW()
+		) promo ON true
 		WHERE 1=1
 	`
 	countQuery := `SELECT COUNT(*) FROM auctions a WHERE 1=1`
 	args := []interface{}{}
 	argCount := 0
 
+	// Drafts are only visible to their seller (GetMyAuctions)
+	query += " AND a.status != 'draft'"
+	countQuery += " AND a.status != 'draft'"
+
 	// Apply Status Filter
 	if filters.Status != nil && *filters.Status != "" {
 		if *filters.Status != "all" {
@@ -281,8 +351,13 @@ func (h *AuctionHandler) GetAuctions(c *gin.Context) {
 		query += " ORDER BY COALESCE(a.current_price, a.starting_price) DESC"
 	case "most_bids":
 		query += " ORDER BY a.total_bids DESC"
-	default:
+	case "newest":
 		query += " ORDER BY a.created_at DESC"
+	default:
+		// Pinned promotions first, then newest first with a listing's age divided by
+		// its promotion's boost, so a 1.5x boost ranks like a listing a third newer
+		query += ` ORDER BY COALESCE(promo.pinned, false) DESC,
+			EXTRACT(EPOCH FROM NOW() - a.created_at) / COALESCE(promo.boost, 1.0) ASC`
 	}
 
 	// Pagination
@@ -379,7 +454,7 @@ func (h *AuctionHandler) GetAuction(c *gin.Context) {
 		a.bid_increment, a.seller_id, a.winner_id, a.category_id, a.town_id, a.suburb_id, 
 		a.status, a.condition, a.start_time, a.end_time, a.original_end_time, a.anti_snipe_minutes,
 		a.total_bids, a.views, a.images, a.is_featured, a.allow_offers, 
-		a.pickup_location, a.shipping_available, a.created_at, a.updated_at,
+		a.pickup_location, a.shipping_available, a.created_at, a.updated_at, a.buy_now_price, a.duration_hours,
 		u.id, u.username, u.full_name, u.avatar_url, u.rating, u.rating_count, u.completed_auctions, u.is_verified,
 		c.name, c.icon,
 		t.name, s.name
@@ -404,7 +479,8 @@ func (h *AuctionHandler) GetAuction(c *gin.Context) {
 		&auction.CategoryID, &auction.TownID, &auction.SuburbID, &auction.Status, &auction.Condition,
 		&auction.StartTime, &auction.EndTime, &auction.OriginalEndTime, &auction.AntiSnipeMinutes,
 		&auction.TotalBids, &auction.Views, &auction.Images, &auction.IsFeatured, &auction.AllowOffers,
-		&auction.PickupLocation, &auction.ShippingAvailable, &auction.CreatedAt, &auction.UpdatedAt,
+		&auction.PickupLocation, &auction.ShippingAvailable, &auction.CreatedAt, &auction.UpdatedAt, &auction.BuyNowPrice,
+		&auction.DurationHours,
 		&seller.ID, &seller.Username, &seller.FullName, &seller.AvatarURL,
 		&sellerRating, &sellerRatingCount, &sellerCompletedAuctions, &sellerIsVerified,
 		&categoryName, &categoryIcon, &townName, &suburbName,
@@ -414,6 +490,14 @@ func (h *AuctionHandler) GetAuction(c *gin.Context) {
 		return
 	}
 
+	// Drafts are private to the seller
+	if auction.Status == models.AuctionStatusDraft {
+		if viewerID, ok := middleware.GetUserID(c); !ok || viewerID != auction.SellerID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+			return
+		}
+	}
+
 	// Set seller details
 	if sellerRating != nil {
 		seller.Rating = *sellerRating
@@ -455,10 +539,19 @@ func (h *AuctionHandler) GetAuction(c *gin.Context) {
 	}
 
 	// Use tiered increment calculation
-	tieredIncrement := h.GetBidIncrement(currentPrice)
+	tieredIncrement := h.GetBidIncrement(currentPrice, auction.CategoryID, auction.TownID)
 	auction.BidIncrement = tieredIncrement
 	auction.MinNextBid = currentPrice + tieredIncrement
 
+	auction.BuyNowAvailable = (auction.Status == models.AuctionStatusActive || auction.Status == models.AuctionStatusEndingSoon) &&
+		buyNowOpen(auction.BuyNowPrice, currentPrice, auction.TotalBids, h.buyNowDisablePercent(context.Background()))
+
+	// Reserve: expose only whether it is met, never the amount (except to the seller)
+	if auction.ReservePrice != nil {
+		reserveMet := auction.TotalBids > 0 && currentPrice >= *auction.ReservePrice
+		auction.ReserveMet = &reserveMet
+	}
+
 	// Get auction tags (hot, trending, etc.)
 	tags := []string{}
 	tagRows, _ := h.db.Pool.Query(context.Background(),
@@ -477,6 +570,9 @@ func (h *AuctionHandler) GetAuction(c *gin.Context) {
 
 	// Check user's bid status
 	userID, hasUser := middleware.GetUserID(c)
+	if !hasUser || userID != auction.SellerID {
+		auction.ReservePrice = nil
+	}
 	if hasUser {
 		h.db.Pool.QueryRow(context.Background(),
 			"SELECT EXISTS(SELECT 1 FROM bids WHERE auction_id = $1 AND bidder_id = $2)",
@@ -502,6 +598,15 @@ func (h *AuctionHandler) CreateAuction(c *gin.Context) {
 		return
 	}
 
+	if msg := validateAuctionPrices(req.StartingPrice, req.ReservePrice, req.BuyNowPrice); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateStartTime(req.StartTime); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
 	// Get user's home town (sellers can only create in their town)
 	var townID uuid.UUID
 	err := h.db.Pool.QueryRow(context.Background(),
@@ -513,39 +618,57 @@ func (h *AuctionHandler) CreateAuction(c *gin.Context) {
 		return
 	}
 
-	// Check category slot availability
-	var maxActive, currentActive int
-	h.db.Pool.QueryRow(context.Background(),
-		"SELECT COALESCE(max_active_auctions, 10) FROM category_slots WHERE category_id = $1 AND town_id = $2",
-		req.CategoryID, townID,
-	).Scan(&maxActive)
-	if maxActive == 0 {
-		maxActive = 10
+	// Calculate TIERED bid increment based on starting price
+	bidIncrement := h.GetBidIncrement(req.StartingPrice, req.CategoryID, townID)
+
+	// Only a duration the seller chose is stored; otherwise the category default applies
+	// whenever the auction goes live
+	var requestedDuration *int
+	if req.DurationHours != 0 {
+		if msg := validateDuration(h.slots.Config(context.Background(), req.CategoryID, townID), req.DurationHours); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+		requestedDuration = &req.DurationHours
 	}
+	durationHours := h.slots.DurationHours(context.Background(), req.CategoryID, townID, req.DurationHours)
 
-	h.db.Pool.QueryRow(context.Background(),
-		"SELECT COUNT(*) FROM auctions WHERE category_id = $1 AND town_id = $2 AND status IN ('active', 'ending_soon')",
-	).Scan(&currentActive)
-
-	status := "active"
-	message := "Auction published successfully!"
-
-	if currentActive >= maxActive {
-		status = "pending"
-		message = "Category is full. Your auction has been added to the waiting list and will go live automatically."
+	antiSnipeMinutes := defaultAntiSnipeMinutes
+	if req.AntiSnipeMinutes != nil {
+		antiSnipeMinutes = *req.AntiSnipeMinutes
 	}
 
-	// Calculate TIERED bid increment based on starting price
-	bidIncrement := h.GetBidIncrement(req.StartingPrice)
+	// Drafts keep their times open until published; scheduled auctions run for the
+	// chosen duration from their start time; everything else starts now if a slot is free
+	var startTime, endTime *time.Time
+	var status models.AuctionStatus
+	var message string
+	var grant *services.SlotGrant // Waiting-list slot or paid extra slot the auction goes live in
 
-	durationHours := req.DurationHours
-	if durationHours <= 0 {
-		durationHours = 168 // 7 days default
+	switch {
+	case req.SaveAsDraft:
+		status = models.AuctionStatusDraft
+		message = "Draft saved. Publish it when you're ready."
+		startTime = req.StartTime
+	case req.StartTime != nil && req.StartTime.After(time.Now()):
+		status = models.AuctionStatusScheduled
+		message = fmt.Sprintf("Auction scheduled to go live on %s.", req.StartTime.Format("2 Jan 2006 15:04"))
+		start := *req.StartTime
+		end := start.Add(time.Duration(durationHours) * time.Hour)
+		startTime, endTime = &start, &end
+	default:
+		status = models.AuctionStatusActive
+		message = "Auction published successfully!"
+		var ok bool
+		if grant, ok = h.slots.CanActivate(context.Background(), userID, req.CategoryID, townID); !ok {
+			status = models.AuctionStatusPending
+			message = "Category is full. Your auction has been added to the waiting list and will go live automatically."
+		}
+		start := time.Now()
+		end := start.Add(time.Duration(durationHours) * time.Hour)
+		startTime, endTime = &start, &end
 	}
 
-	startTime := time.Now()
-	endTime := startTime.Add(time.Duration(durationHours) * time.Hour)
-
 	// Create auction
 	var auctionID uuid.UUID
 	err = h.db.Pool.QueryRow(context.Background(),
@@ -553,20 +676,29 @@ func (h *AuctionHandler) CreateAuction(c *gin.Context) {
 			title, description, starting_price, current_price, reserve_price, bid_increment,
 			seller_id, category_id, town_id, suburb_id, status, condition,
 			start_time, end_time, original_end_time, images, allow_offers,
-			pickup_location, shipping_available
-		) VALUES ($1, $2, $3, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13, $14, $15, $16, $17)
+			pickup_location, shipping_available, buy_now_price, duration_hours, anti_snipe_minutes
+		) VALUES ($1, $2, $3, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13, $14, $15, $16, $17, $18, $19, $20)
 		RETURNING id`,
 		req.Title, req.Description, req.StartingPrice, req.ReservePrice, bidIncrement,
-		userID, req.CategoryID, townID, req.SuburbID, status, req.Condition,
-		startTime, endTime, req.Images, req.AllowOffers, req.PickupLocation, req.ShippingAvailable,
+		userID, req.CategoryID, townID, req.SuburbID, string(status), req.Condition,
+		startTime, endTime, req.Images, req.AllowOffers, req.PickupLocation, req.ShippingAvailable, req.BuyNowPrice,
+		requestedDuration, antiSnipeMinutes,
 	).Scan(&auctionID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create auction"})
 		return
 	}
 
+	if err := h.slots.ConsumeGrant(context.Background(), grant, auctionID); err != nil {
+		log.Printf("Failed to record slot used by auction %s: %v", auctionID, err)
+	}
+
+	h.activity.Record(context.Background(), userID, models.ActivityAuctionCreated, "auction", &auctionID, middleware.GetClientInfo(c), map[string]interface{}{
+		"status": status,
+	})
+
 	// Broadcast to town subscribers if active
-	if status == "active" {
+	if status == models.AuctionStatusActive {
 		h.hub.BroadcastToTown(townID, websocket.MessageTypeAuctionUpdate, gin.H{
 			"action":     "new_auction",
 			"auction_id": auctionID,
@@ -600,6 +732,509 @@ func (h *AuctionHandler) CreateAuction(c *gin.Context) {
 	c.JSON(http.StatusCreated, respBody)
 }
 
+// UpdateAuction edits a seller's auction. Drafts and scheduled auctions can change every
+// listing field. Once live, only the presentation (title, description, images, pickup)
+// can change, and only until the first bid; after that the description is append-only.
+// Every change is recorded in auction_revisions so bidders can see what was edited.
+func (h *AuctionHandler) UpdateAuction(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	auctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var req models.UpdateAuctionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the row so a bid cannot land between the bid check and the update
+	var a models.Auction
+	var status, currentDescription string
+	err = tx.QueryRow(ctx, `
+		SELECT seller_id, status, title, COALESCE(description, ''), images, pickup_location, shipping_available,
+		total_bids, starting_price, reserve_price, buy_now_price, category_id, town_id, suburb_id, condition,
+		allow_offers, start_time, duration_hours, COALESCE(anti_snipe_minutes, 0)
+		FROM auctions WHERE id = $1 FOR UPDATE`,
+		auctionID,
+	).Scan(&a.SellerID, &status, &a.Title, &currentDescription, &a.Images, &a.PickupLocation, &a.ShippingAvailable,
+		&a.TotalBids, &a.StartingPrice, &a.ReservePrice, &a.BuyNowPrice, &a.CategoryID, &a.TownID, &a.SuburbID, &a.Condition,
+		&a.AllowOffers, &a.StartTime, &a.DurationHours, &a.AntiSnipeMinutes)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	if a.SellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only edit your own auctions"})
+		return
+	}
+
+	notLive := status == string(models.AuctionStatusDraft) || status == string(models.AuctionStatusScheduled)
+	live := status == string(models.AuctionStatusPending) || status == string(models.AuctionStatusActive) ||
+		status == string(models.AuctionStatusEndingSoon)
+	if !notLive && !live {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction can no longer be edited"})
+		return
+	}
+	if live && hasListingTermChanges(req) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Prices, category, condition and timing can only be changed before the auction goes live",
+		})
+		return
+	}
+	if live && a.TotalBids > 0 && hasPresentationChanges(req) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "This auction already has bids. You can only add to the description.",
+			"code":  "AUCTION_HAS_BIDS",
+		})
+		return
+	}
+
+	// Validate the listing as it will look after the edit
+	startingPrice, reservePrice, buyNowPrice, categoryID := a.StartingPrice, a.ReservePrice, a.BuyNowPrice, a.CategoryID
+	startTime, durationHours := a.StartTime, a.DurationHours
+	if req.StartingPrice != nil {
+		startingPrice = *req.StartingPrice
+	}
+	if req.ReservePrice != nil {
+		reservePrice = req.ReservePrice
+	}
+	if req.BuyNowPrice != nil {
+		buyNowPrice = req.BuyNowPrice
+	}
+	if req.CategoryID != nil {
+		categoryID = *req.CategoryID
+	}
+	if msg := validateAuctionPrices(startingPrice, reservePrice, buyNowPrice); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if msg := validateStartTime(req.StartTime); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+	if req.StartTime != nil {
+		if status == string(models.AuctionStatusScheduled) && !req.StartTime.After(time.Now()) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Start time must be in the future for a scheduled auction"})
+			return
+		}
+		startTime = req.StartTime
+	}
+	if req.DurationHours != nil {
+		durationHours = req.DurationHours
+	}
+	// A chosen duration must fit the limits of the category the auction ends up in
+	if durationHours != nil && (req.DurationHours != nil || req.CategoryID != nil) {
+		if msg := validateDuration(h.slots.Config(context.Background(), categoryID, a.TownID), *durationHours); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+			return
+		}
+	}
+
+	// Build dynamic update query, keeping a revision for every field that actually changes
+	updates := []string{}
+	args := []interface{}{}
+	argNum := 1
+	var revisions []auctionFieldChange
+	set := func(column string, oldValue, newValue interface{}) {
+		change := auctionFieldChange{Field: column, OldValue: revisionValue(oldValue), NewValue: revisionValue(newValue)}
+		if sameRevisionValue(change.OldValue, change.NewValue) {
+			return
+		}
+		updates = append(updates, column+" = $"+strconv.Itoa(argNum))
+		args = append(args, newValue)
+		argNum++
+		revisions = append(revisions, change)
+	}
+
+	if req.Title != nil {
+		set("title", a.Title, *req.Title)
+	}
+	description := currentDescription
+	if req.Description != nil {
+		description = *req.Description
+	}
+	if req.AppendDescription != nil && strings.TrimSpace(*req.AppendDescription) != "" {
+		description = appendDescriptionUpdate(description, *req.AppendDescription)
+	}
+	set("description", currentDescription, description)
+	if len(req.Images) > 0 {
+		set("images", a.Images, req.Images)
+	}
+	if req.PickupLocation != nil {
+		set("pickup_location", a.PickupLocation, *req.PickupLocation)
+	}
+	if req.ShippingAvailable != nil {
+		set("shipping_available", a.ShippingAvailable, *req.ShippingAvailable)
+	}
+	if req.StartingPrice != nil {
+		set("starting_price", a.StartingPrice, startingPrice)
+	}
+	if req.ReservePrice != nil {
+		set("reserve_price", a.ReservePrice, *req.ReservePrice)
+	}
+	if req.BuyNowPrice != nil {
+		set("buy_now_price", a.BuyNowPrice, *req.BuyNowPrice)
+	}
+	if req.CategoryID != nil {
+		set("category_id", a.CategoryID, categoryID)
+	}
+	if req.SuburbID != nil {
+		set("suburb_id", a.SuburbID, *req.SuburbID)
+	}
+	if req.Condition != nil {
+		set("condition", a.Condition, *req.Condition)
+	}
+	if req.AllowOffers != nil {
+		set("allow_offers", a.AllowOffers, *req.AllowOffers)
+	}
+	if req.AntiSnipeMinutes != nil {
+		set("anti_snipe_minutes", a.AntiSnipeMinutes, *req.AntiSnipeMinutes)
+	}
+	if req.DurationHours != nil {
+		set("duration_hours", a.DurationHours, *durationHours)
+	}
+	if req.StartTime != nil {
+		set("start_time", a.StartTime, *startTime)
+	}
+
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No fields to update"})
+		return
+	}
+
+	// Derived columns follow the fields they depend on and are not revisions themselves
+	if req.StartingPrice != nil {
+		// No bids exist before the auction is live, so the current price follows
+		updates = append(updates, "current_price = $"+strconv.Itoa(argNum))
+		args = append(args, startingPrice)
+		argNum++
+	}
+	if req.StartingPrice != nil || req.CategoryID != nil {
+		updates = append(updates, "bid_increment = $"+strconv.Itoa(argNum))
+		args = append(args, h.GetBidIncrement(startingPrice, categoryID, a.TownID))
+		argNum++
+	}
+	// Scheduled auctions carry their end time; drafts get one when published
+	if status == string(models.AuctionStatusScheduled) && (req.StartTime != nil || req.DurationHours != nil) {
+		hours := 0
+		if durationHours != nil {
+			hours = *durationHours
+		}
+		hours = h.slots.DurationHours(context.Background(), categoryID, a.TownID, hours)
+		updates = append(updates, "end_time = $"+strconv.Itoa(argNum), "original_end_time = $"+strconv.Itoa(argNum))
+		args = append(args, startTime.Add(time.Duration(hours)*time.Hour))
+		argNum++
+	}
+
+	updates = append(updates, "updated_at = NOW()")
+	args = append(args, auctionID)
+
+	query := fmt.Sprintf("UPDATE auctions SET %s WHERE id = $%d", strings.Join(updates, ", "), argNum)
+	if _, err = tx.Exec(ctx, query, args...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update auction"})
+		return
+	}
+
+	for _, r := range revisions {
+		oldValue, newValue := r.OldValue, r.NewValue
+		if r.Field == "reserve_price" {
+			// Revisions are public; the reserve amount never is
+			oldValue, newValue = nil, nil
+		}
+		_, err = tx.Exec(ctx, `
+			INSERT INTO auction_revisions (auction_id, editor_id, field, old_value, new_value, had_bids)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			auctionID, userID, r.Field, oldValue, newValue, a.TotalBids > 0,
+		)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record auction revision"})
+			return
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit"})
+		return
+	}
+
+	fields := make([]string, 0, len(revisions))
+	for _, r := range revisions {
+		fields = append(fields, r.Field)
+	}
+
+	auction, err := h.fetchFullAuction(ctx, auctionID)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"message": "Auction updated", "fields": fields})
+		return
+	}
+
+	// Drafts have no audience yet
+	if status != string(models.AuctionStatusDraft) {
+		h.hub.BroadcastToAuction(auctionID, websocket.MessageTypeAuctionUpdate, gin.H{
+			"action":      "edited",
+			"auction_id":  auctionID,
+			"fields":      fields,
+			"title":       auction.Title,
+			"description": auction.Description,
+			"images":      auction.Images,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Auction updated", "fields": fields, "auction": auction})
+}
+
+// GetAuctionRevisions returns the edit history of an auction, newest first
+func (h *AuctionHandler) GetAuctionRevisions(c *gin.Context) {
+	auctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var sellerID uuid.UUID
+	var status string
+	err = h.db.Pool.QueryRow(context.Background(),
+		"SELECT seller_id, status FROM auctions WHERE id = $1",
+		auctionID,
+	).Scan(&sellerID, &status)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+	if status == string(models.AuctionStatusDraft) {
+		if viewerID, ok := middleware.GetUserID(c); !ok || viewerID != sellerID {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+			return
+		}
+	}
+
+	rows, err := h.db.Pool.Query(context.Background(), `
+		SELECT id, auction_id, editor_id, field, old_value, new_value, had_bids, created_at
+		FROM auction_revisions
+		WHERE auction_id = $1
+		ORDER BY created_at DESC`,
+		auctionID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch revisions"})
+		return
+	}
+	defer rows.Close()
+
+	revisions := []models.AuctionRevision{}
+	for rows.Next() {
+		var r models.AuctionRevision
+		if err := rows.Scan(&r.ID, &r.AuctionID, &r.EditorID, &r.Field, &r.OldValue, &r.NewValue,
+			&r.HadBids, &r.CreatedAt); err != nil {
+			log.Printf("Error scanning auction revision: %v", err)
+			continue
+		}
+		revisions = append(revisions, r)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+}
+
+// auctionFieldChange is one column change made by UpdateAuction
+type auctionFieldChange struct {
+	Field    string
+	OldValue *string
+	NewValue *string
+}
+
+// hasListingTermChanges reports whether the request touches fields that are fixed once
+// the auction is live
+func hasListingTermChanges(req models.UpdateAuctionRequest) bool {
+	return req.StartingPrice != nil || req.ReservePrice != nil || req.BuyNowPrice != nil ||
+		req.CategoryID != nil || req.SuburbID != nil || req.Condition != nil ||
+		req.DurationHours != nil || req.StartTime != nil || req.AllowOffers != nil || req.AntiSnipeMinutes != nil
+}
+
+// hasPresentationChanges reports whether the request rewrites what bidders already saw
+func hasPresentationChanges(req models.UpdateAuctionRequest) bool {
+	return req.Title != nil || req.Description != nil || len(req.Images) > 0 ||
+		req.PickupLocation != nil || req.ShippingAvailable != nil
+}
+
+// appendDescriptionUpdate adds a dated note to the end of a description
+func appendDescriptionUpdate(description, note string) string {
+	note = fmt.Sprintf("Update %s: %s", time.Now().Format("2 Jan 2006"), strings.TrimSpace(note))
+	if strings.TrimSpace(description) == "" {
+		return note
+	}
+	return strings.TrimRight(description, "\n") + "\n\n" + note
+}
+
+// revisionValue renders a column value as text for auction_revisions (nil stays NULL)
+func revisionValue(v interface{}) *string {
+	var s string
+	switch val := v.(type) {
+	case nil:
+		return nil
+	case string:
+		s = val
+	case *string:
+		if val == nil {
+			return nil
+		}
+		s = *val
+	case float64:
+		s = fmt.Sprintf("%.2f", val)
+	case *float64:
+		if val == nil {
+			return nil
+		}
+		s = fmt.Sprintf("%.2f", *val)
+	case int:
+		s = strconv.Itoa(val)
+	case *int:
+		if val == nil {
+			return nil
+		}
+		s = strconv.Itoa(*val)
+	case bool:
+		s = strconv.FormatBool(val)
+	case uuid.UUID:
+		s = val.String()
+	case *uuid.UUID:
+		if val == nil {
+			return nil
+		}
+		s = val.String()
+	case time.Time:
+		s = val.Format(time.RFC3339)
+	case *time.Time:
+		if val == nil {
+			return nil
+		}
+		s = val.Format(time.RFC3339)
+	case []string:
+		encoded, _ := json.Marshal(val)
+		s = string(encoded)
+	default:
+		s = fmt.Sprint(val)
+	}
+	return &s
+}
+
+func sameRevisionValue(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// PublishAuction takes a draft live: scheduled when its start time is in the future,
+// otherwise active immediately (or pending when the category is full)
+func (h *AuctionHandler) PublishAuction(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	auctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var sellerID, categoryID, townID uuid.UUID
+	var status, title string
+	var startTime *time.Time
+	var durationHours *int
+	err = h.db.Pool.QueryRow(context.Background(),
+		"SELECT seller_id, status, title, category_id, town_id, start_time, duration_hours FROM auctions WHERE id = $1",
+		auctionID,
+	).Scan(&sellerID, &status, &title, &categoryID, &townID, &startTime, &durationHours)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	if sellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only publish your own auctions"})
+		return
+	}
+	if status != string(models.AuctionStatusDraft) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only draft auctions can be published"})
+		return
+	}
+	if msg := validateStartTime(startTime); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+		return
+	}
+
+	requested := 0
+	if durationHours != nil {
+		requested = *durationHours
+	}
+	hours := h.slots.DurationHours(context.Background(), categoryID, townID, requested)
+
+	var newStatus models.AuctionStatus
+	var message string
+	var grant *services.SlotGrant
+	canActivate := false
+	start := time.Now()
+	if startTime == nil || !startTime.After(start) {
+		grant, canActivate = h.slots.CanActivate(context.Background(), userID, categoryID, townID)
+	}
+	if startTime != nil && startTime.After(start) {
+		newStatus = models.AuctionStatusScheduled
+		message = fmt.Sprintf("Auction scheduled to go live on %s.", startTime.Format("2 Jan 2006 15:04"))
+		start = *startTime
+	} else if canActivate {
+		newStatus = models.AuctionStatusActive
+		message = "Auction published successfully!"
+	} else {
+		newStatus = models.AuctionStatusPending
+		message = "Category is full. Your auction has been added to the waiting list and will go live automatically."
+	}
+	end := start.Add(time.Duration(hours) * time.Hour)
+
+	result, err := h.db.Pool.Exec(context.Background(), `
+		UPDATE auctions
+		SET status = $1, start_time = $2, end_time = $3, original_end_time = $3, updated_at = NOW()
+		WHERE id = $4 AND status = 'draft'`,
+		string(newStatus), start, end, auctionID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish auction"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusConflict, gin.H{"error": "Auction was already published"})
+		return
+	}
+
+	if newStatus == models.AuctionStatusActive {
+		if err := h.slots.ConsumeGrant(context.Background(), grant, auctionID); err != nil {
+			log.Printf("Failed to record slot used by auction %s: %v", auctionID, err)
+		}
+	}
+
+	if newStatus == models.AuctionStatusActive {
+		h.hub.BroadcastToTown(townID, websocket.MessageTypeAuctionUpdate, gin.H{
+			"action":     "new_auction",
+			"auction_id": auctionID,
+			"title":      title,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":      auctionID,
+		"status":  newStatus,
+		"message": message,
+	})
+}
+
 // PlaceBid places a bid on an auction with STRICT tiered increment enforcement
 // This uses database transactions and row locking to prevent race conditions
 func (h *AuctionHandler) PlaceBid(c *gin.Context) {
@@ -610,6 +1245,13 @@ func (h *AuctionHandler) PlaceBid(c *gin.Context) {
 		return
 	}
 
+	var req models.PlaceBidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	amount := math.Round(req.Amount*100) / 100
+
 	// Start a transaction for atomic bid placement
 	tx, err := h.db.Pool.Begin(context.Background())
 	if err != nil {
@@ -622,11 +1264,11 @@ func (h *AuctionHandler) PlaceBid(c *gin.Context) {
 	var auction models.Auction
 	var previousHighBidderID *uuid.UUID
 	err = tx.QueryRow(context.Background(),
-		`SELECT id, seller_id, current_price, starting_price, status, end_time
+		`SELECT id, seller_id, current_price, starting_price, status, end_time, category_id, town_id
 		FROM auctions WHERE id = $1 FOR UPDATE`,
 		auctionID,
 	).Scan(&auction.ID, &auction.SellerID, &auction.CurrentPrice, &auction.StartingPrice,
-		&auction.Status, &auction.EndTime)
+		&auction.Status, &auction.EndTime, &auction.CategoryID, &auction.TownID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
 		return
@@ -642,10 +1284,17 @@ func (h *AuctionHandler) PlaceBid(c *gin.Context) {
 
 	// 2. Check seller is not bidding on own auction (fraud prevention)
 	if auction.SellerID == userID {
+		h.fraud.RecordSelfBidAttempt(context.Background(), userID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot bid on your own auction", "code": "SELF_BID_FORBIDDEN"})
 		return
 	}
 
+	// 2b. Check the bidder is not blocked by the seller or restricted for unpaid items
+	if code, message := h.strikes.BiddingRestriction(context.Background(), userID, auction.SellerID); code != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": message, "code": code})
+		return
+	}
+
 	// 3. Check auction hasn't ended
 	if auction.EndTime != nil && time.Now().After(*auction.EndTime) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction has ended", "code": "AUCTION_ENDED"})
@@ -658,9 +1307,41 @@ func (h *AuctionHandler) PlaceBid(c *gin.Context) {
 		currentPrice = *auction.CurrentPrice
 	}
 
-	// Use TIERED bid increment - client amount is IGNORED
-	tieredIncrement := h.GetBidIncrement(currentPrice)
-	requiredBid := currentPrice + tieredIncrement
+	// Use TIERED bid increment - the client amount must be at least the next valid bid
+	tieredIncrement := h.GetBidIncrement(currentPrice, auction.CategoryID, auction.TownID)
+	requiredBid := h.increments.NextBid(currentPrice, auction.CategoryID, auction.TownID)
+
+	// 4. Reject stale or too-low amounts with the bid the client should retry with
+	if amount < requiredBid {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":           fmt.Sprintf("Bid must be at least $%.2f", requiredBid),
+			"code":            "BID_TOO_LOW",
+			"current_price":   currentPrice,
+			"next_bid_amount": requiredBid,
+			"next_increment":  tieredIncrement,
+		})
+		return
+	}
+
+	// 5. Higher bids must land on a step of the increment ladder
+	if aligned := h.increments.AlignToLadder(currentPrice, amount, auction.CategoryID, auction.TownID); aligned != amount {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":            fmt.Sprintf("Bid must follow the bid increments, try $%.2f", aligned),
+			"code":             "BID_NOT_ALIGNED",
+			"next_bid_amount":  requiredBid,
+			"suggested_amount": aligned,
+		})
+		return
+	}
+
+	// 6. An auto-bid maximum cannot be below the bid itself
+	if req.MaxAutoBid != nil && *req.MaxAutoBid < amount {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Max auto-bid must be at least your bid of $%.2f", amount),
+			"code":  "MAX_AUTO_BID_TOO_LOW",
+		})
+		return
+	}
 
 	// Get previous high bidder for outbid notification
 	tx.QueryRow(context.Background(),
@@ -668,93 +1349,110 @@ func (h *AuctionHandler) PlaceBid(c *gin.Context) {
 		auctionID,
 	).Scan(&previousHighBidderID)
 
-	// Place bid with the SERVER-CALCULATED amount (ignoring client amount entirely)
+	// Place bid with the validated amount
+	client := middleware.GetClientInfo(c)
 	var bid models.Bid
 	err = tx.QueryRow(context.Background(),
-		`INSERT INTO bids (auction_id, bidder_id, amount)
-		VALUES ($1, $2, $3) RETURNING id, created_at`,
-		auctionID, userID, requiredBid,
+		`INSERT INTO bids (auction_id, bidder_id, amount, max_auto_bid, ip_address)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')) RETURNING id, created_at`,
+		auctionID, userID, amount, req.MaxAutoBid, client.IPAddress,
 	).Scan(&bid.ID, &bid.CreatedAt)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to place bid"})
 		return
 	}
 
+	// Arm the bidder's auto-bid so it defends this bid up to their maximum
+	if req.MaxAutoBid != nil {
+		if _, err := h.bidding.UpsertAutoBid(context.Background(), tx, auctionID, userID, *req.MaxAutoBid, client.IPAddress); err != nil {
+			log.Printf("Failed to set auto-bid for auction %s: %v", auctionID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to place bid"})
+			return
+		}
+	}
+
+	// Let competing auto-bids respond while we still hold the auction lock
+	outcome, err := h.bidding.ResolveAutoBids(context.Background(), tx, auctionID)
+	if err != nil {
+		log.Printf("Failed to resolve auto-bids for auction %s: %v", auctionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to place bid"})
+		return
+	}
+
+	// Anti-sniping: a late bid pushes the end time out, in the same transaction as the bid
+	extension, err := h.bidding.ApplyAntiSnipe(context.Background(), tx, auctionID)
+	if err != nil {
+		log.Printf("Failed to apply anti-snipe extension for auction %s: %v", auctionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to place bid"})
+		return
+	}
+
 	// Commit transaction
 	if err = tx.Commit(context.Background()); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit bid"})
 		return
 	}
 
+	h.activity.Record(context.Background(), userID, models.ActivityBidPlaced, "bid", &bid.ID, client, map[string]interface{}{
+		"auction_id":   auctionID,
+		"amount":       amount,
+		"max_auto_bid": req.MaxAutoBid,
+	})
+
 	bid.AuctionID = auctionID
 	bid.BidderID = userID
-	bid.Amount = requiredBid
-	bid.IsWinning = true
-
-	// Check if time was extended (anti-sniping) - read updated auction
-	var newEndTime *time.Time
-	timeExtended := false
-	h.db.Pool.QueryRow(context.Background(),
-		"SELECT end_time FROM auctions WHERE id = $1",
-		auctionID,
-	).Scan(&newEndTime)
+	bid.Amount = amount
+	bid.MaxAutoBid = req.MaxAutoBid
+	isHighBidder := outcome.HighBidderID != nil && *outcome.HighBidderID == userID
+	bid.IsWinning = isHighBidder
+	finalPrice := outcome.FinalPrice
 
-	if newEndTime != nil && auction.EndTime != nil && newEndTime.After(*auction.EndTime) {
-		timeExtended = true
+	timeExtended := extension.Extended
+	newEndTime := auction.EndTime
+	if timeExtended {
+		newEndTime = &extension.NewEndTime
 	}
 
 	// Calculate the NEXT bid increment for response
-	nextIncrement := h.GetBidIncrement(requiredBid)
-	nextBidAmount := requiredBid + nextIncrement
+	nextIncrement := h.GetBidIncrement(finalPrice, auction.CategoryID, auction.TownID)
+	nextBidAmount := finalPrice + nextIncrement
 
 	// Broadcast bid to auction subscribers
+	bidIncrement := h.GetBidIncrement(amount, auction.CategoryID, auction.TownID)
 	h.hub.BroadcastToAuction(auctionID, websocket.MessageTypeBidNew, gin.H{
 		"bid_id":          bid.ID,
-		"amount":          requiredBid,
+		"amount":          amount,
 		"bidder_id":       userID,
 		"time_extended":   timeExtended,
 		"new_end_time":    newEndTime,
-		"next_bid_amount": nextBidAmount,
-		"next_increment":  nextIncrement,
+		"next_bid_amount": amount + bidIncrement,
+		"next_increment":  bidIncrement,
 	})
 
-	// Notify previous high bidder (outbid)
-	if previousHighBidderID != nil && *previousHighBidderID != userID {
-		h.hub.BroadcastToUser(*previousHighBidderID, websocket.MessageTypeBidOutbid, gin.H{
-			"auction_id": auctionID,
-			"new_amount": requiredBid,
-		})
+	// Push any proxy bids and notify everyone the proxies outbid
+	h.bidding.BroadcastOutcome(auctionID, outcome)
+	h.bidding.BroadcastExtension(auctionID, extension)
 
-		// Create notification
-		h.db.Pool.Exec(context.Background(),
-			`INSERT INTO notifications (user_id, type, title, body, related_auction_id)
-			VALUES ($1, 'outbid', 'You''ve been outbid!', $2, $3)`,
-			previousHighBidderID, fmt.Sprintf("Someone bid $%.2f", requiredBid), auctionID,
-		)
-
-		// Send push notification to outbid user
-		go func() {
-			var fcmToken *string
-			var auctionTitle string
-			h.db.Pool.QueryRow(context.Background(),
-				"SELECT fcm_token FROM users WHERE id = $1", *previousHighBidderID).Scan(&fcmToken)
-			h.db.Pool.QueryRow(context.Background(),
-				"SELECT title FROM auctions WHERE id = $1", auctionID).Scan(&auctionTitle)
+	// Notify previous high bidder (outbid)
+	if previousHighBidderID != nil && *previousHighBidderID != userID &&
+		(outcome.HighBidderID == nil || *outcome.HighBidderID != *previousHighBidderID) &&
+		!outcome.WasOutbid(*previousHighBidderID) {
+		h.bidding.NotifyOutbid(*previousHighBidderID, auctionID, finalPrice)
+	}
 
-			if fcmToken != nil && *fcmToken != "" {
-				err := h.fcmService.SendBidNotification(*fcmToken, auctionTitle, requiredBid, auctionID.String())
-				if err != nil {
-					log.Printf("Failed to send outbid push notification: %v", err)
-				}
-			}
-		}()
+	message := "Bid placed successfully"
+	if req.MaxAutoBid != nil {
+		message = fmt.Sprintf("Bid placed with auto-bid up to $%.2f", *req.MaxAutoBid)
+	}
+	if !isHighBidder {
+		message = "You've been outbid by another bidder's automatic bid"
 	}
 
 	c.JSON(http.StatusOK, models.BidResponse{
 		Bid:           &bid,
-		IsHighBidder:  true,
-		Message:       "Bid placed successfully",
-		NewPrice:      requiredBid,
+		IsHighBidder:  isHighBidder,
+		Message:       message,
+		NewPrice:      finalPrice,
 		TimeExtended:  timeExtended,
 		NewEndTime:    newEndTime,
 		NextBidAmount: nextBidAmount,
@@ -762,6 +1460,143 @@ func (h *AuctionHandler) PlaceBid(c *gin.Context) {
 	})
 }
 
+// BuyNow ends an auction immediately at its buy-now price
+// Uses the same row lock as PlaceBid so a bid and a purchase cannot both win
+func (h *AuctionHandler) BuyNow(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	auctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	ctx := context.Background()
+	disablePercent := h.buyNowDisablePercent(ctx)
+
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the auction row to prevent race conditions
+	var auction models.Auction
+	err = tx.QueryRow(ctx,
+		`SELECT id, title, seller_id, current_price, starting_price, status, end_time, town_id, total_bids, buy_now_price
+		FROM auctions WHERE id = $1 FOR UPDATE`,
+		auctionID,
+	).Scan(&auction.ID, &auction.Title, &auction.SellerID, &auction.CurrentPrice, &auction.StartingPrice,
+		&auction.Status, &auction.EndTime, &auction.TownID, &auction.TotalBids, &auction.BuyNowPrice)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	if auction.Status != models.AuctionStatusActive && auction.Status != models.AuctionStatusEndingSoon {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction is not active", "code": "AUCTION_NOT_ACTIVE"})
+		return
+	}
+	if auction.SellerID == userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot buy your own auction", "code": "SELF_BID_FORBIDDEN"})
+		return
+	}
+	if code, message := h.strikes.BiddingRestriction(ctx, userID, auction.SellerID); code != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": message, "code": code})
+		return
+	}
+	if auction.EndTime != nil && time.Now().After(*auction.EndTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction has ended", "code": "AUCTION_ENDED"})
+		return
+	}
+
+	currentPrice := auction.StartingPrice
+	if auction.CurrentPrice != nil {
+		currentPrice = *auction.CurrentPrice
+	}
+	if !buyNowOpen(auction.BuyNowPrice, currentPrice, auction.TotalBids, disablePercent) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Buy now is not available for this auction", "code": "BUY_NOW_UNAVAILABLE"})
+		return
+	}
+	price := *auction.BuyNowPrice
+
+	// Capture the high bidder before the auction closes so they can be told
+	var previousHighBidderID *uuid.UUID
+	tx.QueryRow(ctx,
+		"SELECT bidder_id FROM bids WHERE auction_id = $1 AND is_winning = true",
+		auctionID,
+	).Scan(&previousHighBidderID)
+
+	if _, err = tx.Exec(ctx, `
+		UPDATE auctions
+		SET status = 'sold', winner_id = $1, final_amount = $2, end_time = NOW(), updated_at = NOW()
+		WHERE id = $3`,
+		userID, price, auctionID,
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete purchase"})
+		return
+	}
+
+	// Auto-bids stop with the auction
+	if _, err = tx.Exec(ctx, `
+		UPDATE auto_bids
+		SET is_active = false, deactivated_at = NOW(), deactivation_reason = 'auction_ended'
+		WHERE auction_id = $1 AND is_active = true`,
+		auctionID,
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete purchase"})
+		return
+	}
+
+	orderID, err := h.orders.Create(ctx, tx, auctionID, userID, auction.SellerID, price, models.OrderSourceBuyNow)
+	if err != nil {
+		log.Printf("Error creating buy-now order: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to complete purchase"})
+		return
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit purchase"})
+		return
+	}
+
+	// Same hand-off as a normal win: conversation first, then both parties are notified
+	conversationID, err := h.notificationSvc.CreateConversation(ctx, auctionID, auction.SellerID, userID)
+	if err != nil {
+		log.Printf("Error creating conversation: %v", err)
+	}
+
+	var buyerName string
+	h.db.Pool.QueryRow(ctx, "SELECT full_name FROM users WHERE id = $1", userID).Scan(&buyerName)
+
+	h.notificationSvc.SendAuctionWonNotification(ctx, userID, auctionID, conversationID, auction.Title, price)
+	h.notificationSvc.SendAuctionSoldNotification(ctx, auction.SellerID, auctionID, conversationID, auction.Title, buyerName, price)
+
+	if previousHighBidderID != nil && *previousHighBidderID != userID {
+		h.bidding.NotifyOutbid(*previousHighBidderID, auctionID, price)
+	}
+
+	h.hub.BroadcastToAuction(auctionID, websocket.MessageTypeAuctionUpdate, gin.H{
+		"action":       "status_change",
+		"auction_id":   auctionID,
+		"status":       models.AuctionStatusSold,
+		"final_amount": price,
+		"sold_via":     "buy_now",
+	})
+	h.hub.BroadcastToTown(auction.TownID, websocket.MessageTypeAuctionUpdate, gin.H{
+		"action":     "auction_ended",
+		"auction_id": auctionID,
+		"title":      auction.Title,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Purchase complete. You won the auction!",
+		"amount":          price,
+		"conversation_id": conversationID,
+		"order_id":        orderID,
+	})
+}
+
 // GetBidHistory returns bids for an auction
 func (h *AuctionHandler) GetBidHistory(c *gin.Context) {
 	auctionID, err := uuid.Parse(c.Param("id"))
@@ -770,6 +1605,17 @@ func (h *AuctionHandler) GetBidHistory(c *gin.Context) {
 		return
 	}
 
+	var currentPrice float64
+	var categoryID, townID uuid.UUID
+	err = h.db.Pool.QueryRow(context.Background(),
+		"SELECT COALESCE(current_price, starting_price), category_id, town_id FROM auctions WHERE id = $1",
+		auctionID,
+	).Scan(&currentPrice, &categoryID, &townID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
 	rows, err := h.db.Pool.Query(context.Background(),
 		`SELECT b.id, b.auction_id, b.bidder_id, b.amount, b.is_winning, b.created_at,
 		u.username, u.avatar_url
@@ -801,12 +1647,12 @@ func (h *AuctionHandler) GetBidHistory(c *gin.Context) {
 		}
 	}
 
-	// Get current increment for next bid info
-	var nextBidAmount, nextIncrement float64
-	if highestBid > 0 {
-		nextIncrement = h.GetBidIncrement(highestBid)
-		nextBidAmount = highestBid + nextIncrement
+	// Next bid info follows the auction price, which equals the highest bid once bidding starts
+	if highestBid > currentPrice {
+		currentPrice = highestBid
 	}
+	nextIncrement := h.GetBidIncrement(currentPrice, categoryID, townID)
+	nextBidAmount := currentPrice + nextIncrement
 
 	c.JSON(http.StatusOK, models.BidHistory{
 		Bids:          bids,
@@ -843,7 +1689,8 @@ func (h *AuctionHandler) CancelAuction(c *gin.Context) {
 		return
 	}
 
-	if status != "active" && status != "ending_soon" && status != "pending" {
+	if status != "active" && status != "ending_soon" && status != "pending" &&
+		status != string(models.AuctionStatusDraft) && status != string(models.AuctionStatusScheduled) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction cannot be cancelled"})
 		return
 	}
@@ -904,7 +1751,7 @@ func (h *AuctionHandler) scanAuctions(rows pgx.Rows) []models.Auction {
 		if a.CurrentPrice != nil {
 			currentPrice = *a.CurrentPrice
 		}
-		tieredIncrement := h.GetBidIncrement(currentPrice)
+		tieredIncrement := h.GetBidIncrement(currentPrice, a.CategoryID, a.TownID)
 		a.BidIncrement = tieredIncrement
 		a.MinNextBid = currentPrice + tieredIncrement
 
@@ -1071,7 +1918,7 @@ func (h *AuctionHandler) GetMyAuctions(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
 
 	// Parse query parameters
-	status := c.Query("status") // active, ended, pending, or empty for all
+	status := c.Query("status") // active, ended, pending, draft, scheduled, or empty for all
 	page := 1
 	limit := 20
 	if p := c.Query("page"); p != "" {
@@ -1116,11 +1963,17 @@ func (h *AuctionHandler) GetMyAuctions(c *gin.Context) {
 			baseQuery += " AND a.status IN ('active', 'ending_soon')"
 			countQuery += " AND a.status IN ('active', 'ending_soon')"
 		case "ended":
-			baseQuery += " AND a.status IN ('ended', 'sold', 'cancelled')"
-			countQuery += " AND a.status IN ('ended', 'sold', 'cancelled')"
+			baseQuery += " AND a.status IN ('ended', 'sold', 'cancelled', 'reserve_not_met', 'unpaid')"
+			countQuery += " AND a.status IN ('ended', 'sold', 'cancelled', 'reserve_not_met', 'unpaid')"
 		case "pending":
 			baseQuery += " AND a.status = 'pending'"
 			countQuery += " AND a.status = 'pending'"
+		case "draft":
+			baseQuery += " AND a.status = 'draft'"
+			countQuery += " AND a.status = 'draft'"
+		case "scheduled":
+			baseQuery += " AND a.status = 'scheduled'"
+			countQuery += " AND a.status = 'scheduled'"
 		default:
 			argCount-- // No filter applied
 		}
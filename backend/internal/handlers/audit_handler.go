@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AuditHandler lets super admins search and export the admin audit log
+type AuditHandler struct {
+	db    *database.DB
+	audit *services.AuditService
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(db *database.DB, audit *services.AuditService) *AuditHandler {
+	return &AuditHandler{db: db, audit: audit}
+}
+
+// auditTime parses an RFC3339 timestamp or a YYYY-MM-DD date. A bare "to" date
+// includes the whole day.
+func auditTime(value string, endOfDay bool) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// auditFilter reads the audit log filters: actor_id, action, entity_type, entity_id,
+// from and to. The second result is a validation message for the client.
+func auditFilter(c *gin.Context) (services.AuditFilter, string) {
+	filter := services.AuditFilter{
+		Action:     c.Query("action"),
+		EntityType: c.Query("entity_type"),
+		EntityID:   c.Query("entity_id"),
+	}
+	if v := c.Query("actor_id"); v != "" {
+		actorID, err := uuid.Parse(v)
+		if err != nil {
+			return filter, "Invalid actor ID"
+		}
+		filter.ActorID = &actorID
+	}
+	if v := c.Query("from"); v != "" {
+		from, err := auditTime(v, false)
+		if err != nil {
+			return filter, "Invalid from date"
+		}
+		filter.From = from
+	}
+	if v := c.Query("to"); v != "" {
+		to, err := auditTime(v, true)
+		if err != nil {
+			return filter, "Invalid to date"
+		}
+		filter.To = to
+	}
+	return filter, ""
+}
+
+// GetAuditLog returns audit log entries, newest first
+func (h *AuditHandler) GetAuditLog(c *gin.Context) {
+	filter, invalid := auditFilter(c)
+	if invalid != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid})
+		return
+	}
+	page, limit, offset := pagination(c)
+	filter.Limit, filter.Offset = limit, offset
+
+	entries, total, err := h.audit.List(context.Background(), filter)
+	if err != nil {
+		log.Printf("Error fetching audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// ExportAuditLog downloads the audit log entries matching the filters as CSV
+func (h *AuditHandler) ExportAuditLog(c *gin.Context) {
+	filter, invalid := auditFilter(c)
+	if invalid != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": invalid})
+		return
+	}
+
+	records, err := h.audit.CSVRecords(context.Background(), filter)
+	if err != nil {
+		log.Printf("Error exporting audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export audit log"})
+		return
+	}
+
+	filename := fmt.Sprintf("admin-audit-log-%s.csv", time.Now().UTC().Format("20060102-150405"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	if err := w.WriteAll(records); err != nil {
+		log.Printf("Error writing audit log export: %v", err)
+	}
+}
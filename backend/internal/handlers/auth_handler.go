@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,7 +15,9 @@ import (
 	"github.com/airmass/backend/internal/database"
 	"github.com/airmass/backend/internal/email"
 	"github.com/airmass/backend/internal/fcm"
+	"github.com/airmass/backend/internal/middleware"
 	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
 	"github.com/airmass/backend/pkg/jwt"
 	"github.com/airmass/backend/pkg/password"
 	"github.com/gin-gonic/gin"
@@ -27,15 +30,23 @@ type AuthHandler struct {
 	jwtService   *jwt.Service
 	emailService *email.EmailService
 	fcmService   *fcm.FCMService
+	strikes      *services.StrikeService
+	activity     *services.ActivityService
+	sessions     *services.SessionService
+	suspensions  *services.SuspensionService
 }
 
 // NewAuthHandler creates a new auth handler
-func NewAuthHandler(db *database.DB, jwtService *jwt.Service, emailService *email.EmailService, fcmService *fcm.FCMService) *AuthHandler {
+func NewAuthHandler(db *database.DB, jwtService *jwt.Service, emailService *email.EmailService, fcmService *fcm.FCMService, strikes *services.StrikeService, activity *services.ActivityService, sessions *services.SessionService, suspensions *services.SuspensionService) *AuthHandler {
 	return &AuthHandler{
 		db:           db,
 		jwtService:   jwtService,
 		emailService: emailService,
 		fcmService:   fcmService,
+		strikes:      strikes,
+		activity:     activity,
+		sessions:     sessions,
+		suspensions:  suspensions,
 	}
 }
 
@@ -81,21 +92,18 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, expiresAt, err := h.jwtService.GenerateToken(userID, req.Email, req.Username)
+	// Sign in on this device
+	resp, err := h.startSession(c, userID, req.Email, req.Username, req.DeviceName)
 	if err != nil {
+		log.Printf("Error starting session for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
 	// Get user with town info
-	user := h.getUserByID(userID)
+	resp.User = h.getUserByID(userID)
 
-	c.JSON(http.StatusCreated, models.AuthResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      user,
-	})
+	c.JSON(http.StatusCreated, resp)
 }
 
 // Login handles user login
@@ -135,21 +143,22 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Generate token
-	token, expiresAt, err := h.jwtService.GenerateToken(user.ID, user.Email, user.Username)
+	// Sign in on this device
+	resp, err := h.startSession(c, user.ID, user.Email, user.Username, req.DeviceName)
 	if err != nil {
+		log.Printf("Error starting session for user %s: %v", user.ID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
+	h.activity.Record(context.Background(), user.ID, models.ActivityLogin, "", nil, middleware.GetClientInfo(c), map[string]interface{}{
+		"method": "password",
+	})
+
 	// Get user with full info
-	fullUser := h.getUserByID(user.ID)
+	resp.User = h.getUserByID(user.ID)
 
-	c.JSON(http.StatusOK, models.AuthResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      fullUser,
-	})
+	c.JSON(http.StatusOK, resp)
 }
 
 // GoogleSignIn handles Google OAuth sign-in
@@ -169,6 +178,7 @@ func (h *AuthHandler) GoogleSignIn(c *gin.Context) {
 
 	// Check if user already exists with this Google ID
 	var user models.User
+	newUser := false
 	err = h.db.Pool.QueryRow(context.Background(),
 		`SELECT id, email, username, full_name, avatar_url, phone, 
 		is_verified, is_active, home_town_id, home_suburb_id, created_at, updated_at
@@ -230,6 +240,7 @@ func (h *AuthHandler) GoogleSignIn(c *gin.Context) {
 			}
 			user.ID = userID
 			user.Username = username
+			newUser = true
 		}
 	}
 
@@ -240,18 +251,21 @@ func (h *AuthHandler) GoogleSignIn(c *gin.Context) {
 		return
 	}
 
-	// Generate JWT token
-	token, expiresAt, err := h.jwtService.GenerateToken(fullUser.ID, fullUser.Email, fullUser.Username)
+	// Sign in on this device
+	resp, err := h.startSession(c, fullUser.ID, fullUser.Email, fullUser.Username, req.DeviceName)
 	if err != nil {
+		log.Printf("Error starting session for user %s: %v", fullUser.ID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.AuthResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      fullUser,
+	h.activity.Record(context.Background(), fullUser.ID, models.ActivityLogin, "", nil, middleware.GetClientInfo(c), map[string]interface{}{
+		"method":   "google",
+		"new_user": newUser,
 	})
+
+	resp.User = fullUser
+	c.JSON(http.StatusOK, resp)
 }
 
 // GoogleUserInfo represents user info from Google token
@@ -324,29 +338,152 @@ func generateUsernameFromEmail(email string) string {
 	return fmt.Sprintf("%s_%x", base, b[:2])
 }
 
-// RefreshToken handles token refresh
+// adminRole returns the user's admin role, empty when they are not an admin
+func (h *AuthHandler) adminRole(userID uuid.UUID) string {
+	var role string
+	h.db.Pool.QueryRow(context.Background(),
+		"SELECT COALESCE(admin_role, '') FROM users WHERE id = $1 AND is_admin = TRUE", userID,
+	).Scan(&role)
+	return role
+}
+
+// startSession opens a device session for a sign-in and issues its first token pair
+func (h *AuthHandler) startSession(c *gin.Context, userID uuid.UUID, email, username, deviceName string) (*models.AuthResponse, error) {
+	session, refreshToken, err := h.sessions.Create(context.Background(), userID, deviceName, middleware.GetClientInfo(c))
+	if err != nil {
+		return nil, err
+	}
+	return h.issueTokens(session, refreshToken, email, username)
+}
+
+// issueTokens returns a session's refresh token with a new access token for it. The
+// admin role is read again, so role changes apply from the next refresh.
+func (h *AuthHandler) issueTokens(session *models.UserSession, refreshToken, email, username string) (*models.AuthResponse, error) {
+	token, expiresAt, err := h.jwtService.GenerateToken(session.UserID, email, username, h.adminRole(session.UserID), session.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthResponse{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt.Unix(),
+		SessionID:        session.ID,
+	}, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access token and refresh token.
+// Each refresh token works once; reusing one signs its session out.
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
-	userID, _ := c.Get("user_id")
-	email, _ := c.Get("user_email")
-	username, _ := c.Get("user_username")
+	var req models.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-	token, expiresAt, err := h.jwtService.GenerateToken(
-		userID.(uuid.UUID),
-		email.(string),
-		username.(string),
-	)
+	session, refreshToken, err := h.sessions.Rotate(context.Background(), req.RefreshToken, middleware.GetClientInfo(c))
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRefreshTokenReused):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token was already used; please sign in again", "code": "REFRESH_TOKEN_REUSED"})
+		case errors.Is(err, services.ErrInvalidRefreshToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token", "code": "INVALID_REFRESH_TOKEN"})
+		default:
+			log.Printf("Error refreshing session: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh token"})
+		}
+		return
+	}
+
+	user := h.getUserByID(session.UserID)
+	if user == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token", "code": "INVALID_REFRESH_TOKEN"})
+		return
+	}
+
+	resp, err := h.issueTokens(session, refreshToken, user.Email, user.Username)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate token"})
 		return
 	}
+	resp.User = user
 
-	user := h.getUserByID(userID.(uuid.UUID))
+	c.JSON(http.StatusOK, resp)
+}
 
-	c.JSON(http.StatusOK, models.AuthResponse{
-		Token:     token,
-		ExpiresAt: expiresAt,
-		User:      user,
-	})
+// Logout signs the current session out
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID := middleware.GetSessionID(c)
+
+	if sessionID != uuid.Nil {
+		err := h.sessions.Revoke(context.Background(), userID, sessionID, models.SessionRevokedLogout)
+		if err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+			log.Printf("Error revoking session %s: %v", sessionID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Signed out"})
+}
+
+// GetSessions lists the devices the user is signed in on
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	current := middleware.GetSessionID(c)
+
+	sessions, err := h.sessions.List(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error fetching sessions for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch sessions"})
+		return
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == current
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+// RevokeSession signs one of the user's devices out
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session ID"})
+		return
+	}
+
+	reason := models.SessionRevokedRemotely
+	if sessionID == middleware.GetSessionID(c) {
+		reason = models.SessionRevokedLogout
+	}
+	if err := h.sessions.Revoke(context.Background(), userID, sessionID, reason); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		log.Printf("Error revoking session %s: %v", sessionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session signed out"})
+}
+
+// RevokeOtherSessions signs the user out on every device except this one
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	revoked, err := h.sessions.RevokeOthers(context.Background(), userID, middleware.GetSessionID(c), models.SessionRevokedRemotely)
+	if err != nil {
+		log.Printf("Error revoking sessions for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to sign out sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Other sessions signed out", "revoked": revoked})
 }
 
 // GetMe returns the current user
@@ -453,6 +590,10 @@ func (h *AuthHandler) GetUserProfile(c *gin.Context) {
 	// For public profile, we might want to hide sensitive info like Email/Phone
 	// but the mobile app might need them for contact if allowed.
 	// For now, return the user object as is, assuming the model controls visibility or the client handles it.
+	// Who is an admin or suspended is not public
+	user.AdminRole = nil
+	user.SuspendedUntil = nil
+	user.SuspensionReason = nil
 
 	c.JSON(http.StatusOK, user)
 }
@@ -470,7 +611,9 @@ func (h *AuthHandler) getUserByID(id uuid.UUID) *models.User {
 	err := h.db.Pool.QueryRow(context.Background(),
 		`SELECT u.id, u.email, u.username, u.full_name, u.avatar_url, u.phone,
 		u.is_verified, u.is_active, u.home_town_id, u.home_suburb_id, 
-		u.last_town_change, u.created_at, u.updated_at,
+		u.last_town_change, u.created_at, u.updated_at, u.admin_role,
+		CASE WHEN u.suspended_until > NOW() THEN u.suspended_until END,
+		CASE WHEN u.suspended_until > NOW() THEN u.suspension_reason END,
 		t.id, t.name, t.state, t.country,
 		s.id, s.name, s.zip_code,
 		st.slug
@@ -483,7 +626,8 @@ func (h *AuthHandler) getUserByID(id uuid.UUID) *models.User {
 	).Scan(
 		&user.ID, &user.Email, &user.Username, &user.FullName, &user.AvatarURL, &user.Phone,
 		&user.IsVerified, &user.IsActive, &user.HomeTownID, &user.HomeSuburbID,
-		&user.LastTownChange, &user.CreatedAt, &user.UpdatedAt,
+		&user.LastTownChange, &user.CreatedAt, &user.UpdatedAt, &user.AdminRole,
+		&user.SuspendedUntil, &user.SuspensionReason,
 		&tID, &tName, &tState, &tCountry,
 		&sID, &sName, &sZip,
 		&storeSlug,
@@ -494,13 +638,16 @@ func (h *AuthHandler) getUserByID(id uuid.UUID) *models.User {
 		h.db.Pool.QueryRow(context.Background(),
 			`SELECT id, email, username, full_name, avatar_url, phone,
 			is_verified, is_active, home_town_id, home_suburb_id, 
-			last_town_change, created_at, updated_at
+			last_town_change, created_at, updated_at, admin_role,
+			CASE WHEN suspended_until > NOW() THEN suspended_until END,
+			CASE WHEN suspended_until > NOW() THEN suspension_reason END
 			FROM users WHERE id = $1`,
 			id,
 		).Scan(
 			&user.ID, &user.Email, &user.Username, &user.FullName, &user.AvatarURL, &user.Phone,
 			&user.IsVerified, &user.IsActive, &user.HomeTownID, &user.HomeSuburbID,
-			&user.LastTownChange, &user.CreatedAt, &user.UpdatedAt,
+			&user.LastTownChange, &user.CreatedAt, &user.UpdatedAt, &user.AdminRole,
+			&user.SuspendedUntil, &user.SuspensionReason,
 		)
 	} else {
 		// Construct nested objects if IDs are present
@@ -647,6 +794,11 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 	// Delete used reset token
 	h.db.Pool.Exec(context.Background(), "DELETE FROM password_resets WHERE user_id = $1", userID)
 
+	// Whoever had the old password is signed out everywhere
+	if err := h.sessions.RevokeAll(context.Background(), userID, models.SessionRevokedPasswordReset); err != nil {
+		log.Printf("Error revoking sessions after password reset for user %s: %v", userID, err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Password has been reset successfully"})
 }
 
@@ -840,11 +992,13 @@ func (h *AuthHandler) UpdateUserStatus(c *gin.Context) {
 		return
 	}
 
-	_, err = h.db.Pool.Exec(context.Background(),
-		"UPDATE users SET is_active = $1, updated_at = NOW() WHERE id = $2",
-		req.IsActive, userID,
-	)
-	if err != nil {
+	// A disabled account is signed out everywhere and its websockets are closed
+	if err := h.suspensions.SetActive(context.Background(), userID, req.IsActive); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		log.Printf("Error updating status of user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user status"})
 		return
 	}
@@ -940,7 +1094,7 @@ func (h *AuthHandler) GetAdminUserDetails(c *gin.Context) {
 		SELECT u.id, u.username, u.email, u.full_name, u.avatar_url, u.phone, u.is_verified, u.is_active, u.created_at, u.home_town_id,
 		t.name as home_town_name,
 		(SELECT COUNT(*) FROM auctions WHERE seller_id = $1) as total_auctions,
-		(SELECT COUNT(*) FROM bids WHERE user_id = $1) as total_bids,
+		(SELECT COUNT(*) FROM bids WHERE bidder_id = $1) as total_bids,
 		(SELECT COUNT(*) FROM auctions WHERE winner_id = $1 AND status = 'sold') as total_wins
 		FROM users u
 		LEFT JOIN towns t ON u.home_town_id = t.id
@@ -957,6 +1111,44 @@ func (h *AuthHandler) GetAdminUserDetails(c *gin.Context) {
 		return
 	}
 
+	// Unpaid item strike history, newest first
+	strikes := []models.UnpaidItemStrike{}
+	rows, err := h.db.Pool.Query(context.Background(), `
+		SELECT s.id, s.user_id, s.auction_id, s.order_id, s.reported_by, s.notes, s.created_at, a.title
+		FROM unpaid_item_strikes s
+		JOIN auctions a ON a.id = s.auction_id
+		WHERE s.user_id = $1
+		ORDER BY s.created_at DESC`,
+		userID,
+	)
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var s models.UnpaidItemStrike
+			if err := rows.Scan(&s.ID, &s.UserID, &s.AuctionID, &s.OrderID, &s.ReportedBy, &s.Notes, &s.CreatedAt, &s.AuctionTitle); err != nil {
+				continue
+			}
+			strikes = append(strikes, s)
+		}
+	}
+
+	// Where the user has logged in, bid and listed from
+	activity, err := h.activity.Recent(context.Background(), userID, 50)
+	if err != nil {
+		log.Printf("Error fetching activity for user %s: %v", userID, err)
+		activity = []models.ActivityLogEntry{}
+	}
+	ipAddresses, err := h.activity.IPUsage(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error fetching IP usage for user %s: %v", userID, err)
+		ipAddresses = []models.ActivityIPUsage{}
+	}
+	suspensions, err := h.suspensions.History(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error fetching suspensions for user %s: %v", userID, err)
+		suspensions = []models.UserSuspension{}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"user": gin.H{
 			"id":             id,
@@ -971,8 +1163,14 @@ func (h *AuthHandler) GetAdminUserDetails(c *gin.Context) {
 			"home_town_id":   homeTownID,
 			"home_town_name": homeTownName,
 		},
-		"total_auctions": totalAuctions,
-		"total_bids":     totalBids,
-		"total_wins":     totalWins,
+		"total_auctions":      totalAuctions,
+		"total_bids":          totalBids,
+		"total_wins":          totalWins,
+		"unpaid_strikes":      strikes,
+		"active_strike_count": h.strikes.ActiveCount(context.Background(), userID),
+		"bidding_restricted":  h.strikes.IsRestricted(context.Background(), userID),
+		"recent_activity":     activity,
+		"ip_addresses":        ipAddresses,
+		"suspensions":         suspensions,
 	})
 }
@@ -0,0 +1,285 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// pgForeignKeyViolation is the Postgres error code for a reference to a missing row
+const pgForeignKeyViolation = "23503"
+
+// BidIncrementHandler manages the bid increment ladder (Admin)
+type BidIncrementHandler struct {
+	db         *database.DB
+	increments *services.BidIncrementService
+}
+
+// NewBidIncrementHandler creates a new bid increment handler
+func NewBidIncrementHandler(db *database.DB, increments *services.BidIncrementService) *BidIncrementHandler {
+	return &BidIncrementHandler{db: db, increments: increments}
+}
+
+// ListBidIncrementTiers returns increment tiers, optionally filtered by category and town
+func (h *BidIncrementHandler) ListBidIncrementTiers(c *gin.Context) {
+	query := `
+		SELECT t.id, t.category_id, t.town_id, t.min_price, t.max_price, t.increment, t.is_active,
+		t.created_at, t.updated_at, c.name, tw.name
+		FROM bid_increment_tiers t
+		LEFT JOIN categories c ON t.category_id = c.id
+		LEFT JOIN towns tw ON t.town_id = tw.id
+		WHERE 1=1`
+	args := []interface{}{}
+
+	if categoryID := c.Query("category_id"); categoryID != "" {
+		id, err := uuid.Parse(categoryID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+			return
+		}
+		args = append(args, id)
+		query += " AND t.category_id = $1"
+	}
+	if townID := c.Query("town_id"); townID != "" {
+		id, err := uuid.Parse(townID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid town ID"})
+			return
+		}
+		args = append(args, id)
+		if len(args) == 1 {
+			query += " AND t.town_id = $1"
+		} else {
+			query += " AND t.town_id = $2"
+		}
+	}
+
+	query += " ORDER BY t.category_id NULLS FIRST, t.town_id NULLS FIRST, t.min_price ASC"
+
+	rows, err := h.db.Pool.Query(context.Background(), query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch bid increment tiers"})
+		return
+	}
+	defer rows.Close()
+
+	tiers := []models.BidIncrementTier{}
+	for rows.Next() {
+		var t models.BidIncrementTier
+		err := rows.Scan(&t.ID, &t.CategoryID, &t.TownID, &t.MinPrice, &t.MaxPrice, &t.Increment, &t.IsActive,
+			&t.CreatedAt, &t.UpdatedAt, &t.CategoryName, &t.TownName)
+		if err != nil {
+			log.Printf("Error scanning bid increment tier: %v", err)
+			continue
+		}
+		tiers = append(tiers, t)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"tiers": tiers})
+}
+
+// CreateBidIncrementTier adds a tier to the global ladder or to a category/town override
+func (h *BidIncrementHandler) CreateBidIncrementTier(c *gin.Context) {
+	var req models.BidIncrementTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	isActive := req.IsActive == nil || *req.IsActive
+	ctx := context.Background()
+	tx, err := h.lockLadder(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bid increment tier"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !h.validateTier(c, tx, uuid.Nil, req, isActive) {
+		return
+	}
+
+	var tier models.BidIncrementTier
+	err = tx.QueryRow(ctx, `
+		INSERT INTO bid_increment_tiers (category_id, town_id, min_price, max_price, increment, is_active)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, category_id, town_id, min_price, max_price, increment, is_active, created_at, updated_at
+	`, req.CategoryID, req.TownID, req.MinPrice, req.MaxPrice, req.Increment, isActive).Scan(
+		&tier.ID, &tier.CategoryID, &tier.TownID, &tier.MinPrice, &tier.MaxPrice, &tier.Increment,
+		&tier.IsActive, &tier.CreatedAt, &tier.UpdatedAt)
+	if isForeignKeyViolation(err) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown category or town"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bid increment tier"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bid increment tier"})
+		return
+	}
+
+	h.increments.Invalidate()
+	c.JSON(http.StatusCreated, tier)
+}
+
+// UpdateBidIncrementTier replaces a tier's range, increment, scope and active flag
+func (h *BidIncrementHandler) UpdateBidIncrementTier(c *gin.Context) {
+	tierID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tier ID"})
+		return
+	}
+
+	var req models.BidIncrementTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	isActive := req.IsActive == nil || *req.IsActive
+	ctx := context.Background()
+	tx, err := h.lockLadder(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bid increment tier"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	if !h.validateTier(c, tx, tierID, req, isActive) {
+		return
+	}
+
+	var tier models.BidIncrementTier
+	err = tx.QueryRow(ctx, `
+		UPDATE bid_increment_tiers
+		SET category_id = $1, town_id = $2, min_price = $3, max_price = $4, increment = $5,
+		    is_active = $6, updated_at = NOW()
+		WHERE id = $7
+		RETURNING id, category_id, town_id, min_price, max_price, increment, is_active, created_at, updated_at
+	`, req.CategoryID, req.TownID, req.MinPrice, req.MaxPrice, req.Increment, isActive, tierID).Scan(
+		&tier.ID, &tier.CategoryID, &tier.TownID, &tier.MinPrice, &tier.MaxPrice, &tier.Increment,
+		&tier.IsActive, &tier.CreatedAt, &tier.UpdatedAt)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bid increment tier not found"})
+		return
+	case isForeignKeyViolation(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown category or town"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bid increment tier"})
+		return
+	}
+	if err := tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update bid increment tier"})
+		return
+	}
+
+	h.increments.Invalidate()
+	c.JSON(http.StatusOK, tier)
+}
+
+// DeleteBidIncrementTier removes a tier. Removing the last tier of an override
+// makes that scope fall back to the next less specific ladder.
+func (h *BidIncrementHandler) DeleteBidIncrementTier(c *gin.Context) {
+	tierID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tier ID"})
+		return
+	}
+
+	result, err := h.db.Pool.Exec(context.Background(), "DELETE FROM bid_increment_tiers WHERE id = $1", tierID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bid increment tier"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bid increment tier not found"})
+		return
+	}
+
+	h.increments.Invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Bid increment tier deleted"})
+}
+
+// lockLadder starts a transaction holding the ladder's write lock, so the overlap
+// check and the write it guards can't interleave with another admin's
+func (h *BidIncrementHandler) lockLadder(ctx context.Context) (pgx.Tx, error) {
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock(hashtext('bid_increment_tiers'))"); err != nil {
+		tx.Rollback(ctx)
+		return nil, err
+	}
+	return tx, nil
+}
+
+// validateTier checks the price range and rejects active tiers that overlap another
+// active tier in the same scope, which would make the ladder ambiguous. Run it in the
+// transaction from lockLadder that writes the tier.
+func (h *BidIncrementHandler) validateTier(c *gin.Context, tx pgx.Tx, tierID uuid.UUID, req models.BidIncrementTierRequest, isActive bool) bool {
+	if req.MaxPrice != nil && *req.MaxPrice < req.MinPrice {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "max_price must be greater than or equal to min_price"})
+		return false
+	}
+	if !isActive {
+		return true
+	}
+
+	rows, err := tx.Query(context.Background(), `
+		SELECT id, min_price, max_price
+		FROM bid_increment_tiers
+		WHERE is_active = true AND id != $1
+		AND category_id IS NOT DISTINCT FROM $2
+		AND town_id IS NOT DISTINCT FROM $3`,
+		tierID, req.CategoryID, req.TownID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate bid increment tier"})
+		return false
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var existingID uuid.UUID
+		var minPrice float64
+		var maxPrice *float64
+		if err := rows.Scan(&existingID, &minPrice, &maxPrice); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate bid increment tier"})
+			return false
+		}
+		if services.TiersOverlap(req.MinPrice, req.MaxPrice, minPrice, maxPrice) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":   "Tier overlaps an existing tier in the same scope",
+				"code":    "TIER_OVERLAP",
+				"tier_id": existingID,
+			})
+			return false
+		}
+	}
+	if rows.Err() != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate bid increment tier"})
+		return false
+	}
+
+	return true
+}
+
+// isForeignKeyViolation reports whether err is Postgres refusing a reference to a missing row
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation
+}
@@ -9,18 +9,20 @@ import (
 
 	"github.com/airmass/backend/internal/database"
 	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
 // CategoryHandler handles category endpoints
 type CategoryHandler struct {
-	db *database.DB
+	db    *database.DB
+	slots *services.SlotService
 }
 
 // NewCategoryHandler creates a new category handler
-func NewCategoryHandler(db *database.DB) *CategoryHandler {
-	return &CategoryHandler{db: db}
+func NewCategoryHandler(db *database.DB, slots *services.SlotService) *CategoryHandler {
+	return &CategoryHandler{db: db, slots: slots}
 }
 
 // GetCategories returns all categories
@@ -115,21 +117,8 @@ func (h *CategoryHandler) GetCategorySlots(c *gin.Context) {
 		return
 	}
 
-	var slot models.CategorySlot
-	err = h.db.Pool.QueryRow(context.Background(),
-		`SELECT cs.id, cs.category_id, cs.town_id, cs.max_active_auctions, cs.auction_duration_hours, cs.created_at
-		FROM category_slots cs WHERE cs.category_id = $1 AND cs.town_id = $2`,
-		categoryID, townID,
-	).Scan(&slot.ID, &slot.CategoryID, &slot.TownID, &slot.MaxActiveAuctions,
-		&slot.AuctionDurationHours, &slot.CreatedAt)
-
-	if err != nil {
-		// No custom slots, use defaults
-		slot.CategoryID = categoryID
-		slot.TownID = townID
-		slot.MaxActiveAuctions = 10
-		slot.AuctionDurationHours = 168
-	}
+	// Defaults are filled in when the town has no custom configuration
+	slot := h.slots.Config(context.Background(), categoryID, townID)
 
 	// Get current active count
 	h.db.Pool.QueryRow(context.Background(),
@@ -287,3 +276,126 @@ func (h *CategoryHandler) DeleteCategory(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Category deleted successfully"})
 }
+
+const categorySlotColumns = `cs.id, cs.category_id, cs.town_id, COALESCE(cs.max_active_auctions, 10),
+	COALESCE(cs.auction_duration_hours, 168), COALESCE(cs.min_duration_hours, 1), COALESCE(cs.max_duration_hours, 720),
+	cs.created_at, COALESCE(cs.updated_at, cs.created_at)`
+
+func categorySlotScanArgs(s *models.CategorySlot) []interface{} {
+	return []interface{}{&s.ID, &s.CategoryID, &s.TownID, &s.MaxActiveAuctions,
+		&s.AuctionDurationHours, &s.MinDurationHours, &s.MaxDurationHours, &s.CreatedAt, &s.UpdatedAt}
+}
+
+// ListCategorySlots returns the custom slot configurations, optionally filtered by
+// category_id or town_id (Admin)
+func (h *CategoryHandler) ListCategorySlots(c *gin.Context) {
+	conditions := []string{}
+	args := []interface{}{}
+	for _, param := range []string{"category_id", "town_id"} {
+		value := c.Query(param)
+		if value == "" {
+			continue
+		}
+		id, err := uuid.Parse(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + param})
+			return
+		}
+		args = append(args, id)
+		conditions = append(conditions, fmt.Sprintf("cs.%s = $%d", param, len(args)))
+	}
+
+	query := `SELECT ` + categorySlotColumns + `, cat.name, t.name
+		FROM category_slots cs
+		JOIN categories cat ON cat.id = cs.category_id
+		JOIN towns t ON t.id = cs.town_id`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY cat.name, t.name"
+
+	rows, err := h.db.Pool.Query(context.Background(), query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch category slots"})
+		return
+	}
+	defer rows.Close()
+
+	type slotWithNames struct {
+		models.CategorySlot
+		CategoryName string `json:"category_name"`
+		TownName     string `json:"town_name"`
+	}
+	slots := []slotWithNames{}
+	for rows.Next() {
+		var s slotWithNames
+		if err := rows.Scan(append(categorySlotScanArgs(&s.CategorySlot), &s.CategoryName, &s.TownName)...); err != nil {
+			continue
+		}
+		slots = append(slots, s)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"slots": slots})
+}
+
+// UpsertCategorySlot sets the slot configuration of a category in a town (Admin).
+// New limits apply to auctions as they go live; running auctions keep their end time.
+func (h *CategoryHandler) UpsertCategorySlot(c *gin.Context) {
+	var req models.UpsertCategorySlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.MinDurationHours > req.MaxDurationHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Minimum duration cannot exceed the maximum duration"})
+		return
+	}
+	if req.AuctionDurationHours < req.MinDurationHours || req.AuctionDurationHours > req.MaxDurationHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Default duration must be between the minimum and maximum duration"})
+		return
+	}
+
+	var slot models.CategorySlot
+	err := h.db.Pool.QueryRow(context.Background(), `
+		INSERT INTO category_slots AS cs (category_id, town_id, max_active_auctions, auction_duration_hours,
+			min_duration_hours, max_duration_hours)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (category_id, town_id) DO UPDATE SET
+			max_active_auctions = EXCLUDED.max_active_auctions,
+			auction_duration_hours = EXCLUDED.auction_duration_hours,
+			min_duration_hours = EXCLUDED.min_duration_hours,
+			max_duration_hours = EXCLUDED.max_duration_hours,
+			updated_at = NOW()
+		RETURNING `+categorySlotColumns,
+		req.CategoryID, req.TownID, req.MaxActiveAuctions, req.AuctionDurationHours,
+		req.MinDurationHours, req.MaxDurationHours,
+	).Scan(categorySlotScanArgs(&slot)...)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to save category slots. Check the category and town exist."})
+		return
+	}
+
+	c.JSON(http.StatusOK, slot)
+}
+
+// DeleteCategorySlot removes a custom slot configuration so the defaults apply again (Admin)
+func (h *CategoryHandler) DeleteCategorySlot(c *gin.Context) {
+	slotID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid slot ID"})
+		return
+	}
+
+	result, err := h.db.Pool.Exec(context.Background(), "DELETE FROM category_slots WHERE id = $1", slotID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete category slots"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Category slots not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Category slots reset to defaults"})
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/airmass/backend/pkg/storage"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxDisputeEvidence is how many files can be uploaded to one dispute
+const maxDisputeEvidence = 20
+
+// DisputeHandler lets buyers and sellers dispute an order and admins resolve it
+type DisputeHandler struct {
+	db       *database.DB
+	disputes *services.DisputeService
+	storage  *storage.SupabaseStorage
+}
+
+// NewDisputeHandler creates a new dispute handler
+func NewDisputeHandler(db *database.DB, disputes *services.DisputeService, storage *storage.SupabaseStorage) *DisputeHandler {
+	return &DisputeHandler{db: db, disputes: disputes, storage: storage}
+}
+
+// disputeError writes the response for an error from DisputeService
+func disputeError(c *gin.Context, err error, action string) {
+	switch {
+	case errors.Is(err, services.ErrDisputeOrderNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+	case errors.Is(err, services.ErrDisputeReason):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "That reason is not available for your side of the order", "code": "INVALID_REASON"})
+	case errors.Is(err, services.ErrOrderNotDisputable):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only paid orders can be disputed", "code": "ORDER_NOT_DISPUTABLE"})
+	case errors.Is(err, services.ErrDisputeWindowClosed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The time to dispute this order has passed", "code": "DISPUTE_WINDOW_CLOSED"})
+	case errors.Is(err, services.ErrDisputeExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "This order already has an open dispute", "code": "DISPUTE_EXISTS"})
+	case errors.Is(err, services.ErrDisputeState):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The dispute cannot be changed this way in its current state", "code": "INVALID_DISPUTE_STATE"})
+	case errors.Is(err, services.ErrDisputeRefundAmount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A partial refund must be more than $0 and less than the order amount", "code": "INVALID_REFUND_AMOUNT"})
+	default:
+		log.Printf("Error trying to %s: %v", action, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to " + action})
+	}
+}
+
+// loadDispute returns the dispute in the :id param. Unless asAdmin is set, the user
+// must be its buyer or seller.
+func (h *DisputeHandler) loadDispute(c *gin.Context, asAdmin bool) (*models.Dispute, bool) {
+	userID, _ := middleware.GetUserID(c)
+	disputeID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dispute ID"})
+		return nil, false
+	}
+
+	d, err := h.disputes.Get(context.Background(), disputeID)
+	if err != nil || (!asAdmin && d.BuyerID != userID && d.SellerID != userID) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dispute not found"})
+		return nil, false
+	}
+	return d, true
+}
+
+// OpenDispute lets the buyer or seller of an order dispute it. The payment stays held
+// until the dispute is withdrawn or an admin resolves it.
+func (h *DisputeHandler) OpenDispute(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.OpenDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	d, err := h.disputes.Open(context.Background(), userID, &req)
+	if err != nil {
+		disputeError(c, err, "open dispute")
+		return
+	}
+
+	c.JSON(http.StatusCreated, d)
+}
+
+// GetMyDisputes returns the disputes the user is the buyer or seller in, optionally
+// filtered by status
+func (h *DisputeHandler) GetMyDisputes(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	disputes, err := h.disputes.List(context.Background(), &userID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch disputes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"disputes": disputes})
+}
+
+// GetDispute returns one of the user's disputes with its messages and evidence
+func (h *DisputeHandler) GetDispute(c *gin.Context) {
+	d, ok := h.loadDispute(c, false)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// AddDisputeMessage adds the user's message to a dispute's thread
+func (h *DisputeHandler) AddDisputeMessage(c *gin.Context) {
+	h.addMessage(c, false)
+}
+
+// AdminAddDisputeMessage adds a support message to a dispute's thread
+func (h *DisputeHandler) AdminAddDisputeMessage(c *gin.Context) {
+	h.addMessage(c, true)
+}
+
+func (h *DisputeHandler) addMessage(c *gin.Context, asAdmin bool) {
+	userID, _ := middleware.GetUserID(c)
+	d, ok := h.loadDispute(c, asAdmin)
+	if !ok {
+		return
+	}
+
+	var req models.DisputeMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m, err := h.disputes.AddMessage(context.Background(), d, userID, asAdmin, req.Body)
+	if err != nil {
+		disputeError(c, err, "send message")
+		return
+	}
+
+	c.JSON(http.StatusCreated, m)
+}
+
+// UploadDisputeEvidence uploads a photo or PDF backing up the user's side of a dispute
+func (h *DisputeHandler) UploadDisputeEvidence(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	d, ok := h.loadDispute(c, false)
+	if !ok {
+		return
+	}
+	if len(d.Evidence) >= maxDisputeEvidence {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("A dispute can have at most %d files", maxDisputeEvidence)})
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No file provided"})
+		return
+	}
+	defer file.Close()
+
+	contentType := header.Header.Get("Content-Type")
+	allowedTypes := map[string]bool{
+		"image/jpeg":      true,
+		"image/jpg":       true,
+		"image/png":       true,
+		"image/webp":      true,
+		"application/pdf": true,
+	}
+	if !allowedTypes[contentType] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid file type. Allowed: jpg, png, webp, pdf"})
+		return
+	}
+	if header.Size > 10*1024*1024 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File too large. Max size: 10MB"})
+		return
+	}
+	if d.Status != models.DisputeStatusOpen && d.Status != models.DisputeStatusAwaitingReturn {
+		disputeError(c, services.ErrDisputeState, "upload evidence")
+		return
+	}
+
+	url, err := h.storage.UploadFile(file, header, "disputes/"+d.ID.String())
+	if err != nil {
+		log.Printf("Error uploading dispute evidence: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file"})
+		return
+	}
+
+	e, err := h.disputes.AddEvidence(context.Background(), d, userID, url, header.Filename, contentType, header.Size)
+	if err != nil {
+		disputeError(c, err, "upload evidence")
+		return
+	}
+
+	c.JSON(http.StatusCreated, e)
+}
+
+// WithdrawDispute lets the user who opened a dispute drop it
+func (h *DisputeHandler) WithdrawDispute(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	d, ok := h.loadDispute(c, false)
+	if !ok {
+		return
+	}
+
+	if err := h.disputes.Withdraw(context.Background(), d, userID); err != nil {
+		disputeError(c, err, "withdraw dispute")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Dispute withdrawn", "dispute": d})
+}
+
+// MarkReturnSent lets the buyer record that they sent the item back to the seller
+func (h *DisputeHandler) MarkReturnSent(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	d, ok := h.loadDispute(c, false)
+	if !ok {
+		return
+	}
+
+	var req models.DisputeReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.disputes.MarkReturnSent(context.Background(), d, userID, &req); err != nil {
+		disputeError(c, err, "record return")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Return recorded. You will be refunded once the seller confirms it arrived."})
+}
+
+// MarkReturnReceived lets the seller confirm the returned item arrived, which refunds
+// the buyer
+func (h *DisputeHandler) MarkReturnReceived(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	d, ok := h.loadDispute(c, false)
+	if !ok {
+		return
+	}
+
+	if err := h.disputes.MarkReturnReceived(context.Background(), d, userID); err != nil {
+		disputeError(c, err, "confirm return")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Return confirmed. The buyer has been refunded.", "dispute": d})
+}
+
+// AdminListDisputes returns all disputes, optionally filtered by status
+func (h *DisputeHandler) AdminListDisputes(c *gin.Context) {
+	disputes, err := h.disputes.List(context.Background(), nil, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch disputes"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"disputes": disputes})
+}
+
+// AdminGetDispute returns any dispute with its messages and evidence
+func (h *DisputeHandler) AdminGetDispute(c *gin.Context) {
+	d, ok := h.loadDispute(c, true)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, d)
+}
+
+// ResolveDispute records an admin's decision and refunds or releases the payment
+func (h *DisputeHandler) ResolveDispute(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	d, ok := h.loadDispute(c, true)
+	if !ok {
+		return
+	}
+
+	var req models.ResolveDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.disputes.Resolve(context.Background(), d, adminID, &req); err != nil {
+		disputeError(c, err, "resolve dispute")
+		return
+	}
+
+	c.JSON(http.StatusOK, d)
+}
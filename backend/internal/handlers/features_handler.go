@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"time"
 
 	"github.com/airmass/backend/internal/database"
 	"github.com/airmass/backend/internal/middleware"
 	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
 	"github.com/airmass/backend/internal/websocket"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -17,13 +19,19 @@ import (
 
 // FeaturesHandler handles advanced feature endpoints
 type FeaturesHandler struct {
-	db  *database.DB
-	hub *websocket.Hub
+	db         *database.DB
+	hub        *websocket.Hub
+	bidding    *services.BiddingService
+	increments *services.BidIncrementService
+	payments   *services.PaymentService
+	strikes    *services.StrikeService
+	fraud      *services.FraudService
+	activity   *services.ActivityService
 }
 
 // NewFeaturesHandler creates a new features handler
-func NewFeaturesHandler(db *database.DB, hub *websocket.Hub) *FeaturesHandler {
-	return &FeaturesHandler{db: db, hub: hub}
+func NewFeaturesHandler(db *database.DB, hub *websocket.Hub, bidding *services.BiddingService, increments *services.BidIncrementService, paymentSvc *services.PaymentService, strikes *services.StrikeService, fraud *services.FraudService, activity *services.ActivityService) *FeaturesHandler {
+	return &FeaturesHandler{db: db, hub: hub, bidding: bidding, increments: increments, payments: paymentSvc, strikes: strikes, fraud: fraud, activity: activity}
 }
 
 // =============================================================================
@@ -60,12 +68,15 @@ func (h *FeaturesHandler) SetAutoBid(c *gin.Context) {
 		StartingPrice float64
 		Status        string
 		EndTime       *time.Time
+		CategoryID    uuid.UUID
+		TownID        uuid.UUID
 	}
 	err = tx.QueryRow(context.Background(),
-		`SELECT seller_id, current_price, starting_price, status, end_time 
+		`SELECT seller_id, current_price, starting_price, status, end_time, category_id, town_id
 		 FROM auctions WHERE id = $1 FOR UPDATE`,
 		auctionID,
-	).Scan(&auction.SellerID, &auction.CurrentPrice, &auction.StartingPrice, &auction.Status, &auction.EndTime)
+	).Scan(&auction.SellerID, &auction.CurrentPrice, &auction.StartingPrice, &auction.Status, &auction.EndTime,
+		&auction.CategoryID, &auction.TownID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
 		return
@@ -73,9 +84,14 @@ func (h *FeaturesHandler) SetAutoBid(c *gin.Context) {
 
 	// Validations
 	if auction.SellerID == userID {
+		h.fraud.RecordSelfBidAttempt(context.Background(), userID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot auto-bid on your own auction"})
 		return
 	}
+	if code, message := h.strikes.BiddingRestriction(context.Background(), userID, auction.SellerID); code != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": message, "code": code})
+		return
+	}
 	if auction.Status != "active" && auction.Status != "ending_soon" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction is not active"})
 		return
@@ -92,11 +108,7 @@ func (h *FeaturesHandler) SetAutoBid(c *gin.Context) {
 	}
 
 	// Get tiered increment
-	var increment float64
-	tx.QueryRow(context.Background(), "SELECT get_bid_increment($1)", currentPrice).Scan(&increment)
-	if increment == 0 {
-		increment = 1.0 // fallback
-	}
+	increment := h.getIncrement(currentPrice, auction.CategoryID, auction.TownID)
 	nextBid := currentPrice + increment
 
 	// Validate max amount is reasonable
@@ -109,48 +121,29 @@ func (h *FeaturesHandler) SetAutoBid(c *gin.Context) {
 	}
 
 	// Create or update auto-bid
-	var autoBidID uuid.UUID
-	err = tx.QueryRow(context.Background(),
-		`INSERT INTO auto_bids (auction_id, user_id, max_amount, is_active)
-		 VALUES ($1, $2, $3, true)
-		 ON CONFLICT (auction_id, user_id) DO UPDATE SET
-		   max_amount = EXCLUDED.max_amount,
-		   is_active = true,
-		   updated_at = NOW(),
-		   deactivated_at = NULL,
-		   deactivation_reason = NULL
-		 RETURNING id`,
-		auctionID, userID, req.MaxAmount,
-	).Scan(&autoBidID)
+	client := middleware.GetClientInfo(c)
+	autoBidID, err := h.bidding.UpsertAutoBid(context.Background(), tx, auctionID, userID, req.MaxAmount, client.IPAddress)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set auto-bid"})
 		return
 	}
 
-	// Check if we're not currently the high bidder - if so, place a bid immediately
-	var isHighBidder bool
-	tx.QueryRow(context.Background(),
-		`SELECT EXISTS(SELECT 1 FROM bids WHERE auction_id = $1 AND bidder_id = $2 AND is_winning = true)`,
-		auctionID, userID,
-	).Scan(&isHighBidder)
+	// Resolve against every other active auto-bid while holding the auction lock
+	outcome, err := h.bidding.ResolveAutoBids(context.Background(), tx, auctionID)
+	if err != nil {
+		log.Printf("Failed to resolve auto-bids for auction %s: %v", auctionID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set auto-bid"})
+		return
+	}
 
-	bidsPlaced := 0
-	if !isHighBidder {
-		// Place initial bid
-		_, err = tx.Exec(context.Background(),
-			`INSERT INTO bids (auction_id, bidder_id, amount, is_auto_bid) VALUES ($1, $2, $3, true)`,
-			auctionID, userID, nextBid,
-		)
-		if err == nil {
-			bidsPlaced = 1
-			currentPrice = nextBid
-			isHighBidder = true
-
-			// Update auto-bid current amount
-			tx.Exec(context.Background(),
-				`UPDATE auto_bids SET current_bid_amount = $1 WHERE id = $2`,
-				nextBid, autoBidID,
-			)
+	// Proxy bids placed here count as late bids just like manual ones
+	var extension *services.AntiSnipeExtension
+	if len(outcome.PlacedBids) > 0 {
+		extension, err = h.bidding.ApplyAntiSnipe(context.Background(), tx, auctionID)
+		if err != nil {
+			log.Printf("Failed to apply anti-snipe extension for auction %s: %v", auctionID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set auto-bid"})
+			return
 		}
 	}
 
@@ -159,22 +152,38 @@ func (h *FeaturesHandler) SetAutoBid(c *gin.Context) {
 		return
 	}
 
+	h.activity.Record(context.Background(), userID, models.ActivityAutoBidSet, "auction", &auctionID, client, map[string]interface{}{
+		"auto_bid_id": autoBidID,
+		"max_amount":  req.MaxAmount,
+	})
+
+	h.bidding.BroadcastOutcome(auctionID, outcome)
+	h.bidding.BroadcastExtension(auctionID, extension)
+
+	isHighBidder := outcome.HighBidderID != nil && *outcome.HighBidderID == userID
+	currentPrice = outcome.FinalPrice
+
 	// Recalculate next bid
-	increment = h.getIncrement(currentPrice)
+	increment = h.getIncrement(currentPrice, auction.CategoryID, auction.TownID)
 	nextBid = currentPrice + increment
 
+	message := "Auto-bid configured successfully"
+	if !isHighBidder {
+		message = "Auto-bid configured, but another bidder's maximum is higher"
+	}
+
 	c.JSON(http.StatusOK, models.AutoBidResponse{
 		AutoBid: &models.AutoBid{
 			ID:        autoBidID,
 			AuctionID: auctionID,
 			UserID:    userID,
 			MaxAmount: req.MaxAmount,
-			IsActive:  true,
+			IsActive:  req.MaxAmount >= nextBid,
 		},
-		Message:      "Auto-bid configured successfully",
+		Message:      message,
 		CurrentBid:   currentPrice,
 		NextBid:      nextBid,
-		BidsPlaced:   bidsPlaced,
+		BidsPlaced:   len(outcome.PlacedBids),
 		IsHighBidder: isHighBidder,
 	})
 }
@@ -432,11 +441,13 @@ func (h *FeaturesHandler) PromoteAuction(c *gin.Context) {
 		return
 	}
 
-	// Check for existing active promotion of same type
+	// Check for an active promotion of the same type, or one still being paid for
 	var existingCount int
 	h.db.Pool.QueryRow(context.Background(),
 		`SELECT COUNT(*) FROM promoted_auctions 
-		 WHERE auction_id = $1 AND promotion_type = $2 AND is_active = true AND ends_at > NOW()`,
+		 WHERE auction_id = $1 AND promotion_type = $2
+		 AND ((is_active = true AND ends_at > NOW())
+		   OR (payment_status = 'pending' AND created_at > NOW() - INTERVAL '1 hour'))`,
 		auctionID, pricing.PromotionType,
 	).Scan(&existingCount)
 	if existingCount > 0 {
@@ -444,14 +455,15 @@ func (h *FeaturesHandler) PromoteAuction(c *gin.Context) {
 		return
 	}
 
-	// Create promotion
+	// Create promotion. It stays inactive until the payment webhook confirms it, and
+	// then runs for the full duration from that moment.
 	startsAt := time.Now()
 	endsAt := startsAt.Add(time.Duration(pricing.DurationHours) * time.Hour)
 
 	var promoID uuid.UUID
 	err = h.db.Pool.QueryRow(context.Background(),
-		`INSERT INTO promoted_auctions (auction_id, user_id, promotion_type, town_id, starts_at, ends_at, amount_paid, boost_multiplier, payment_status)
-		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'paid')
+		`INSERT INTO promoted_auctions (auction_id, user_id, promotion_type, town_id, starts_at, ends_at, amount_paid, boost_multiplier, payment_status, is_active)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, 'pending', false)
 		 RETURNING id`,
 		auctionID, userID, pricing.PromotionType, townID, startsAt, endsAt, pricing.Price, pricing.BoostMultiplier,
 	).Scan(&promoID)
@@ -460,28 +472,151 @@ func (h *FeaturesHandler) PromoteAuction(c *gin.Context) {
 		return
 	}
 
-	// Update auction featured status if applicable
-	if pricing.PromotionType == "featured" || pricing.PromotionType == "pinned" {
+	payment, err := h.payments.Start(context.Background(), userID, models.PaymentPurposePromotion, promoID,
+		pricing.Price, fmt.Sprintf("Auction promotion (%s)", pricing.PromotionType))
+	if err != nil {
+		log.Printf("Failed to start promotion payment: %v", err)
 		h.db.Pool.Exec(context.Background(),
-			"UPDATE auctions SET is_featured = true WHERE id = $1",
-			auctionID,
-		)
+			"UPDATE promoted_auctions SET payment_status = 'failed' WHERE id = $1", promoID)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Could not start payment. Please try again."})
+		return
 	}
+	h.db.Pool.Exec(context.Background(),
+		"UPDATE promoted_auctions SET payment_id = $1 WHERE id = $2", payment.ID, promoID)
 
 	c.JSON(http.StatusCreated, models.PromotionResponse{
 		Promotion: &models.PromotedAuction{
 			ID:            promoID,
 			AuctionID:     auctionID,
+			UserID:        userID,
 			PromotionType: pricing.PromotionType,
+			TownID:        &townID,
 			StartsAt:      startsAt,
 			EndsAt:        endsAt,
 			AmountPaid:    pricing.Price,
+			PaymentStatus: "pending",
+			PaymentID:     &payment.ID,
 		},
-		Message:   "Promotion activated",
+		Payment:   payment,
+		Message:   "Complete the payment to activate your promotion",
 		ExpiresAt: endsAt,
 	})
 }
 
+const promotionColumns = `pa.id, pa.auction_id, pa.user_id, pa.promotion_type, pa.town_id, pa.starts_at, pa.ends_at,
+	pa.amount_paid, pa.is_active, pa.impressions, pa.clicks, pa.boost_multiplier, pa.payment_status, pa.payment_id,
+	pa.ended_at, pa.created_at, a.title`
+
+func promotionScanArgs(p *models.PromotedAuction) []interface{} {
+	return []interface{}{&p.ID, &p.AuctionID, &p.UserID, &p.PromotionType, &p.TownID, &p.StartsAt, &p.EndsAt,
+		&p.AmountPaid, &p.IsActive, &p.Impressions, &p.Clicks, &p.BoostMultiplier, &p.PaymentStatus, &p.PaymentID,
+		&p.EndedAt, &p.CreatedAt, &p.AuctionTitle}
+}
+
+// GetMyPromotions returns the current user's paid promotions with their running totals,
+// newest first
+func (h *FeaturesHandler) GetMyPromotions(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	rows, err := h.db.Pool.Query(context.Background(), `
+		SELECT `+promotionColumns+`
+		FROM promoted_auctions pa
+		JOIN auctions a ON a.id = pa.auction_id
+		WHERE pa.user_id = $1 AND pa.payment_status IN ('paid', 'refunded')
+		ORDER BY pa.created_at DESC
+		LIMIT 100`,
+		userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch promotions"})
+		return
+	}
+	defer rows.Close()
+
+	promotions := []models.PromotedAuction{}
+	for rows.Next() {
+		var p models.PromotedAuction
+		if err := rows.Scan(promotionScanArgs(&p)...); err != nil {
+			log.Printf("Error scanning promotion: %v", err)
+			continue
+		}
+		promotions = append(promotions, p)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"promotions": promotions})
+}
+
+// GetPromotionReport shows the seller how one of their promotions performed: impressions,
+// clicks, click-through rate and cost per click, per day, along with the bids and
+// watchers the auction gained while it ran
+func (h *FeaturesHandler) GetPromotionReport(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	promotionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid promotion ID"})
+		return
+	}
+
+	ctx := context.Background()
+	var p models.PromotedAuction
+	err = h.db.Pool.QueryRow(ctx, `
+		SELECT `+promotionColumns+`
+		FROM promoted_auctions pa
+		JOIN auctions a ON a.id = pa.auction_id
+		WHERE pa.id = $1 AND pa.user_id = $2`,
+		promotionID, userID,
+	).Scan(promotionScanArgs(&p)...)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Promotion not found"})
+		return
+	}
+
+	report := models.PromotionReport{Promotion: &p, Daily: []models.PromotionDailyStat{}}
+	if p.Impressions > 0 {
+		report.ClickThrough = float64(p.Clicks) / float64(p.Impressions)
+	}
+	if p.Clicks > 0 && p.PaymentStatus == "paid" {
+		cpc := p.AmountPaid / float64(p.Clicks)
+		report.CostPerClick = &cpc
+	}
+
+	// A promotion runs from payment until ends_at, or until it was switched off early
+	if p.PaymentStatus == "paid" || p.EndedAt != nil {
+		until := p.EndsAt
+		if p.EndedAt != nil && p.EndedAt.Before(until) {
+			until = *p.EndedAt
+		}
+		h.db.Pool.QueryRow(ctx,
+			"SELECT COUNT(*) FROM bids WHERE auction_id = $1 AND created_at >= $2 AND created_at < $3",
+			p.AuctionID, p.StartsAt, until,
+		).Scan(&report.BidsDuring)
+		h.db.Pool.QueryRow(ctx,
+			"SELECT COUNT(*) FROM watchlist WHERE auction_id = $1 AND created_at >= $2 AND created_at < $3",
+			p.AuctionID, p.StartsAt, until,
+		).Scan(&report.WatchersDuring)
+	}
+
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT TO_CHAR(day, 'YYYY-MM-DD'), impressions, clicks
+		FROM promotion_daily_stats
+		WHERE promotion_id = $1
+		ORDER BY day`,
+		promotionID,
+	)
+	if err == nil {
+		defer rows.Close()
+		for rows.Next() {
+			var d models.PromotionDailyStat
+			if err := rows.Scan(&d.Day, &d.Impressions, &d.Clicks); err != nil {
+				continue
+			}
+			report.Daily = append(report.Daily, d)
+		}
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // =============================================================================
 // USER RATINGS ENDPOINTS
 // =============================================================================
@@ -609,12 +744,14 @@ func (h *FeaturesHandler) GetUserReputation(c *gin.Context) {
 	err = h.db.Pool.QueryRow(context.Background(),
 		`SELECT id, username, full_name, avatar_url, rating, rating_count, completed_auctions,
 		        badge_level, is_trusted_seller, is_verified, is_fast_responder,
-		        total_transactions, successful_transactions, COALESCE(member_since, created_at), badges
+		        total_transactions, successful_transactions, COALESCE(disputed_transactions, 0),
+		        COALESCE(member_since, created_at), badges
 		 FROM users WHERE id = $1`,
 		userID,
 	).Scan(&rep.UserID, &rep.Username, &rep.FullName, &rep.AvatarURL, &rep.Rating, &rep.RatingCount,
 		&rep.CompletedAuctions, &rep.BadgeLevel, &rep.IsTrustedSeller, &rep.IsVerified,
-		&rep.IsFastResponder, &rep.TotalTransactions, &rep.SuccessfulTransactions, &rep.MemberSince, &badges)
+		&rep.IsFastResponder, &rep.TotalTransactions, &rep.SuccessfulTransactions, &rep.DisputedTransactions,
+		&rep.MemberSince, &badges)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		return
@@ -770,17 +907,6 @@ func (h *FeaturesHandler) GetTopSellersInTown(c *gin.Context) {
 // HELPER FUNCTIONS
 // =============================================================================
 
-func (h *FeaturesHandler) getIncrement(currentPrice float64) float64 {
-	switch {
-	case currentPrice < 5:
-		return 1.00
-	case currentPrice < 20:
-		return 2.00
-	case currentPrice < 100:
-		return 5.00
-	case currentPrice < 500:
-		return 10.00
-	default:
-		return 25.00
-	}
+func (h *FeaturesHandler) getIncrement(currentPrice float64, categoryID, townID uuid.UUID) float64 {
+	return h.increments.Increment(currentPrice, categoryID, townID)
 }
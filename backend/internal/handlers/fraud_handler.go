@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// FraudHandler exposes the fraud review queue to admins
+type FraudHandler struct {
+	db    *database.DB
+	fraud *services.FraudService
+}
+
+// NewFraudHandler creates a new fraud handler
+func NewFraudHandler(db *database.DB, fraud *services.FraudService) *FraudHandler {
+	return &FraudHandler{db: db, fraud: fraud}
+}
+
+// pagination reads the page and limit query params
+func pagination(c *gin.Context) (page, limit, offset int) {
+	page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	return page, limit, (page - 1) * limit
+}
+
+// GetFraudSignals returns the review queue: unreviewed signals first, most severe
+// first. Filters: reviewed, severity, type and user_id.
+func (h *FraudHandler) GetFraudSignals(c *gin.Context) {
+	page, limit, offset := pagination(c)
+	filter := services.FraudSignalFilter{
+		Severity:   c.Query("severity"),
+		SignalType: c.Query("type"),
+		Limit:      limit,
+		Offset:     offset,
+	}
+	if v := c.Query("reviewed"); v != "" {
+		reviewed, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid reviewed filter"})
+			return
+		}
+		filter.Reviewed = &reviewed
+	}
+	if v := c.Query("user_id"); v != "" {
+		userID, err := uuid.Parse(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	signals, total, err := h.fraud.ListSignals(context.Background(), filter)
+	if err != nil {
+		log.Printf("Error fetching fraud signals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fraud signals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"signals": signals,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// ReviewFraudSignal confirms or dismisses a signal
+func (h *FraudHandler) ReviewFraudSignal(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	signalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid signal ID"})
+		return
+	}
+
+	var req models.ReviewFraudSignalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	signal, err := h.fraud.ReviewSignal(context.Background(), signalID, adminID, req.Action, req.Notes)
+	if err != nil {
+		if errors.Is(err, services.ErrFraudSignalNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Fraud signal not found"})
+			return
+		}
+		log.Printf("Error reviewing fraud signal %s: %v", signalID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review fraud signal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, signal)
+}
+
+// GetRiskyUsers returns users by fraud score, optionally filtered by risk_level
+func (h *FraudHandler) GetRiskyUsers(c *gin.Context) {
+	page, limit, offset := pagination(c)
+
+	users, total, err := h.fraud.ListMetrics(context.Background(), c.Query("risk_level"), limit, offset)
+	if err != nil {
+		log.Printf("Error fetching behaviour metrics: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": users,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
+
+// GetUserFraudProfile returns a user's behaviour metrics and all their signals
+func (h *FraudHandler) GetUserFraudProfile(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	ctx := context.Background()
+	// Users who have never been analysed have no metrics yet
+	metrics, _ := h.fraud.Metrics(ctx, userID)
+
+	signals, _, err := h.fraud.ListSignals(ctx, services.FraudSignalFilter{UserID: &userID, Limit: 100})
+	if err != nil {
+		log.Printf("Error fetching fraud signals for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch fraud signals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"metrics": metrics, "signals": signals})
+}
+
+// ReviewUserFraud confirms or dismisses all of a user's open signals, and can lift
+// their automatic flag
+func (h *FraudHandler) ReviewUserFraud(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req models.ReviewFraudSignalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	reviewed, err := h.fraud.ReviewUser(ctx, userID, adminID, req.Action, req.Notes, req.ClearFlag)
+	if err != nil {
+		log.Printf("Error reviewing fraud signals for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review user"})
+		return
+	}
+
+	metrics, _ := h.fraud.Metrics(ctx, userID)
+	c.JSON(http.StatusOK, gin.H{"reviewed": reviewed, "metrics": metrics})
+}
+
+// RescanUser analyses a user's bidding now instead of waiting for the fraud worker
+func (h *FraudHandler) RescanUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	metrics, err := h.fraud.Analyze(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error analysing user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to analyse user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, metrics)
+}
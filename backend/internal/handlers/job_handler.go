@@ -2,92 +2,93 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
+	"errors"
+	"log"
 	"net/http"
-	"time"
 
 	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
+// JobHandler lists the background jobs, their run history, and triggers them by hand
 type JobHandler struct {
-	db *database.DB
+	db   *database.DB
+	jobs *services.JobService
 }
 
-func NewJobHandler(db *database.DB) *JobHandler {
-	return &JobHandler{db: db}
+func NewJobHandler(db *database.DB, jobs *services.JobService) *JobHandler {
+	return &JobHandler{db: db, jobs: jobs}
 }
 
-// CheckStaleStores identifies stores with active products that haven't been confirmed in 30 days
-// and sends them a notification.
-func (h *JobHandler) CheckStaleStores(c *gin.Context) {
-	ctx := context.Background()
-
-	// 1. Find stores where ALL active products are stale (> 30 days old confirmation)
-	// We want stores that HAVE products, but none are fresh.
-	query := `
-		SELECT s.id, s.user_id, s.store_name, MAX(p.last_confirmed_at) as last_activity
-		FROM stores s
-		JOIN products p ON s.id = p.store_id
-		WHERE s.is_active = true AND p.is_available = true
-		GROUP BY s.id
-		HAVING MAX(p.last_confirmed_at) < NOW() - INTERVAL '30 days'
-	`
-
-	rows, err := h.db.Pool.Query(ctx, query)
+// ListJobs returns every job with its schedule and latest run
+func (h *JobHandler) ListJobs(c *gin.Context) {
+	jobs, err := h.jobs.Jobs(context.Background())
 	if err != nil {
-		fmt.Printf("Job Query Error: %v\n", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to query stale stores"})
+		log.Printf("Error fetching jobs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch jobs"})
 		return
 	}
-	defer rows.Close()
-
-	fmt.Println("Job Query Executed. Iterating rows...")
-	nudgedCount := 0
-	for rows.Next() {
-		fmt.Println("Row found!")
-		var storeID, userID uuid.UUID
-		var storeName string
-		var lastActivity time.Time
+	c.JSON(http.StatusOK, gin.H{"jobs": jobs})
+}
 
-		if err := rows.Scan(&storeID, &userID, &storeName, &lastActivity); err != nil {
-			fmt.Printf("Error scanning row: %v\n", err)
-			continue
-		}
-		fmt.Printf("Processing stale store: %s (%s)\n", storeName, storeID)
+// GetJobRuns returns the run history, newest first, optionally for one job
+func (h *JobHandler) GetJobRuns(c *gin.Context) {
+	page, limit, offset := pagination(c)
 
-		// 2. Check if we already sent a notification recently...
+	runs, total, err := h.jobs.Runs(context.Background(), c.Query("job"), limit, offset)
+	if err != nil {
+		log.Printf("Error fetching job runs: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch job runs"})
+		return
+	}
 
-		// 2. Check if we already sent a notification recently (e.g., in last 7 days)
-		// We use the notifications table. We'll look for a specific type/content.
-		// For simplicity, we can just check if *any* notification of type 'system_alert'
-		// with metadata={'type': 'freshness_nudge'} exists in last 7 days.
-		// Since we don't have rich metadata filtering efficiently, we might just insert and
-		// rely on the user clearing it, OR we'll skip complex dedup for this MVP step.
+	c.JSON(http.StatusOK, gin.H{
+		"runs":  runs,
+		"total": total,
+		"page":  page,
+		"limit": limit,
+	})
+}
 
-		// Let's send the notification.
-		message := fmt.Sprintf("Your store '%s' is looking dusty! View your dashboard to boost visibility.", storeName)
+// RunJob runs a job now and returns the recorded run
+func (h *JobHandler) RunJob(c *gin.Context) {
+	h.runJob(c, c.Param("name"))
+}
 
-		// JSON marshal metadata
-		metaJSON, _ := json.Marshal(map[string]string{"type": "freshness_nudge", "store_id": storeID.String()})
+// CheckStaleStores runs the stale store nudge now. Kept for existing cron callers;
+// the job worker runs it on a schedule.
+func (h *JobHandler) CheckStaleStores(c *gin.Context) {
+	h.runJob(c, services.JobNudgeStaleStores)
+}
 
-		// Insert notification
-		_, err := h.db.Pool.Exec(ctx, `
-			INSERT INTO notifications (user_id, type, title, body, data)
-			VALUES ($1, 'system', 'Boost Your Visibility', $2, $3)
-		`, userID, message, metaJSON)
+func (h *JobHandler) runJob(c *gin.Context, name string) {
+	// Job token callers have no user
+	var triggeredBy *uuid.UUID
+	if userID, ok := middleware.GetUserID(c); ok {
+		triggeredBy = &userID
+	}
 
-		if err == nil {
-			nudgedCount++
-		} else {
-			fmt.Printf("Error inserting notification: %v\n", err)
+	run, err := h.jobs.Run(context.Background(), name, models.JobTriggerManual, triggeredBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUnknownJob):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job not found", "code": "JOB_NOT_FOUND"})
+		case errors.Is(err, services.ErrJobRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "Job is already running", "code": "JOB_RUNNING"})
+		default:
+			log.Printf("Error running job %s: %v", name, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to run job"})
 		}
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message":      "Stale store check completed",
-		"stores_found": nudgedCount, // This is technically stores nudged
-	})
+	if run.Status == models.JobRunFailed {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Job failed", "code": "JOB_FAILED", "run": run})
+		return
+	}
+	c.JSON(http.StatusOK, run)
 }
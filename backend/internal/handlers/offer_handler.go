@@ -0,0 +1,435 @@
+package handlers
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/airmass/backend/internal/websocket"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// offerTTL is how long the other side has to answer an offer or counter-offer
+const offerTTL = 48 * time.Hour
+
+const offerColumns = `o.id, o.auction_id, o.buyer_id, o.seller_id, o.amount, o.counter_amount, o.message,
+	o.status, o.expires_at, o.responded_at, o.created_at, o.updated_at`
+
+// OfferHandler handles make-an-offer / counter-offer endpoints
+type OfferHandler struct {
+	db              *database.DB
+	hub             *websocket.Hub
+	orders          *services.OrderService
+	strikes         *services.StrikeService
+	notificationSvc *services.NotificationService
+}
+
+// NewOfferHandler creates a new offer handler
+func NewOfferHandler(db *database.DB, hub *websocket.Hub, orders *services.OrderService, strikes *services.StrikeService, notificationSvc *services.NotificationService) *OfferHandler {
+	return &OfferHandler{db: db, hub: hub, orders: orders, strikes: strikes, notificationSvc: notificationSvc}
+}
+
+// MakeOffer submits a buyer's offer on an active auction that allows offers
+func (h *OfferHandler) MakeOffer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	auctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var req models.MakeOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var auction models.Auction
+	err = h.db.Pool.QueryRow(context.Background(),
+		"SELECT id, title, seller_id, status, end_time, allow_offers FROM auctions WHERE id = $1",
+		auctionID,
+	).Scan(&auction.ID, &auction.Title, &auction.SellerID, &auction.Status, &auction.EndTime, &auction.AllowOffers)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	if !auction.AllowOffers {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "This auction does not accept offers", "code": "OFFERS_NOT_ALLOWED"})
+		return
+	}
+	if auction.SellerID == userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot make an offer on your own auction"})
+		return
+	}
+	if code, message := h.strikes.BiddingRestriction(context.Background(), userID, auction.SellerID); code != "" {
+		c.JSON(http.StatusForbidden, gin.H{"error": message, "code": code})
+		return
+	}
+	if (auction.Status != models.AuctionStatusActive && auction.Status != models.AuctionStatusEndingSoon) ||
+		(auction.EndTime != nil && time.Now().After(*auction.EndTime)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction is not active", "code": "AUCTION_NOT_ACTIVE"})
+		return
+	}
+
+	var offer models.Offer
+	err = h.db.Pool.QueryRow(context.Background(), `
+		INSERT INTO auction_offers AS o (auction_id, buyer_id, seller_id, amount, message, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+offerColumns,
+		auctionID, userID, auction.SellerID, math.Round(req.Amount*100)/100, req.Message, time.Now().Add(offerTTL),
+	).Scan(offerScanArgs(&offer)...)
+	if err != nil {
+		// Unique index allows one open offer per buyer per auction
+		c.JSON(http.StatusConflict, gin.H{"error": "You already have an open offer on this auction"})
+		return
+	}
+
+	h.notificationSvc.SendOfferNotification(context.Background(), auction.SellerID, websocket.MessageTypeOfferNew, &offer, auction.Title)
+
+	c.JSON(http.StatusCreated, offer)
+}
+
+// GetAuctionOffers returns all offers on an auction to its seller, or the caller's own offers otherwise
+func (h *OfferHandler) GetAuctionOffers(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	auctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	rows, err := h.db.Pool.Query(context.Background(), `
+		SELECT `+offerColumns+`, u.username, u.avatar_url
+		FROM auction_offers o
+		JOIN users u ON u.id = o.buyer_id
+		WHERE o.auction_id = $1 AND (o.seller_id = $2 OR o.buyer_id = $2)
+		ORDER BY o.created_at DESC`,
+		auctionID, userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch offers"})
+		return
+	}
+	defer rows.Close()
+
+	offers := []models.Offer{}
+	for rows.Next() {
+		var o models.Offer
+		var buyer models.User
+		if err := rows.Scan(append(offerScanArgs(&o), &buyer.Username, &buyer.AvatarURL)...); err != nil {
+			log.Printf("Error scanning offer: %v", err)
+			continue
+		}
+		buyer.ID = o.BuyerID
+		o.Buyer = &buyer
+		offers = append(offers, o)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offers": offers})
+}
+
+// GetMyOffers returns offers the user made (role=buyer, default) or received (role=seller)
+func (h *OfferHandler) GetMyOffers(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	column := "o.buyer_id"
+	if c.Query("role") == "seller" {
+		column = "o.seller_id"
+	}
+
+	rows, err := h.db.Pool.Query(context.Background(), `
+		SELECT `+offerColumns+`, a.title, a.images, a.status
+		FROM auction_offers o
+		JOIN auctions a ON a.id = o.auction_id
+		WHERE `+column+` = $1
+		ORDER BY o.created_at DESC
+		LIMIT 100`,
+		userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch offers"})
+		return
+	}
+	defer rows.Close()
+
+	offers := []models.Offer{}
+	for rows.Next() {
+		var o models.Offer
+		var a models.Auction
+		if err := rows.Scan(append(offerScanArgs(&o), &a.Title, &a.Images, &a.Status)...); err != nil {
+			log.Printf("Error scanning offer: %v", err)
+			continue
+		}
+		a.ID = o.AuctionID
+		o.Auction = &a
+		offers = append(offers, o)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offers": offers})
+}
+
+// CounterOffer lets the seller answer a pending offer with a higher price
+func (h *OfferHandler) CounterOffer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	var req models.CounterOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	counterAmount := math.Round(req.Amount*100) / 100
+
+	var offer models.Offer
+	err = h.db.Pool.QueryRow(context.Background(), `
+		UPDATE auction_offers o
+		SET status = 'countered', counter_amount = $1, expires_at = $2, responded_at = NOW(), updated_at = NOW()
+		WHERE o.id = $3 AND o.seller_id = $4 AND o.status = 'pending' AND o.expires_at > NOW() AND o.amount < $1
+		RETURNING `+offerColumns,
+		counterAmount, time.Now().Add(offerTTL), offerID, userID,
+	).Scan(offerScanArgs(&offer)...)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Offer cannot be countered. The counter must be above the offer and the offer must still be pending."})
+		return
+	}
+
+	h.notificationSvc.SendOfferNotification(context.Background(), offer.BuyerID, websocket.MessageTypeOfferCountered, &offer, h.auctionTitle(offer.AuctionID))
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// DeclineOffer lets the seller decline a pending offer, or the buyer decline a counter-offer
+func (h *OfferHandler) DeclineOffer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	var offer models.Offer
+	err = h.db.Pool.QueryRow(context.Background(), `
+		UPDATE auction_offers o
+		SET status = 'declined', responded_at = NOW(), updated_at = NOW()
+		WHERE o.id = $1 AND (
+			(o.status = 'pending' AND o.seller_id = $2) OR
+			(o.status = 'countered' AND o.buyer_id = $2)
+		)
+		RETURNING `+offerColumns,
+		offerID, userID,
+	).Scan(offerScanArgs(&offer)...)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found or not awaiting your response"})
+		return
+	}
+
+	// Tell the other side
+	recipient := offer.BuyerID
+	if userID == offer.BuyerID {
+		recipient = offer.SellerID
+	}
+	h.notificationSvc.SendOfferNotification(context.Background(), recipient, websocket.MessageTypeOfferDeclined, &offer, h.auctionTitle(offer.AuctionID))
+
+	c.JSON(http.StatusOK, offer)
+}
+
+// AcceptOffer closes the deal: the seller accepts a pending offer, or the buyer accepts a counter-offer.
+// The auction ends immediately as sold at the agreed amount.
+func (h *OfferHandler) AcceptOffer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	ctx := context.Background()
+	tx, err := h.db.Pool.Begin(ctx)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(ctx)
+
+	var offer models.Offer
+	err = tx.QueryRow(ctx,
+		"SELECT "+offerColumns+" FROM auction_offers o WHERE o.id = $1 FOR UPDATE",
+		offerID,
+	).Scan(offerScanArgs(&offer)...)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	}
+
+	canAccept := (offer.Status == models.OfferStatusPending && offer.SellerID == userID) ||
+		(offer.Status == models.OfferStatusCountered && offer.BuyerID == userID)
+	if !canAccept {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Offer is not awaiting your response"})
+		return
+	}
+	if time.Now().After(offer.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Offer has expired"})
+		return
+	}
+
+	// Lock the auction so no bid lands while it is being sold
+	var title string
+	var status models.AuctionStatus
+	var townID uuid.UUID
+	err = tx.QueryRow(ctx,
+		"SELECT title, status, town_id FROM auctions WHERE id = $1 FOR UPDATE",
+		offer.AuctionID,
+	).Scan(&title, &status, &townID)
+	if err != nil || (status != models.AuctionStatusActive && status != models.AuctionStatusEndingSoon) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction is no longer active"})
+		return
+	}
+
+	amount := offer.AgreedAmount()
+
+	if err = tx.QueryRow(ctx, `
+		UPDATE auction_offers o
+		SET status = 'accepted', responded_at = NOW(), updated_at = NOW()
+		WHERE o.id = $1
+		RETURNING `+offerColumns,
+		offerID,
+	).Scan(offerScanArgs(&offer)...); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
+		return
+	}
+
+	if _, err = tx.Exec(ctx, `
+		UPDATE auctions
+		SET status = 'sold', winner_id = $1, final_amount = $2, end_time = NOW(), updated_at = NOW()
+		WHERE id = $3`,
+		offer.BuyerID, amount, offer.AuctionID,
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
+		return
+	}
+
+	// Every other open offer on the auction is now moot
+	otherOffers, err := h.closeOtherOffers(ctx, tx, offer.AuctionID, offer.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
+		return
+	}
+
+	// Auto-bids stop with the auction
+	if _, err = tx.Exec(ctx, `
+		UPDATE auto_bids
+		SET is_active = false, deactivated_at = NOW(), deactivation_reason = 'auction_ended'
+		WHERE auction_id = $1 AND is_active = true`,
+		offer.AuctionID,
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
+		return
+	}
+
+	orderID, err := h.orders.Create(ctx, tx, offer.AuctionID, offer.BuyerID, offer.SellerID, amount, models.OrderSourceOffer)
+	if err != nil {
+		log.Printf("Error creating offer order: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
+		return
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit"})
+		return
+	}
+
+	// Same hand-off as a normal win: conversation first, then both parties are notified
+	conversationID, err := h.notificationSvc.CreateConversation(ctx, offer.AuctionID, offer.SellerID, offer.BuyerID)
+	if err != nil {
+		log.Printf("Error creating conversation: %v", err)
+	}
+
+	var buyerName string
+	h.db.Pool.QueryRow(ctx, "SELECT full_name FROM users WHERE id = $1", offer.BuyerID).Scan(&buyerName)
+
+	h.notificationSvc.SendAuctionWonNotification(ctx, offer.BuyerID, offer.AuctionID, conversationID, title, amount)
+	h.notificationSvc.SendAuctionSoldNotification(ctx, offer.SellerID, offer.AuctionID, conversationID, title, buyerName, amount)
+
+	// The side that did not click accept hears about it as an offer event too
+	counterparty := offer.BuyerID
+	if userID == offer.BuyerID {
+		counterparty = offer.SellerID
+	}
+	h.notificationSvc.SendOfferNotification(ctx, counterparty, websocket.MessageTypeOfferAccepted, &offer, title)
+
+	for i := range otherOffers {
+		h.notificationSvc.SendOfferNotification(ctx, otherOffers[i].BuyerID, websocket.MessageTypeOfferDeclined, &otherOffers[i], title)
+	}
+
+	h.hub.BroadcastToAuction(offer.AuctionID, websocket.MessageTypeAuctionUpdate, gin.H{
+		"action":       "status_change",
+		"auction_id":   offer.AuctionID,
+		"status":       models.AuctionStatusSold,
+		"final_amount": amount,
+		"sold_via":     "offer",
+	})
+	h.hub.BroadcastToTown(townID, websocket.MessageTypeAuctionUpdate, gin.H{
+		"action":     "auction_ended",
+		"auction_id": offer.AuctionID,
+		"title":      title,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Offer accepted",
+		"offer":           offer,
+		"conversation_id": conversationID,
+		"order_id":        orderID,
+	})
+}
+
+// closeOtherOffers declines the remaining open offers on an auction and returns them
+func (h *OfferHandler) closeOtherOffers(ctx context.Context, tx pgx.Tx, auctionID, acceptedID uuid.UUID) ([]models.Offer, error) {
+	rows, err := tx.Query(ctx, `
+		UPDATE auction_offers o
+		SET status = 'declined', responded_at = NOW(), updated_at = NOW()
+		WHERE o.auction_id = $1 AND o.id != $2 AND o.status IN ('pending', 'countered')
+		RETURNING `+offerColumns,
+		auctionID, acceptedID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var offers []models.Offer
+	for rows.Next() {
+		var o models.Offer
+		if err := rows.Scan(offerScanArgs(&o)...); err != nil {
+			return nil, err
+		}
+		offers = append(offers, o)
+	}
+	return offers, rows.Err()
+}
+
+func (h *OfferHandler) auctionTitle(auctionID uuid.UUID) string {
+	var title string
+	h.db.Pool.QueryRow(context.Background(), "SELECT title FROM auctions WHERE id = $1", auctionID).Scan(&title)
+	return title
+}
+
+// offerScanArgs returns scan targets matching offerColumns
+func offerScanArgs(o *models.Offer) []interface{} {
+	return []interface{}{
+		&o.ID, &o.AuctionID, &o.BuyerID, &o.SellerID, &o.Amount, &o.CounterAmount, &o.Message,
+		&o.Status, &o.ExpiresAt, &o.RespondedAt, &o.CreatedAt, &o.UpdatedAt,
+	}
+}
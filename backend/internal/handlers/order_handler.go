@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const orderColumns = `o.id, o.auction_id, o.buyer_id, o.seller_id, o.amount, o.source, o.status, o.payment_id,
+	o.payment_due_at, o.payment_reminder_sent_at, o.paid_at, o.dispatched_at, o.courier, o.tracking_number,
+	o.collected_at, o.received_at, o.release_due_at, o.released_at, o.cancelled_at, o.cancel_reason,
+	o.created_at, o.updated_at`
+
+// OrderHandler lets buyers pay for what they won and both sides track delivery until
+// the held payment is released
+type OrderHandler struct {
+	db              *database.DB
+	orders          *services.OrderService
+	notificationSvc *services.NotificationService
+}
+
+// NewOrderHandler creates a new order handler
+func NewOrderHandler(db *database.DB, orders *services.OrderService, notificationSvc *services.NotificationService) *OrderHandler {
+	return &OrderHandler{db: db, orders: orders, notificationSvc: notificationSvc}
+}
+
+func orderScanArgs(o *models.Order) []interface{} {
+	return []interface{}{&o.ID, &o.AuctionID, &o.BuyerID, &o.SellerID, &o.Amount, &o.Source, &o.Status, &o.PaymentID,
+		&o.PaymentDueAt, &o.PaymentReminderSentAt, &o.PaidAt, &o.DispatchedAt, &o.Courier, &o.TrackingNumber,
+		&o.CollectedAt, &o.ReceivedAt, &o.ReleaseDueAt, &o.ReleasedAt, &o.CancelledAt, &o.CancelReason,
+		&o.CreatedAt, &o.UpdatedAt}
+}
+
+// loadOrder returns an order the user is buyer or seller of, with its auction title and images
+func (h *OrderHandler) loadOrder(ctx context.Context, orderID, userID uuid.UUID) (*models.Order, error) {
+	var o models.Order
+	var a models.Auction
+	args := append(orderScanArgs(&o), &a.Title, &a.Images)
+	err := h.db.Pool.QueryRow(ctx, `
+		SELECT `+orderColumns+`, a.title, a.images
+		FROM orders o
+		JOIN auctions a ON a.id = o.auction_id
+		WHERE o.id = $1 AND (o.buyer_id = $2 OR o.seller_id = $2)`,
+		orderID, userID,
+	).Scan(args...)
+	if err != nil {
+		return nil, err
+	}
+	a.ID = o.AuctionID
+	o.Auction = &a
+	return &o, nil
+}
+
+// GetMyOrders returns the user's orders, newest first. role=buyer or role=seller
+// narrows the list to one side; status filters by order status.
+func (h *OrderHandler) GetMyOrders(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	query := `
+		SELECT ` + orderColumns + `, a.title, a.images
+		FROM orders o
+		JOIN auctions a ON a.id = o.auction_id
+		WHERE `
+	switch c.Query("role") {
+	case "buyer":
+		query += "o.buyer_id = $1"
+	case "seller":
+		query += "o.seller_id = $1"
+	case "":
+		query += "(o.buyer_id = $1 OR o.seller_id = $1)"
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be buyer or seller"})
+		return
+	}
+	args := []interface{}{userID}
+	if status := c.Query("status"); status != "" {
+		query += " AND o.status = $2"
+		args = append(args, status)
+	}
+	query += " ORDER BY o.created_at DESC LIMIT 100"
+
+	rows, err := h.db.Pool.Query(context.Background(), query, args...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch orders"})
+		return
+	}
+	defer rows.Close()
+
+	orders := []models.Order{}
+	for rows.Next() {
+		var o models.Order
+		var a models.Auction
+		if err := rows.Scan(append(orderScanArgs(&o), &a.Title, &a.Images)...); err != nil {
+			log.Printf("Error scanning order: %v", err)
+			continue
+		}
+		a.ID = o.AuctionID
+		o.Auction = &a
+		orders = append(orders, o)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orders": orders})
+}
+
+// GetOrder returns one of the user's orders
+func (h *OrderHandler) GetOrder(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	order, err := h.loadOrder(context.Background(), orderID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, order)
+}
+
+// PayOrder starts the buyer's payment for an order awaiting payment. The client
+// completes it with the provider; the order is marked paid when the provider's webhook
+// confirms it.
+func (h *OrderHandler) PayOrder(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	ctx := context.Background()
+	order, err := h.loadOrder(ctx, orderID, userID)
+	if err != nil || order.BuyerID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+	if order.Status != models.OrderStatusAwaitingPayment {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Order is not awaiting payment", "code": "ORDER_NOT_PAYABLE"})
+		return
+	}
+	if time.Now().After(order.PaymentDueAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "The payment deadline has passed", "code": "PAYMENT_OVERDUE"})
+		return
+	}
+
+	payment, err := h.orders.Pay(ctx, order, order.Auction.Title)
+	if err != nil {
+		log.Printf("Error starting order payment: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start payment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"order": order, "payment": payment})
+}
+
+// DispatchOrder lets the seller mark a paid order as sent, optionally with tracking details
+func (h *OrderHandler) DispatchOrder(c *gin.Context) {
+	var req models.DispatchOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.fulfil(c, models.OrderStatusDispatched, &req)
+}
+
+// MarkOrderCollected lets the seller mark a paid order as handed over in person
+func (h *OrderHandler) MarkOrderCollected(c *gin.Context) {
+	h.fulfil(c, models.OrderStatusCollected, nil)
+}
+
+// fulfil moves a paid order to dispatched or collected and starts the release window
+func (h *OrderHandler) fulfil(c *gin.Context, status models.OrderStatus, req *models.DispatchOrderRequest) {
+	userID, _ := middleware.GetUserID(c)
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	ctx := context.Background()
+	order, err := h.loadOrder(ctx, orderID, userID)
+	if err != nil || order.SellerID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+	if order.Status != models.OrderStatusPaid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only paid orders can be dispatched or collected", "code": "ORDER_NOT_PAID"})
+		return
+	}
+
+	var courier, trackingNumber *string
+	if req != nil {
+		courier, trackingNumber = req.Courier, req.TrackingNumber
+	}
+
+	auction := order.Auction
+	err = h.db.Pool.QueryRow(ctx, `
+		UPDATE orders o
+		SET status = $1,
+			dispatched_at = CASE WHEN $1 = 'dispatched' THEN NOW() ELSE o.dispatched_at END,
+			collected_at = CASE WHEN $1 = 'collected' THEN NOW() ELSE o.collected_at END,
+			courier = COALESCE($2, o.courier),
+			tracking_number = COALESCE($3, o.tracking_number),
+			release_due_at = $4,
+			updated_at = NOW()
+		WHERE o.id = $5 AND o.status = 'paid'
+		RETURNING `+orderColumns,
+		string(status), courier, trackingNumber, h.orders.ReleaseDue(ctx), orderID,
+	).Scan(orderScanArgs(order)...)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Order was updated by someone else, please retry"})
+		return
+	}
+	order.Auction = auction
+
+	h.notificationSvc.SendOrderFulfilledNotification(ctx, order.BuyerID, order, auction.Title)
+
+	c.JSON(http.StatusOK, order)
+}
+
+// ConfirmOrderReceived lets the buyer confirm they have the item, which completes the
+// order and releases the payment to the seller
+func (h *OrderHandler) ConfirmOrderReceived(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	orderID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid order ID"})
+		return
+	}
+
+	ctx := context.Background()
+	order, err := h.loadOrder(ctx, orderID, userID)
+	if err != nil || order.BuyerID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Order not found"})
+		return
+	}
+
+	released, err := h.orders.Release(ctx, orderID, true)
+	if err != nil {
+		log.Printf("Error releasing order: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to confirm receipt"})
+		return
+	}
+	if !released {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only paid orders can be confirmed as received", "code": "ORDER_NOT_PAID"})
+		return
+	}
+
+	order, _ = h.loadOrder(ctx, orderID, userID)
+	c.JSON(http.StatusOK, gin.H{"message": "Thanks for confirming. The payment has been released to the seller.", "order": order})
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/payments"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// paymentSignatureHeader carries the provider's webhook signature
+const paymentSignatureHeader = "X-Payment-Signature"
+
+// PaymentHandler receives payment provider webhooks and lists the user's payments
+type PaymentHandler struct {
+	db       *database.DB
+	payments *services.PaymentService
+}
+
+// NewPaymentHandler creates a new payment handler
+func NewPaymentHandler(db *database.DB, paymentSvc *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{db: db, payments: paymentSvc}
+}
+
+// Webhook applies a payment provider event. Only a valid signature is accepted, and
+// replays of an event are acknowledged without being applied twice.
+func (h *PaymentHandler) Webhook(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payload"})
+		return
+	}
+
+	err = h.payments.HandleWebhook(context.Background(), payload, c.GetHeader(paymentSignatureHeader))
+	switch {
+	case errors.Is(err, payments.ErrInvalidSignature):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+	case errors.Is(err, services.ErrPaymentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+	case err != nil:
+		log.Printf("Error handling payment webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+	default:
+		c.JSON(http.StatusOK, gin.H{"received": true})
+	}
+}
+
+// GetMyPayments returns the user's payments, newest first
+func (h *PaymentHandler) GetMyPayments(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	rows, err := h.db.Pool.Query(context.Background(), `
+		SELECT id, purpose, reference_id, provider, provider_intent_id, amount, currency, status,
+		refunded_amount, description, failure_reason, confirmed_at, refunded_at, created_at, updated_at
+		FROM payments WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT 100`,
+		userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch payments"})
+		return
+	}
+	defer rows.Close()
+
+	list := []models.Payment{}
+	for rows.Next() {
+		var p models.Payment
+		if err := rows.Scan(&p.ID, &p.Purpose, &p.ReferenceID, &p.Provider, &p.ProviderIntentID, &p.Amount,
+			&p.Currency, &p.Status, &p.RefundedAmount, &p.Description, &p.FailureReason, &p.ConfirmedAt,
+			&p.RefundedAt, &p.CreatedAt, &p.UpdatedAt); err != nil {
+			log.Printf("Error scanning payment: %v", err)
+			continue
+		}
+		p.UserID = &userID
+		list = append(list, p)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payments": list})
+}
+
+// ConfirmFakePayment stands in for the provider's checkout page while the fake
+// provider is configured: it confirms the intent and delivers the signed webhook the
+// provider would send
+func (h *PaymentHandler) ConfirmFakePayment(c *gin.Context) {
+	fake, ok := h.payments.Provider().(*payments.FakeProvider)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Not available"})
+		return
+	}
+
+	userID, _ := middleware.GetUserID(c)
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid payment ID"})
+		return
+	}
+
+	ctx := context.Background()
+	var intentID string
+	err = h.db.Pool.QueryRow(ctx,
+		"SELECT provider_intent_id FROM payments WHERE id = $1 AND user_id = $2 AND provider = $3",
+		paymentID, userID, fake.Name(),
+	).Scan(&intentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Payment not found"})
+		return
+	}
+
+	intent, err := fake.Confirm(ctx, intentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	payload, signature, err := fake.Webhook(intentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if err := h.payments.HandleWebhook(ctx, payload, signature); err != nil {
+		log.Printf("Error applying fake payment webhook: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply payment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment_id": paymentID, "status": intent.Status})
+}
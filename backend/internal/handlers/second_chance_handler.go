@@ -0,0 +1,281 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/airmass/backend/internal/websocket"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// secondChanceOfferTTL is how long the top bidder has to answer a second-chance offer
+const secondChanceOfferTTL = 48 * time.Hour
+
+// SecondChanceHandler handles offers to the top bidder of auctions that ended below
+// reserve, and to the next bidder when the winner did not pay
+type SecondChanceHandler struct {
+	db              *database.DB
+	hub             *websocket.Hub
+	orders          *services.OrderService
+	notificationSvc *services.NotificationService
+}
+
+// NewSecondChanceHandler creates a new second-chance offer handler
+func NewSecondChanceHandler(db *database.DB, hub *websocket.Hub, orders *services.OrderService, notificationSvc *services.NotificationService) *SecondChanceHandler {
+	return &SecondChanceHandler{db: db, hub: hub, orders: orders, notificationSvc: notificationSvc}
+}
+
+// CreateSecondChanceOffer lets the seller offer a reserve_not_met auction to its top bidder
+func (h *SecondChanceHandler) CreateSecondChanceOffer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	auctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var req models.SecondChanceOfferRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var sellerID uuid.UUID
+	var status, title string
+	var reservePrice *float64
+	err = h.db.Pool.QueryRow(context.Background(),
+		"SELECT seller_id, status, title, reserve_price FROM auctions WHERE id = $1",
+		auctionID,
+	).Scan(&sellerID, &status, &title, &reservePrice)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+
+	if sellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the seller can send a second-chance offer"})
+		return
+	}
+	if status != string(models.AuctionStatusReserveNotMet) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Second-chance offers are only available when the reserve was not met"})
+		return
+	}
+
+	// Top bidder and their bid. After a tied proxy round the runner-up's bid was written
+	// first at the same amount, so the top bid is the one marked is_winning.
+	var bidderID uuid.UUID
+	var topBid float64
+	err = h.db.Pool.QueryRow(context.Background(),
+		"SELECT bidder_id, amount FROM bids WHERE auction_id = $1 AND is_winning = true",
+		auctionID,
+	).Scan(&bidderID, &topBid)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction has no bids"})
+		return
+	}
+
+	// The offer sits between what the bidder already bid and the reserve
+	amount := topBid
+	if req.Amount != nil {
+		amount = math.Round(*req.Amount*100) / 100
+	}
+	if amount < topBid || (reservePrice != nil && amount > *reservePrice) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("Offer must be at least the top bid of $%.2f and not above your reserve", topBid),
+		})
+		return
+	}
+
+	var offer models.SecondChanceOffer
+	err = h.db.Pool.QueryRow(context.Background(), `
+		INSERT INTO second_chance_offers (auction_id, seller_id, bidder_id, amount, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, auction_id, seller_id, bidder_id, amount, reason, status, expires_at, created_at`,
+		auctionID, userID, bidderID, amount, time.Now().Add(secondChanceOfferTTL),
+	).Scan(&offer.ID, &offer.AuctionID, &offer.SellerID, &offer.BidderID, &offer.Amount,
+		&offer.Reason, &offer.Status, &offer.ExpiresAt, &offer.CreatedAt)
+	if err != nil {
+		// Unique index allows a single pending offer per auction
+		c.JSON(http.StatusConflict, gin.H{"error": "A second-chance offer is already open for this auction"})
+		return
+	}
+
+	h.notificationSvc.SendSecondChanceOfferNotification(context.Background(), bidderID, offer.ID, auctionID, title, amount)
+
+	c.JSON(http.StatusCreated, offer)
+}
+
+// GetMySecondChanceOffers returns second-chance offers made to the current user
+func (h *SecondChanceHandler) GetMySecondChanceOffers(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	rows, err := h.db.Pool.Query(context.Background(), `
+		SELECT o.id, o.auction_id, o.seller_id, o.bidder_id, o.amount, o.reason, o.status, o.expires_at,
+		o.responded_at, o.created_at, a.title, a.images
+		FROM second_chance_offers o
+		JOIN auctions a ON a.id = o.auction_id
+		WHERE o.bidder_id = $1
+		ORDER BY o.created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch offers"})
+		return
+	}
+	defer rows.Close()
+
+	offers := []models.SecondChanceOffer{}
+	for rows.Next() {
+		var o models.SecondChanceOffer
+		var a models.Auction
+		if err := rows.Scan(&o.ID, &o.AuctionID, &o.SellerID, &o.BidderID, &o.Amount, &o.Reason, &o.Status, &o.ExpiresAt,
+			&o.RespondedAt, &o.CreatedAt, &a.Title, &a.Images); err != nil {
+			log.Printf("Error scanning second-chance offer: %v", err)
+			continue
+		}
+		a.ID = o.AuctionID
+		o.Auction = &a
+		offers = append(offers, o)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"offers": offers})
+}
+
+// AcceptSecondChanceOffer sells the auction to the bidder at the offered amount
+func (h *SecondChanceHandler) AcceptSecondChanceOffer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	tx, err := h.db.Pool.Begin(context.Background())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start transaction"})
+		return
+	}
+	defer tx.Rollback(context.Background())
+
+	var offer models.SecondChanceOffer
+	err = tx.QueryRow(context.Background(), `
+		SELECT id, auction_id, seller_id, bidder_id, amount, status, expires_at
+		FROM second_chance_offers WHERE id = $1 FOR UPDATE`,
+		offerID,
+	).Scan(&offer.ID, &offer.AuctionID, &offer.SellerID, &offer.BidderID, &offer.Amount, &offer.Status, &offer.ExpiresAt)
+	if err != nil || offer.BidderID != userID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	}
+	if offer.Status != "pending" || time.Now().After(offer.ExpiresAt) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Offer is no longer available"})
+		return
+	}
+
+	// Lock the auction so it cannot be relisted or cancelled mid-accept
+	var title, status string
+	err = tx.QueryRow(context.Background(),
+		"SELECT title, status FROM auctions WHERE id = $1 FOR UPDATE",
+		offer.AuctionID,
+	).Scan(&title, &status)
+	if err != nil || (status != string(models.AuctionStatusReserveNotMet) && status != string(models.AuctionStatusUnpaid)) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Auction is no longer available"})
+		return
+	}
+
+	if _, err = tx.Exec(context.Background(),
+		"UPDATE second_chance_offers SET status = 'accepted', responded_at = NOW() WHERE id = $1",
+		offerID,
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
+		return
+	}
+
+	if _, err = tx.Exec(context.Background(), `
+		UPDATE auctions
+		SET status = 'sold', winner_id = $1, final_amount = $2, updated_at = NOW()
+		WHERE id = $3`,
+		userID, offer.Amount, offer.AuctionID,
+	); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
+		return
+	}
+
+	orderID, err := h.orders.Create(context.Background(), tx, offer.AuctionID, userID, offer.SellerID, offer.Amount, models.OrderSourceSecondChance)
+	if err != nil {
+		log.Printf("Error creating second-chance order: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to accept offer"})
+		return
+	}
+
+	if err = tx.Commit(context.Background()); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to commit"})
+		return
+	}
+
+	// Same hand-off as a normal win: conversation first, then both parties are notified
+	ctx := context.Background()
+	conversationID, err := h.notificationSvc.CreateConversation(ctx, offer.AuctionID, offer.SellerID, userID)
+	if err != nil {
+		log.Printf("Error creating conversation: %v", err)
+	}
+
+	var buyerName string
+	h.db.Pool.QueryRow(ctx, "SELECT full_name FROM users WHERE id = $1", userID).Scan(&buyerName)
+
+	h.notificationSvc.SendAuctionWonNotification(ctx, userID, offer.AuctionID, conversationID, title, offer.Amount)
+	h.notificationSvc.SendAuctionSoldNotification(ctx, offer.SellerID, offer.AuctionID, conversationID, title, buyerName, offer.Amount)
+
+	h.hub.BroadcastToAuction(offer.AuctionID, websocket.MessageTypeAuctionUpdate, gin.H{
+		"action":     "status_change",
+		"auction_id": offer.AuctionID,
+		"status":     models.AuctionStatusSold,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":         "Offer accepted. You won the auction!",
+		"conversation_id": conversationID,
+		"amount":          offer.Amount,
+		"order_id":        orderID,
+	})
+}
+
+// DeclineSecondChanceOffer turns down a second-chance offer
+func (h *SecondChanceHandler) DeclineSecondChanceOffer(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	offerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid offer ID"})
+		return
+	}
+
+	var auctionID, sellerID uuid.UUID
+	err = h.db.Pool.QueryRow(context.Background(), `
+		UPDATE second_chance_offers
+		SET status = 'declined', responded_at = NOW()
+		WHERE id = $1 AND bidder_id = $2 AND status = 'pending'
+		RETURNING auction_id, seller_id`,
+		offerID, userID,
+	).Scan(&auctionID, &sellerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Offer not found"})
+		return
+	}
+
+	var title string
+	h.db.Pool.QueryRow(context.Background(), "SELECT title FROM auctions WHERE id = $1", auctionID).Scan(&title)
+	h.notificationSvc.SendSecondChanceDeclinedNotification(context.Background(), sellerID, auctionID, title)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Offer declined"})
+}
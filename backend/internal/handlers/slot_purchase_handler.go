@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/payments"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const slotPurchaseColumns = `sp.id, sp.user_id, sp.category_id, sp.town_id, sp.auction_id, sp.amount_paid,
+	COALESCE(sp.original_wait_position, 0), sp.purchase_type, sp.status, sp.payment_provider, sp.payment_intent_id,
+	sp.created_at, sp.processed_at, sp.refunded_at, sp.refund_reason`
+
+// SlotPurchaseHandler sells queue skips and extra slots in full categories
+type SlotPurchaseHandler struct {
+	db        *database.DB
+	slots     *services.SlotService
+	purchases *services.SlotPurchaseService
+}
+
+// NewSlotPurchaseHandler creates a new slot purchase handler
+func NewSlotPurchaseHandler(db *database.DB, slots *services.SlotService, purchases *services.SlotPurchaseService) *SlotPurchaseHandler {
+	return &SlotPurchaseHandler{db: db, slots: slots, purchases: purchases}
+}
+
+func slotPurchaseScanArgs(p *models.SlotPurchase) []interface{} {
+	return []interface{}{&p.ID, &p.UserID, &p.CategoryID, &p.TownID, &p.AuctionID, &p.AmountPaid,
+		&p.OriginalWaitPosition, &p.PurchaseType, &p.Status, &p.PaymentProvider, &p.PaymentIntentID,
+		&p.CreatedAt, &p.ProcessedAt, &p.RefundedAt, &p.RefundReason}
+}
+
+// queuePosition returns the user's best place (1-based) in a category's slot queue, or 0
+func (h *SlotPurchaseHandler) queuePosition(ctx context.Context, userID, categoryID, townID uuid.UUID) int {
+	queue, err := h.slots.Queue(ctx, categoryID, townID, 0)
+	if err != nil {
+		return 0
+	}
+	for i, item := range queue {
+		if item.UserID == userID {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// hasUnusedPurchase reports whether the user already paid for a purchase of this type
+// that has not been used yet
+func (h *SlotPurchaseHandler) hasUnusedPurchase(ctx context.Context, userID, categoryID, townID uuid.UUID, purchaseType string) bool {
+	var exists bool
+	h.db.Pool.QueryRow(ctx,
+		`SELECT EXISTS(
+			SELECT 1 FROM slot_purchases
+			WHERE user_id = $1 AND category_id = $2 AND town_id = $3 AND purchase_type = $4
+			AND status IN ('pending', 'completed') AND auction_id IS NULL AND processed_at IS NULL
+		)`,
+		userID, categoryID, townID, purchaseType,
+	).Scan(&exists)
+	return exists
+}
+
+// GetSlotPricing returns skip-queue and extra-slot prices for a category in the user's
+// town, with what the user could buy right now
+func (h *SlotPurchaseHandler) GetSlotPricing(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	ctx := context.Background()
+	var townID uuid.UUID
+	if err := h.db.Pool.QueryRow(ctx, "SELECT home_town_id FROM users WHERE id = $1", userID).Scan(&townID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please set your home town first"})
+		return
+	}
+
+	pricing := h.purchases.Pricing(ctx, categoryID, townID)
+	position := h.queuePosition(ctx, userID, categoryID, townID)
+	available := h.slots.AvailableSlots(ctx, categoryID, townID)
+
+	c.JSON(http.StatusOK, gin.H{
+		"pricing":         pricing,
+		"available_slots": available,
+		"queue_position":  position,
+		"can_skip_queue": pricing.IsActive && position > 1 &&
+			!h.hasUnusedPurchase(ctx, userID, categoryID, townID, services.SlotPurchaseSkipQueue),
+		"can_buy_extra_slot": pricing.IsActive && available == 0 &&
+			!h.hasUnusedPurchase(ctx, userID, categoryID, townID, services.SlotPurchaseExtraSlot),
+	})
+}
+
+// PurchaseSlot pays for a queue skip or an extra slot in a full category.
+// A skip moves the seller's queued auction or waiting-list entry to the front; an extra
+// slot lets their next auction go live on top of the category limit. Purchases that
+// can't be used are refunded by the auction worker.
+func (h *SlotPurchaseHandler) PurchaseSlot(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.PurchaseSlotRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	var townID uuid.UUID
+	if err := h.db.Pool.QueryRow(ctx, "SELECT home_town_id FROM users WHERE id = $1", userID).Scan(&townID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please set your home town first"})
+		return
+	}
+
+	pricing := h.purchases.Pricing(ctx, req.CategoryID, townID)
+	if !pricing.IsActive {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Slot purchases are not available in this category"})
+		return
+	}
+
+	if h.hasUnusedPurchase(ctx, userID, req.CategoryID, townID, req.PurchaseType) {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "You already have an unused purchase of this kind in this category",
+			"code":  "PURCHASE_EXISTS",
+		})
+		return
+	}
+
+	position := h.queuePosition(ctx, userID, req.CategoryID, townID)
+	var amount float64
+	switch req.PurchaseType {
+	case services.SlotPurchaseSkipQueue:
+		if position == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "Join the waiting list or queue an auction in this category first",
+				"code":  "NOT_IN_QUEUE",
+			})
+			return
+		}
+		if position == 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You are already first in line"})
+			return
+		}
+		amount = pricing.SkipQueuePrice
+	case services.SlotPurchaseExtraSlot:
+		if h.slots.HasCapacity(ctx, req.CategoryID, townID) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "This category has a free slot. Create your auction directly.",
+				"code":  "SLOT_AVAILABLE",
+			})
+			return
+		}
+		amount = pricing.ExtraSlotPrice
+	}
+
+	var waitPosition *int
+	if position > 0 {
+		waitPosition = &position
+	}
+
+	var purchase models.SlotPurchase
+	err := h.db.Pool.QueryRow(ctx, `
+		INSERT INTO slot_purchases AS sp (user_id, category_id, town_id, amount_paid, original_wait_position, purchase_type, status)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending')
+		RETURNING `+slotPurchaseColumns,
+		userID, req.CategoryID, townID, amount, waitPosition, req.PurchaseType,
+	).Scan(slotPurchaseScanArgs(&purchase)...)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create purchase"})
+		return
+	}
+
+	if err := h.purchases.Checkout(ctx, &purchase); err != nil {
+		if errors.Is(err, payments.ErrPaymentDeclined) {
+			c.JSON(http.StatusPaymentRequired, gin.H{"error": "Your payment was declined", "code": "PAYMENT_DECLINED"})
+			return
+		}
+		log.Printf("Slot purchase checkout failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Payment failed. You have not been charged."})
+		return
+	}
+
+	message := "Extra slot purchased. Your next auction in this category goes live straight away."
+	if req.PurchaseType == services.SlotPurchaseSkipQueue {
+		message = "Queue skipped. You're now at the front of the line."
+		if err := h.slots.RenumberWaitingList(ctx, req.CategoryID, townID); err != nil {
+			log.Printf("Failed to renumber waiting list: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"purchase": purchase, "message": message})
+}
+
+// GetMySlotPurchases returns the user's slot purchases, newest first
+func (h *SlotPurchaseHandler) GetMySlotPurchases(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	rows, err := h.db.Pool.Query(context.Background(), `
+		SELECT `+slotPurchaseColumns+`
+		FROM slot_purchases sp
+		WHERE sp.user_id = $1
+		ORDER BY sp.created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch purchases"})
+		return
+	}
+	defer rows.Close()
+
+	purchases := []models.SlotPurchase{}
+	for rows.Next() {
+		var p models.SlotPurchase
+		if err := rows.Scan(slotPurchaseScanArgs(&p)...); err != nil {
+			log.Printf("Error scanning slot purchase: %v", err)
+			continue
+		}
+		purchases = append(purchases, p)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"purchases": purchases})
+}
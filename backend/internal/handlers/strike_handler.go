@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// StrikeHandler handles unpaid item reports and sellers' blocked bidder lists
+type StrikeHandler struct {
+	db      *database.DB
+	strikes *services.StrikeService
+}
+
+// NewStrikeHandler creates a new strike handler
+func NewStrikeHandler(db *database.DB, strikes *services.StrikeService) *StrikeHandler {
+	return &StrikeHandler{db: db, strikes: strikes}
+}
+
+// ReportUnpaidItem lets the seller report that the buyer of their auction never paid.
+// It is available once the buyer's order has passed its payment deadline, and records
+// one strike against the buyer per auction.
+func (h *StrikeHandler) ReportUnpaidItem(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	auctionID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid auction ID"})
+		return
+	}
+
+	var req models.ReportUnpaidItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	var sellerID uuid.UUID
+	if err := h.db.Pool.QueryRow(ctx, "SELECT seller_id FROM auctions WHERE id = $1", auctionID).Scan(&sellerID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Auction not found"})
+		return
+	}
+	if sellerID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the seller can report an unpaid item"})
+		return
+	}
+
+	// The latest order that lapsed, whether or not the worker has cancelled it yet
+	var orderID, buyerID uuid.UUID
+	err = h.db.Pool.QueryRow(ctx, `
+		SELECT id, buyer_id FROM orders
+		WHERE auction_id = $1 AND seller_id = $2
+		AND (
+			(status = 'cancelled' AND cancel_reason = 'non_payment')
+			OR (status = 'awaiting_payment' AND payment_due_at <= NOW())
+		)
+		ORDER BY created_at DESC
+		LIMIT 1`,
+		auctionID, userID,
+	).Scan(&orderID, &buyerID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "You can report an unpaid item once the buyer's payment deadline has passed",
+			"code":  "NOT_UNPAID",
+		})
+		return
+	}
+
+	strike, err := h.strikes.RecordUnpaidStrike(ctx, buyerID, auctionID, &orderID, userID, req.Notes)
+	if errors.Is(err, services.ErrStrikeExists) {
+		c.JSON(http.StatusConflict, gin.H{"error": "You already reported this buyer for this auction", "code": "ALREADY_REPORTED"})
+		return
+	}
+	if err != nil {
+		log.Printf("Error recording unpaid item strike: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to report unpaid item"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Unpaid item reported", "strike": strike})
+}
+
+// GetBlockedBidders returns the bidders the current user has blocked from their auctions
+func (h *StrikeHandler) GetBlockedBidders(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	rows, err := h.db.Pool.Query(context.Background(), `
+		SELECT b.bidder_id, b.reason, b.created_at, u.username, u.full_name, u.avatar_url
+		FROM seller_blocked_bidders b
+		JOIN users u ON u.id = b.bidder_id
+		WHERE b.seller_id = $1
+		ORDER BY b.created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocked bidders"})
+		return
+	}
+	defer rows.Close()
+
+	blocked := []models.BlockedBidder{}
+	for rows.Next() {
+		var b models.BlockedBidder
+		var u models.User
+		if err := rows.Scan(&b.BidderID, &b.Reason, &b.CreatedAt, &u.Username, &u.FullName, &u.AvatarURL); err != nil {
+			log.Printf("Error scanning blocked bidder: %v", err)
+			continue
+		}
+		u.ID = b.BidderID
+		b.Bidder = &u
+		blocked = append(blocked, b)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"blocked_bidders": blocked})
+}
+
+// BlockBidder stops a user from bidding on, buying or making offers on the current
+// user's auctions
+func (h *StrikeHandler) BlockBidder(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.BlockBidderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.UserID == userID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot block yourself"})
+		return
+	}
+
+	ctx := context.Background()
+	var exists bool
+	h.db.Pool.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM users WHERE id = $1)", req.UserID).Scan(&exists)
+	if !exists {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		return
+	}
+
+	if err := h.strikes.Block(ctx, userID, req.UserID, req.Reason); err != nil {
+		log.Printf("Error blocking bidder: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block bidder"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Bidder blocked", "bidder_id": req.UserID})
+}
+
+// UnblockBidder lets a blocked user bid on the current user's auctions again
+func (h *StrikeHandler) UnblockBidder(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	bidderID, err := uuid.Parse(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	result, err := h.db.Pool.Exec(context.Background(),
+		"DELETE FROM seller_blocked_bidders WHERE seller_id = $1 AND bidder_id = $2",
+		userID, bidderID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock bidder"})
+		return
+	}
+	if result.RowsAffected() == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Bidder is not blocked"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Bidder unblocked"})
+}
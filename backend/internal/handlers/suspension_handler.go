@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SuspensionHandler handles timed suspensions and their appeals
+type SuspensionHandler struct {
+	db          *database.DB
+	suspensions *services.SuspensionService
+}
+
+// NewSuspensionHandler creates a new suspension handler
+func NewSuspensionHandler(db *database.DB, suspensions *services.SuspensionService) *SuspensionHandler {
+	return &SuspensionHandler{db: db, suspensions: suspensions}
+}
+
+// SuspendUser suspends a user until a time or for a number of hours (Admin)
+func (h *SuspensionHandler) SuspendUser(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+	if userID == adminID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot suspend yourself"})
+		return
+	}
+
+	var req models.SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var until time.Time
+	switch {
+	case req.Until != nil:
+		until = *req.Until
+	case req.DurationHours > 0:
+		until = time.Now().Add(time.Duration(req.DurationHours) * time.Hour)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Either until or duration_hours is required"})
+		return
+	}
+	if !until.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Suspension must end in the future"})
+		return
+	}
+
+	suspension, err := h.suspensions.Suspend(context.Background(), userID, adminID, until, req.Reason)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		log.Printf("Error suspending user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+		return
+	}
+
+	c.JSON(http.StatusOK, suspension)
+}
+
+// LiftSuspension ends a user's suspension early (Admin)
+func (h *SuspensionHandler) LiftSuspension(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.suspensions.Lift(context.Background(), userID, adminID); err != nil {
+		if errors.Is(err, services.ErrNotSuspended) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User is not suspended", "code": "NOT_SUSPENDED"})
+			return
+		}
+		log.Printf("Error lifting suspension of user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lift suspension"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Suspension lifted"})
+}
+
+// GetMySuspension returns the user's current suspension and its appeal, if any
+func (h *SuspensionHandler) GetMySuspension(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	suspension, err := h.suspensions.Current(context.Background(), userID)
+	if err != nil {
+		log.Printf("Error fetching suspension for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch suspension"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"suspended": suspension != nil, "suspension": suspension})
+}
+
+// AppealSuspension appeals the user's current suspension
+func (h *SuspensionHandler) AppealSuspension(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.CreateAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appeal, err := h.suspensions.Appeal(context.Background(), userID, req.Message)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrNotSuspended):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "You are not suspended", "code": "NOT_SUSPENDED"})
+		case errors.Is(err, services.ErrAppealExists):
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already appealed this suspension", "code": "APPEAL_EXISTS"})
+		default:
+			log.Printf("Error creating appeal for user %s: %v", userID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to submit appeal"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, appeal)
+}
+
+// GetAppeals returns suspension appeals, optionally filtered by status (Admin)
+func (h *SuspensionHandler) GetAppeals(c *gin.Context) {
+	page, limit, offset := pagination(c)
+
+	appeals, total, err := h.suspensions.ListAppeals(context.Background(), c.Query("status"), limit, offset)
+	if err != nil {
+		log.Printf("Error fetching appeals: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch appeals"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"appeals": appeals,
+		"total":   total,
+		"page":    page,
+		"limit":   limit,
+	})
+}
+
+// ReviewAppeal approves or rejects an appeal; approving lifts the suspension (Admin)
+func (h *SuspensionHandler) ReviewAppeal(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+	appealID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appeal ID"})
+		return
+	}
+
+	var req models.ReviewAppealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	appeal, err := h.suspensions.ReviewAppeal(context.Background(), appealID, adminID, req.Decision, req.Response)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAppealNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Appeal not found"})
+		case errors.Is(err, services.ErrAppealNotPending):
+			c.JSON(http.StatusConflict, gin.H{"error": "Appeal has already been reviewed", "code": "APPEAL_REVIEWED"})
+		default:
+			log.Printf("Error reviewing appeal %s: %v", appealID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to review appeal"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const waitingListColumns = `wl.id, wl.user_id, wl.category_id, wl.town_id, COALESCE(wl.position, 0), wl.status,
+	wl.auction_title, wl.auction_description, wl.expected_starting_price, wl.notified_at, wl.expires_at,
+	wl.auction_id, wl.created_at`
+
+// WaitingListHandler lets sellers queue for a slot in a full category
+type WaitingListHandler struct {
+	db    *database.DB
+	slots *services.SlotService
+}
+
+// NewWaitingListHandler creates a new waiting list handler
+func NewWaitingListHandler(db *database.DB, slots *services.SlotService) *WaitingListHandler {
+	return &WaitingListHandler{db: db, slots: slots}
+}
+
+func waitingListScanArgs(e *models.WaitingListEntry) []interface{} {
+	return []interface{}{&e.ID, &e.UserID, &e.CategoryID, &e.TownID, &e.Position, &e.Status,
+		&e.AuctionTitle, &e.AuctionDescription, &e.ExpectedStartingPrice, &e.NotifiedAt, &e.ExpiresAt,
+		&e.AuctionID, &e.CreatedAt}
+}
+
+// JoinWaitingList queues the user for the next free slot in a category of their home town
+func (h *WaitingListHandler) JoinWaitingList(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req models.JoinWaitingListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := context.Background()
+	var townID uuid.UUID
+	if err := h.db.Pool.QueryRow(ctx, "SELECT home_town_id FROM users WHERE id = $1", userID).Scan(&townID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please set your home town before joining a waiting list"})
+		return
+	}
+
+	if _, ok := h.slots.ActiveClaim(ctx, userID, req.CategoryID, townID); ok {
+		c.JSON(http.StatusConflict, gin.H{
+			"error": "A slot is already reserved for you in this category. Create your auction to use it.",
+			"code":  "SLOT_RESERVED",
+		})
+		return
+	}
+
+	// Nobody to queue behind: the seller can list straight away
+	if h.slots.HasCapacity(ctx, req.CategoryID, townID) {
+		if queue, err := h.slots.Queue(ctx, req.CategoryID, townID, 1); err == nil && len(queue) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "This category has a free slot. Create your auction directly.",
+				"code":  "SLOT_AVAILABLE",
+			})
+			return
+		}
+	}
+
+	// Rejoining after leaving, expiring or using a slot starts a fresh place at the back
+	var entry models.WaitingListEntry
+	err := h.db.Pool.QueryRow(ctx, `
+		INSERT INTO waiting_list AS wl (user_id, category_id, town_id, auction_title, auction_description, expected_starting_price)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id, category_id, town_id) DO UPDATE SET
+			status = 'waiting',
+			auction_title = EXCLUDED.auction_title,
+			auction_description = EXCLUDED.auction_description,
+			expected_starting_price = EXCLUDED.expected_starting_price,
+			notified_at = NULL,
+			expires_at = NULL,
+			auction_id = NULL,
+			created_at = NOW()
+		WHERE wl.status IN ('expired', 'cancelled', 'claimed')
+		RETURNING `+waitingListColumns,
+		userID, req.CategoryID, townID, req.AuctionTitle, req.AuctionDescription, req.ExpectedStartingPrice,
+	).Scan(waitingListScanArgs(&entry)...)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "You are already on the waiting list for this category"})
+		return
+	}
+
+	if err := h.slots.RenumberWaitingList(ctx, req.CategoryID, townID); err != nil {
+		log.Printf("Failed to renumber waiting list: %v", err)
+	}
+
+	c.JSON(http.StatusCreated, h.buildResponse(ctx, &entry))
+}
+
+// GetMyWaitingList returns the user's open waiting-list entries with their place in line
+func (h *WaitingListHandler) GetMyWaitingList(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	ctx := context.Background()
+
+	rows, err := h.db.Pool.Query(ctx, `
+		SELECT `+waitingListColumns+`, cat.name, t.name
+		FROM waiting_list wl
+		JOIN categories cat ON cat.id = wl.category_id
+		JOIN towns t ON t.id = wl.town_id
+		WHERE wl.user_id = $1 AND wl.status IN ('waiting', 'slot_available')
+		ORDER BY wl.created_at ASC`,
+		userID,
+	)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch waiting list"})
+		return
+	}
+
+	var entries []models.WaitingListEntry
+	for rows.Next() {
+		var e models.WaitingListEntry
+		var categoryName, townName string
+		if err := rows.Scan(append(waitingListScanArgs(&e), &categoryName, &townName)...); err != nil {
+			log.Printf("Error scanning waiting list entry: %v", err)
+			continue
+		}
+		e.Category = &models.Category{ID: e.CategoryID, Name: categoryName}
+		e.Town = &models.Town{ID: e.TownID, Name: townName}
+		entries = append(entries, e)
+	}
+	rows.Close()
+
+	responses := []models.WaitingListResponse{}
+	for i := range entries {
+		responses = append(responses, *h.buildResponse(ctx, &entries[i]))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entries": responses})
+}
+
+// GetCategoryWaitingList shows how busy a category is in the user's town and where the
+// user stands in its queue
+func (h *WaitingListHandler) GetCategoryWaitingList(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	categoryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid category ID"})
+		return
+	}
+
+	ctx := context.Background()
+	var townID uuid.UUID
+	if err := h.db.Pool.QueryRow(ctx, "SELECT home_town_id FROM users WHERE id = $1", userID).Scan(&townID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Please set your home town first"})
+		return
+	}
+
+	queue, err := h.slots.Queue(ctx, categoryID, townID, 0)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch waiting list"})
+		return
+	}
+
+	resp := gin.H{
+		"category_id":     categoryID,
+		"town_id":         townID,
+		"max_active":      h.slots.MaxActive(ctx, categoryID, townID),
+		"active_count":    h.slots.ActiveCount(ctx, categoryID, townID),
+		"available_slots": h.slots.AvailableSlots(ctx, categoryID, townID),
+		"queue_length":    len(queue),
+		"entry":           nil,
+	}
+
+	var entry models.WaitingListEntry
+	err = h.db.Pool.QueryRow(ctx, `
+		SELECT `+waitingListColumns+`
+		FROM waiting_list wl
+		WHERE wl.user_id = $1 AND wl.category_id = $2 AND wl.town_id = $3
+		AND wl.status IN ('waiting', 'slot_available')`,
+		userID, categoryID, townID,
+	).Scan(waitingListScanArgs(&entry)...)
+	if err == nil {
+		resp["entry"] = h.buildResponse(ctx, &entry)
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// LeaveWaitingList removes the user from a queue, giving up any slot reserved for them
+func (h *WaitingListHandler) LeaveWaitingList(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	entryID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid waiting list ID"})
+		return
+	}
+
+	ctx := context.Background()
+	var categoryID, townID uuid.UUID
+	err = h.db.Pool.QueryRow(ctx, `
+		UPDATE waiting_list SET status = 'cancelled', expires_at = NULL
+		WHERE id = $1 AND user_id = $2 AND status IN ('waiting', 'slot_available')
+		RETURNING category_id, town_id`,
+		entryID, userID,
+	).Scan(&categoryID, &townID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Waiting list entry not found"})
+		return
+	}
+
+	// A released reservation is handed to the next in line by the auction worker
+	if err := h.slots.RenumberWaitingList(ctx, categoryID, townID); err != nil {
+		log.Printf("Failed to renumber waiting list: %v", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "You have left the waiting list"})
+}
+
+// buildResponse adds the live queue position and estimated wait to an entry
+func (h *WaitingListHandler) buildResponse(ctx context.Context, entry *models.WaitingListEntry) *models.WaitingListResponse {
+	resp := &models.WaitingListResponse{Entry: entry}
+
+	if entry.Status == models.WaitingStatusSlotAvailable && entry.ExpiresAt != nil {
+		resp.EstimatedWait = fmt.Sprintf("Slot ready, claim within %s", formatDuration(time.Until(*entry.ExpiresAt)))
+		return resp
+	}
+
+	queue, err := h.slots.Queue(ctx, entry.CategoryID, entry.TownID, 0)
+	if err != nil {
+		return resp
+	}
+	for i, item := range queue {
+		if item.Kind == services.QueueItemWaiting && item.ID == entry.ID {
+			resp.Position = i + 1
+			resp.AheadOfYou = i
+			entry.Position = i + 1
+			break
+		}
+	}
+
+	if wait := h.slots.EstimatedWait(ctx, entry.CategoryID, entry.TownID, resp.Position); wait > 0 {
+		resp.EstimatedWait = formatDuration(wait)
+	} else {
+		resp.EstimatedWait = "Next free slot"
+	}
+	return resp
+}
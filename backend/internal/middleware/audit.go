@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// maxAuditBody is the largest request body copied into the audit log
+const maxAuditBody = 64 * 1024
+
+// Audit records a successful admin action in the audit log. target says which row the
+// route changes; it is captured before and after the handler runs. Must run after Auth.
+func Audit(audit *services.AuditService, action string, target services.AuditTarget) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.Background()
+		key := ""
+		if target.Param != "" {
+			key = c.Param(target.Param)
+		}
+		before := audit.Snapshot(ctx, target, key)
+		body := auditBody(c)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= http.StatusBadRequest {
+			return
+		}
+
+		client := GetClientInfo(c)
+		entry := &models.AdminAuditEntry{
+			Action:      action,
+			Before:      before,
+			After:       audit.Snapshot(ctx, target, key),
+			Method:      c.Request.Method,
+			Path:        c.Request.URL.Path,
+			StatusCode:  status,
+			RequestBody: body,
+			UserAgent:   &client.UserAgent,
+		}
+		if userID, ok := GetUserID(c); ok {
+			entry.ActorID = &userID
+		}
+		if role := GetRole(c); role != "" {
+			entry.ActorRole = &role
+		}
+		if target.EntityType != "" {
+			entry.EntityType = &target.EntityType
+		}
+		if key != "" {
+			entry.EntityID = &key
+		}
+		if client.IPAddress != "" {
+			entry.IPAddress = &client.IPAddress
+		}
+		if id := GetRequestID(c); id != "" {
+			entry.RequestID = &id
+		}
+		audit.Record(ctx, entry)
+	}
+}
+
+// auditBody returns a copy of a JSON request body, leaving the body readable for the
+// handler. Fields that look like secrets are blanked at any depth.
+func auditBody(c *gin.Context) json.RawMessage {
+	if c.Request.Body == nil || !strings.HasPrefix(c.ContentType(), "application/json") {
+		return nil
+	}
+	raw, err := io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBody+1))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(raw), c.Request.Body))
+	if err != nil || len(raw) == 0 || len(raw) > maxAuditBody || !json.Valid(raw) {
+		return nil
+	}
+
+	// UseNumber keeps large numbers exactly as sent
+	var body interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if decoder.Decode(&body) != nil {
+		return nil
+	}
+	redacted, err := json.Marshal(redactSecrets(body))
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// redactSecrets blanks the values of object fields whose names look like secrets,
+// in nested objects and arrays too
+func redactSecrets(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			lower := strings.ToLower(name)
+			if strings.Contains(lower, "password") || strings.Contains(lower, "token") || strings.Contains(lower, "secret") {
+				v[name] = "[redacted]"
+			} else {
+				v[name] = redactSecrets(field)
+			}
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactSecrets(v[i])
+		}
+	}
+	return v
+}
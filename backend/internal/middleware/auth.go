@@ -1,39 +1,89 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
+	"time"
 
+	"github.com/airmass/backend/internal/services"
 	"github.com/airmass/backend/pkg/jwt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
 
-// Auth middleware validates JWT token
+// Auth middleware validates JWT token. Signed-out sessions and banned or suspended
+// users are refused.
 func Auth(jwtService *jwt.Service) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Authorization header required"})
-			return
+		if authenticate(c, jwtService, false) {
+			c.Next()
 		}
+	}
+}
 
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid authorization format"})
-			return
+// AuthAllowSuspended is Auth for the few routes a suspended user still needs: their
+// profile, signing out and appealing the suspension
+func AuthAllowSuspended(jwtService *jwt.Service) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticate(c, jwtService, true) {
+			c.Next()
 		}
+	}
+}
 
-		claims, err := jwtService.ValidateToken(parts[1])
-		if err != nil {
-			c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token"})
-			return
-		}
+// authenticate validates the bearer token and stores its claims in the context,
+// aborting when it is missing or invalid or its user has lost access
+func authenticate(c *gin.Context, jwtService *jwt.Service, allowSuspended bool) bool {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Authorization header required"})
+		return false
+	}
 
-		c.Set("user_id", claims.UserID)
-		c.Set("user_email", claims.Email)
-		c.Set("user_username", claims.Username)
-		c.Next()
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid authorization format"})
+		return false
+	}
+
+	claims, err := jwtService.Parse(parts[1])
+	if err != nil {
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token"})
+		return false
 	}
+
+	err = jwtService.CheckAccess(claims)
+	var suspended *services.AccountSuspendedError
+	switch {
+	case err == nil, allowSuspended && errors.As(err, &suspended):
+	case errors.Is(err, jwt.ErrTokenRevoked):
+		c.AbortWithStatusJSON(401, gin.H{"error": "Session has been signed out", "code": "TOKEN_REVOKED"})
+		return false
+	case errors.Is(err, services.ErrAccountDisabled):
+		c.AbortWithStatusJSON(403, gin.H{"error": "Account is disabled", "code": "ACCOUNT_DISABLED"})
+		return false
+	case errors.Is(err, services.ErrAccessCheckFailed):
+		c.AbortWithStatusJSON(503, gin.H{"error": "Could not verify session, try again", "code": "ACCESS_CHECK_FAILED"})
+		return false
+	case errors.As(err, &suspended):
+		c.AbortWithStatusJSON(403, gin.H{
+			"error":           "Account is suspended",
+			"code":            "ACCOUNT_SUSPENDED",
+			"suspended_until": suspended.Until.Format(time.RFC3339),
+			"reason":          suspended.Reason,
+		})
+		return false
+	default:
+		c.AbortWithStatusJSON(401, gin.H{"error": "Invalid token"})
+		return false
+	}
+
+	c.Set("user_id", claims.UserID)
+	c.Set("user_email", claims.Email)
+	c.Set("user_username", claims.Username)
+	c.Set("user_role", claims.Role)
+	c.Set("session_id", claims.SessionID)
+	return true
 }
 
 // OptionalAuth middleware validates JWT if present
@@ -52,12 +102,22 @@ func OptionalAuth(jwtService *jwt.Service) gin.HandlerFunc {
 				c.Set("user_id", claims.UserID)
 				c.Set("user_email", claims.Email)
 				c.Set("user_username", claims.Username)
+				c.Set("user_role", claims.Role)
+				c.Set("session_id", claims.SessionID)
 			}
 		}
 		c.Next()
 	}
 }
 
+// GetSessionID gets the device session of the request's token; uuid.Nil for tokens
+// issued before sessions existed
+func GetSessionID(c *gin.Context) uuid.UUID {
+	sessionID, _ := c.Get("session_id")
+	id, _ := sessionID.(uuid.UUID)
+	return id
+}
+
 // GetUserID gets user ID from context
 func GetUserID(c *gin.Context) (uuid.UUID, bool) {
 	userID, exists := c.Get("user_id")
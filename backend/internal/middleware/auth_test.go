@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/airmass/backend/internal/services"
+	"github.com/airmass/backend/pkg/jwt"
+	"github.com/google/uuid"
+)
+
+func TestAuthAccessCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		check    error
+		wantCode int
+		wantErr  string
+	}{
+		{"token may be used", nil, http.StatusOK, ""},
+		{"revoked token", jwt.ErrTokenRevoked, http.StatusUnauthorized, "TOKEN_REVOKED"},
+		{"check could not run", services.ErrAccessCheckFailed, http.StatusServiceUnavailable, "ACCESS_CHECK_FAILED"},
+		{"unexpected check error", errors.New("boom"), http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwtService := jwt.NewService("test-secret", 1)
+			jwtService.SetAccessCheck(func(*jwt.Claims) error { return tt.check })
+			token, _, err := jwtService.GenerateToken(uuid.New(), "a@b.c", "a", "", uuid.New())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := serve(t, req, Auth(jwtService))
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if code := errorCode(t, w); code != tt.wantErr {
+				t.Errorf("code = %q, want %q", code, tt.wantErr)
+			}
+		})
+	}
+}
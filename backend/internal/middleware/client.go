@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"strings"
+
+	"github.com/airmass/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceIDHeader is the optional header the apps send to identify the device
+const DeviceIDHeader = "X-Device-ID"
+
+// GetClientInfo returns the client's IP, user agent and device id. The IP comes from
+// the forwarding headers only when the request came through a trusted proxy.
+func GetClientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: truncate(c.Request.UserAgent(), 512),
+		DeviceID:  truncate(strings.TrimSpace(c.GetHeader(DeviceIDHeader)), 255),
+	}
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
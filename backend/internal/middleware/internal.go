@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/pkg/jwt"
+	"github.com/gin-gonic/gin"
+)
+
+// JobTokenHeader carries the internal job token
+const JobTokenHeader = "X-Job-Token"
+
+// InternalAuth guards internal endpoints (jobs, test helpers). It lets through callers
+// sending the internal job token, and otherwise requires a super admin's JWT. An empty
+// jobToken turns token access off.
+func InternalAuth(jwtService *jwt.Service, jobToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token := c.GetHeader(JobTokenHeader); jobToken != "" && token != "" {
+			if subtle.ConstantTimeCompare([]byte(token), []byte(jobToken)) != 1 {
+				c.AbortWithStatusJSON(401, gin.H{"error": "Invalid job token"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !authenticate(c, jwtService, false) {
+			return
+		}
+		if GetRole(c) != models.AdminRoleSuperAdmin {
+			c.AbortWithStatusJSON(403, gin.H{"error": "Super admin access required", "code": "ADMIN_REQUIRED"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader carries the request id in both directions
+const RequestIDHeader = "X-Request-ID"
+
+// RequestID gives every request an id, reusing the caller's X-Request-ID when it sent
+// a sensible one, and returns it in the response headers
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" || len(id) > 100 {
+			id = uuid.NewString()
+		}
+		c.Set("request_id", id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID gets the request id from context
+func GetRequestID(c *gin.Context) string {
+	id, _ := c.Get("request_id")
+	s, _ := id.(string)
+	return s
+}
@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"github.com/airmass/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole only lets through admins whose role is one of roles. Super admins pass
+// every check. Must run after Auth, which takes the role from the token.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	allowed := map[string]bool{models.AdminRoleSuperAdmin: true}
+	for _, role := range roles {
+		allowed[role] = true
+	}
+
+	return func(c *gin.Context) {
+		role := GetRole(c)
+		if role == "" {
+			c.AbortWithStatusJSON(403, gin.H{"error": "Admin access required", "code": "ADMIN_REQUIRED"})
+			return
+		}
+		if !allowed[role] {
+			c.AbortWithStatusJSON(403, gin.H{"error": "Your admin role cannot do this", "code": "INSUFFICIENT_ROLE"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetRole gets the user's admin role from context, empty for regular users
+func GetRole(c *gin.Context) string {
+	role, _ := c.Get("user_role")
+	s, _ := role.(string)
+	return s
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/airmass/backend/internal/models"
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// serve runs one GET request through handlers and returns the recorded response
+func serve(t *testing.T, req *http.Request, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	r := gin.New()
+	r.GET("/", append(handlers, func(c *gin.Context) { c.Status(http.StatusOK) })...)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// errorCode returns the "code" field of a JSON error response
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Code string `json:"code"`
+	}
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("invalid JSON response %q: %v", w.Body.String(), err)
+		}
+	}
+	return body.Code
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     *string // Nil when Auth set no role at all
+		allowed  []string
+		wantCode int
+		wantErr  string
+	}{
+		{"no role in context", nil, []string{models.AdminRoleSupport}, http.StatusForbidden, "ADMIN_REQUIRED"},
+		{"regular user", strPtr(""), []string{models.AdminRoleSupport}, http.StatusForbidden, "ADMIN_REQUIRED"},
+		{"listed role", strPtr(models.AdminRoleSupport), []string{models.AdminRoleModerator, models.AdminRoleSupport}, http.StatusOK, ""},
+		{"unlisted role", strPtr(models.AdminRoleFinance), []string{models.AdminRoleModerator, models.AdminRoleSupport}, http.StatusForbidden, "INSUFFICIENT_ROLE"},
+		{"super admin passes any check", strPtr(models.AdminRoleSuperAdmin), []string{models.AdminRoleFinance}, http.StatusOK, ""},
+		{"super admin only", strPtr(models.AdminRoleSuperAdmin), nil, http.StatusOK, ""},
+		{"other roles refused by super admin only", strPtr(models.AdminRoleModerator), nil, http.StatusForbidden, "INSUFFICIENT_ROLE"},
+		{"unknown role", strPtr("owner"), []string{models.AdminRoleModerator}, http.StatusForbidden, "INSUFFICIENT_ROLE"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setRole := func(c *gin.Context) {
+				if tt.role != nil {
+					c.Set("user_role", *tt.role)
+				}
+			}
+			w := serve(t, httptest.NewRequest(http.MethodGet, "/", nil), setRole, RequireRole(tt.allowed...))
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantCode)
+			}
+			if code := errorCode(t, w); code != tt.wantErr {
+				t.Errorf("code = %q, want %q", code, tt.wantErr)
+			}
+		})
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
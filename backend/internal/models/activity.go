@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Activity log actions
+const (
+	ActivityLogin          = "login"
+	ActivityBidPlaced      = "bid_placed"
+	ActivityAutoBidSet     = "auto_bid_set"
+	ActivityAuctionCreated = "auction_created"
+)
+
+// ClientInfo identifies where a request came from
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+	DeviceID  string
+}
+
+// ActivityLogEntry is a recorded user action with the client it came from
+type ActivityLogEntry struct {
+	ID         uuid.UUID              `json:"id"`
+	UserID     uuid.UUID              `json:"user_id"`
+	Action     string                 `json:"action"`
+	EntityType *string                `json:"entity_type,omitempty"`
+	EntityID   *uuid.UUID             `json:"entity_id,omitempty"`
+	IPAddress  *string                `json:"ip_address,omitempty"`
+	UserAgent  *string                `json:"user_agent,omitempty"`
+	DeviceID   *string                `json:"device_id,omitempty"`
+	Metadata   map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt  time.Time              `json:"created_at"`
+}
+
+// ActivityIPUsage summarises one IP address a user has been seen on
+type ActivityIPUsage struct {
+	IPAddress  string    `json:"ip_address"`
+	Uses       int       `json:"uses"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	OtherUsers int       `json:"other_users"` // Other accounts seen on the same IP
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Admin roles. super_admin can use every admin route; the others are granted routes
+// one by one in the router.
+const (
+	AdminRoleSuperAdmin = "super_admin"
+	AdminRoleModerator  = "moderator"
+	AdminRoleSupport    = "support"
+	AdminRoleFinance    = "finance"
+)
+
+// AdminUser is a user with access to the admin dashboard
+type AdminUser struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	FullName  string    `json:"full_name"`
+	Role      string    `json:"role"`
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddAdminRequest makes a user an admin
+type AddAdminRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Role   string    `json:"role" binding:"omitempty,oneof=super_admin moderator support finance"` // Defaults to support
+}
+
+// UpdateAdminRoleRequest changes an admin's role
+type UpdateAdminRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=super_admin moderator support finance"`
+}
+
+// AdminAuditEntry records a change an admin made
+type AdminAuditEntry struct {
+	ID          uuid.UUID              `json:"id"`
+	ActorID     *uuid.UUID             `json:"actor_id,omitempty"`
+	ActorRole   *string                `json:"actor_role,omitempty"`
+	Action      string                 `json:"action"`
+	EntityType  *string                `json:"entity_type,omitempty"`
+	EntityID    *string                `json:"entity_id,omitempty"`
+	Before      map[string]interface{} `json:"before,omitempty"`
+	After       map[string]interface{} `json:"after,omitempty"`
+	Changes     map[string]interface{} `json:"changes,omitempty"`
+	RequestBody interface{}            `json:"request_body,omitempty"`
+	Method      string                 `json:"method"`
+	Path        string                 `json:"path"`
+	StatusCode  int                    `json:"status_code"`
+	IPAddress   *string                `json:"ip_address,omitempty"`
+	UserAgent   *string                `json:"user_agent,omitempty"`
+	RequestID   *string                `json:"request_id,omitempty"`
+	CreatedAt   time.Time              `json:"created_at"`
+
+	// Joined fields
+	ActorUsername *string `json:"actor_username,omitempty"`
+}
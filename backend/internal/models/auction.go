@@ -10,13 +10,16 @@ import (
 type AuctionStatus string
 
 const (
-	AuctionStatusDraft      AuctionStatus = "draft"
-	AuctionStatusPending    AuctionStatus = "pending"
-	AuctionStatusActive     AuctionStatus = "active"
-	AuctionStatusEndingSoon AuctionStatus = "ending_soon"
-	AuctionStatusEnded      AuctionStatus = "ended"
-	AuctionStatusSold       AuctionStatus = "sold"
-	AuctionStatusCancelled  AuctionStatus = "cancelled"
+	AuctionStatusDraft         AuctionStatus = "draft"
+	AuctionStatusScheduled     AuctionStatus = "scheduled" // Published, waiting for start_time
+	AuctionStatusPending       AuctionStatus = "pending"
+	AuctionStatusActive        AuctionStatus = "active"
+	AuctionStatusEndingSoon    AuctionStatus = "ending_soon"
+	AuctionStatusEnded         AuctionStatus = "ended"
+	AuctionStatusSold          AuctionStatus = "sold"
+	AuctionStatusCancelled     AuctionStatus = "cancelled"
+	AuctionStatusReserveNotMet AuctionStatus = "reserve_not_met" // Highest bid was below the reserve
+	AuctionStatusUnpaid        AuctionStatus = "unpaid"          // Winner did not pay; may go to the next bidder
 )
 
 // Auction represents an auction listing
@@ -27,6 +30,7 @@ type Auction struct {
 	StartingPrice     float64       `json:"starting_price"`
 	CurrentPrice      *float64      `json:"current_price,omitempty"`
 	ReservePrice      *float64      `json:"reserve_price,omitempty"`
+	BuyNowPrice       *float64      `json:"buy_now_price,omitempty"`
 	BidIncrement      float64       `json:"bid_increment"`
 	SellerID          uuid.UUID     `json:"seller_id"`
 	WinnerID          *uuid.UUID    `json:"winner_id,omitempty"`
@@ -39,6 +43,7 @@ type Auction struct {
 	EndTime           *time.Time    `json:"end_time,omitempty"`
 	OriginalEndTime   *time.Time    `json:"original_end_time,omitempty"`
 	AntiSnipeMinutes  int           `json:"anti_snipe_minutes"`
+	DurationHours     *int          `json:"duration_hours,omitempty"`
 	TotalBids         int           `json:"total_bids"`
 	Views             int           `json:"views"`
 	Images            []string      `json:"images"`
@@ -62,6 +67,8 @@ type Auction struct {
 	MinNextBid       float64  `json:"min_next_bid,omitempty"`
 	UserIsHighBidder bool     `json:"user_is_high_bidder,omitempty"`
 	UserHasBid       bool     `json:"user_has_bid,omitempty"`
+	ReserveMet       *bool    `json:"reserve_met,omitempty"` // Only set when the auction has a reserve
+	BuyNowAvailable  bool     `json:"buy_now_available,omitempty"`
 	Tags             []string `json:"tags,omitempty"` // hot, trending, bidding_war, ending_soon
 }
 
@@ -71,6 +78,7 @@ type CreateAuctionRequest struct {
 	Description       string     `json:"description"`
 	StartingPrice     float64    `json:"starting_price" binding:"required,min=0.01"`
 	ReservePrice      *float64   `json:"reserve_price"`
+	BuyNowPrice       *float64   `json:"buy_now_price"`
 	BidIncrement      float64    `json:"bid_increment"`
 	CategoryID        uuid.UUID  `json:"category_id" binding:"required"`
 	SuburbID          *uuid.UUID `json:"suburb_id"`
@@ -80,15 +88,49 @@ type CreateAuctionRequest struct {
 	AllowOffers       bool       `json:"allow_offers"`
 	PickupLocation    *string    `json:"pickup_location"`
 	ShippingAvailable bool       `json:"shipping_available"`
+	StartTime         *time.Time `json:"start_time"`    // Future start schedules the auction
+	SaveAsDraft       bool       `json:"save_as_draft"` // Keep unpublished until POST /auctions/:id/publish
+
+	// Late-bid window that triggers an extension; 0 disables anti-sniping
+	AntiSnipeMinutes *int `json:"anti_snipe_minutes" binding:"omitempty,min=0,max=30"`
 }
 
 // UpdateAuctionRequest represents auction update input
 type UpdateAuctionRequest struct {
-	Title             *string  `json:"title"`
+	Title             *string  `json:"title" binding:"omitempty,min=5,max=200"`
 	Description       *string  `json:"description"`
-	Images            []string `json:"images"`
+	Images            []string `json:"images" binding:"omitempty,max=10"`
 	PickupLocation    *string  `json:"pickup_location"`
 	ShippingAvailable *bool    `json:"shipping_available"`
+
+	// Appended to the description as a dated note; the only edit allowed once bids exist
+	AppendDescription *string `json:"append_description" binding:"omitempty,max=2000"`
+
+	// Only editable while the auction is a draft or scheduled
+	StartingPrice *float64   `json:"starting_price" binding:"omitempty,min=0.01"`
+	ReservePrice  *float64   `json:"reserve_price"`
+	BuyNowPrice   *float64   `json:"buy_now_price"`
+	CategoryID    *uuid.UUID `json:"category_id"`
+	SuburbID      *uuid.UUID `json:"suburb_id"`
+	Condition     *string    `json:"condition" binding:"omitempty,oneof=new like_new used good fair poor"`
+	DurationHours *int       `json:"duration_hours"`
+	StartTime     *time.Time `json:"start_time"`
+	AllowOffers   *bool      `json:"allow_offers"`
+
+	AntiSnipeMinutes *int `json:"anti_snipe_minutes" binding:"omitempty,min=0,max=30"`
+}
+
+// AuctionRevision records one field changed by the seller. Revisions are public so
+// bidders can see what changed after they bid; reserve price values are never stored.
+type AuctionRevision struct {
+	ID        uuid.UUID `json:"id"`
+	AuctionID uuid.UUID `json:"auction_id"`
+	EditorID  uuid.UUID `json:"editor_id"`
+	Field     string    `json:"field"`
+	OldValue  *string   `json:"old_value,omitempty"`
+	NewValue  *string   `json:"new_value,omitempty"`
+	HadBids   bool      `json:"had_bids"` // Edit was made after the first bid
+	CreatedAt time.Time `json:"created_at"`
 }
 
 // AuctionFilters represents query filters for auctions
@@ -115,3 +157,27 @@ type AuctionListResponse struct {
 	Limit      int       `json:"limit"`
 	TotalPages int       `json:"total_pages"`
 }
+
+// SecondChanceOffer is an offer of an unsold item to a bidder: the top bidder after the
+// reserve was not met, or the next bidder after the winner did not pay
+type SecondChanceOffer struct {
+	ID          uuid.UUID  `json:"id"`
+	AuctionID   uuid.UUID  `json:"auction_id"`
+	SellerID    uuid.UUID  `json:"seller_id"`
+	BidderID    uuid.UUID  `json:"bidder_id"`
+	Amount      float64    `json:"amount"`
+	Reason      string     `json:"reason"` // reserve_not_met, non_payment
+	Status      string     `json:"status"` // pending, accepted, declined, expired
+	ExpiresAt   time.Time  `json:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Joined fields
+	Auction *Auction `json:"auction,omitempty"`
+}
+
+// SecondChanceOfferRequest represents second-chance offer input.
+// Amount defaults to the top bid when omitted.
+type SecondChanceOfferRequest struct {
+	Amount *float64 `json:"amount"`
+}
@@ -48,3 +48,32 @@ type BidHistory struct {
 	NextBidAmount float64 `json:"next_bid_amount"` // The ONLY valid next bid
 	NextIncrement float64 `json:"next_increment"`  // The increment for next bid
 }
+
+// BidIncrementTier is one rung of the bid increment ladder.
+// Tiers without a category or town are the global ladder; a category and/or town
+// override replaces the whole ladder for auctions in that scope.
+type BidIncrementTier struct {
+	ID         uuid.UUID  `json:"id"`
+	CategoryID *uuid.UUID `json:"category_id,omitempty"`
+	TownID     *uuid.UUID `json:"town_id,omitempty"`
+	MinPrice   float64    `json:"min_price"`
+	MaxPrice   *float64   `json:"max_price,omitempty"` // nil means no upper limit
+	Increment  float64    `json:"increment"`
+	IsActive   bool       `json:"is_active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+
+	// Joined fields
+	CategoryName *string `json:"category_name,omitempty"`
+	TownName     *string `json:"town_name,omitempty"`
+}
+
+// BidIncrementTierRequest represents bid increment tier create/update input
+type BidIncrementTierRequest struct {
+	CategoryID *uuid.UUID `json:"category_id"`
+	TownID     *uuid.UUID `json:"town_id"`
+	MinPrice   float64    `json:"min_price" binding:"min=0"`
+	MaxPrice   *float64   `json:"max_price"`
+	Increment  float64    `json:"increment" binding:"required,gt=0"`
+	IsActive   *bool      `json:"is_active"`
+}
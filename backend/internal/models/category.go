@@ -28,8 +28,11 @@ type CategorySlot struct {
 	CategoryID           uuid.UUID `json:"category_id"`
 	TownID               uuid.UUID `json:"town_id"`
 	MaxActiveAuctions    int       `json:"max_active_auctions"`
-	AuctionDurationHours int       `json:"auction_duration_hours"`
+	AuctionDurationHours int       `json:"auction_duration_hours"` // Default when the seller doesn't choose
+	MinDurationHours     int       `json:"min_duration_hours"`
+	MaxDurationHours     int       `json:"max_duration_hours"`
 	CreatedAt            time.Time `json:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at"`
 
 	// Computed fields
 	CurrentActive    int  `json:"current_active,omitempty"`
@@ -42,3 +45,13 @@ type CategoryWithSlots struct {
 	Category
 	SlotInfo *CategorySlot `json:"slot_info,omitempty"`
 }
+
+// UpsertCategorySlotRequest sets the slot configuration of a category in a town (Admin)
+type UpsertCategorySlotRequest struct {
+	CategoryID           uuid.UUID `json:"category_id" binding:"required"`
+	TownID               uuid.UUID `json:"town_id" binding:"required"`
+	MaxActiveAuctions    int       `json:"max_active_auctions" binding:"required,min=1"`
+	AuctionDurationHours int       `json:"auction_duration_hours" binding:"required,min=1"`
+	MinDurationHours     int       `json:"min_duration_hours" binding:"required,min=1"`
+	MaxDurationHours     int       `json:"max_duration_hours" binding:"required,min=1"`
+}
@@ -0,0 +1,122 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DisputeStatus represents where a dispute is in its resolution
+type DisputeStatus string
+
+const (
+	DisputeStatusOpen           DisputeStatus = "open"
+	DisputeStatusAwaitingReturn DisputeStatus = "awaiting_return" // Buyer is refunded once the seller has the item back
+	DisputeStatusResolved       DisputeStatus = "resolved"
+	DisputeStatusWithdrawn      DisputeStatus = "withdrawn"
+)
+
+// How an admin settled a dispute
+const (
+	DisputeOutcomeRefund          = "refund"
+	DisputeOutcomePartialRefund   = "partial_refund"
+	DisputeOutcomeReturnAndRefund = "return_and_refund"
+	DisputeOutcomeNoRefund        = "no_refund" // Money released to the seller
+)
+
+// Which side of a dispute was found at fault
+const (
+	DisputeFaultBuyer  = "buyer"
+	DisputeFaultSeller = "seller"
+	DisputeFaultNone   = "none"
+)
+
+// DisputeBuyerReasons and DisputeSellerReasons are the reasons each side can give
+var (
+	DisputeBuyerReasons  = []string{"item_not_received", "not_as_described", "damaged", "counterfeit", "other"}
+	DisputeSellerReasons = []string{"buyer_unresponsive", "collection_refused", "other"}
+)
+
+// Dispute is a buyer's or seller's complaint about an order, settled by an admin
+type Dispute struct {
+	ID                   uuid.UUID     `json:"id"`
+	OrderID              uuid.UUID     `json:"order_id"`
+	AuctionID            uuid.UUID     `json:"auction_id"`
+	BuyerID              uuid.UUID     `json:"buyer_id"`
+	SellerID             uuid.UUID     `json:"seller_id"`
+	OpenedBy             uuid.UUID     `json:"opened_by"`
+	Reason               string        `json:"reason"`
+	Description          string        `json:"description"`
+	Status               DisputeStatus `json:"status"`
+	OrderStatusBefore    OrderStatus   `json:"-"`
+	Outcome              *string       `json:"outcome,omitempty"`
+	AtFault              *string       `json:"at_fault,omitempty"`
+	RefundAmount         *float64      `json:"refund_amount,omitempty"`
+	ResolutionNotes      *string       `json:"resolution_notes,omitempty"`
+	ResolvedBy           *uuid.UUID    `json:"resolved_by,omitempty"`
+	ResolvedAt           *time.Time    `json:"resolved_at,omitempty"`
+	ReturnCourier        *string       `json:"return_courier,omitempty"`
+	ReturnTrackingNumber *string       `json:"return_tracking_number,omitempty"`
+	ReturnSentAt         *time.Time    `json:"return_sent_at,omitempty"`
+	ReturnReceivedAt     *time.Time    `json:"return_received_at,omitempty"`
+	CreatedAt            time.Time     `json:"created_at"`
+	UpdatedAt            time.Time     `json:"updated_at"`
+
+	// Joined fields
+	AuctionTitle *string           `json:"auction_title,omitempty"`
+	OrderAmount  *float64          `json:"order_amount,omitempty"`
+	Messages     []DisputeMessage  `json:"messages,omitempty"`
+	Evidence     []DisputeEvidence `json:"evidence,omitempty"`
+}
+
+// DisputeMessage is one message in a dispute's thread
+type DisputeMessage struct {
+	ID        uuid.UUID `json:"id"`
+	DisputeID uuid.UUID `json:"dispute_id"`
+	SenderID  uuid.UUID `json:"sender_id"`
+	IsAdmin   bool      `json:"is_admin"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Joined fields
+	SenderUsername *string `json:"sender_username,omitempty"`
+}
+
+// DisputeEvidence is a file uploaded to back up a dispute
+type DisputeEvidence struct {
+	ID          uuid.UUID `json:"id"`
+	DisputeID   uuid.UUID `json:"dispute_id"`
+	UploadedBy  uuid.UUID `json:"uploaded_by"`
+	URL         string    `json:"url"`
+	Filename    *string   `json:"filename,omitempty"`
+	ContentType *string   `json:"content_type,omitempty"`
+	SizeBytes   *int64    `json:"size_bytes,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// OpenDisputeRequest opens a dispute on an order, or on the order of a won auction
+type OpenDisputeRequest struct {
+	OrderID     *uuid.UUID `json:"order_id" binding:"required_without=AuctionID"`
+	AuctionID   *uuid.UUID `json:"auction_id"`
+	Reason      string     `json:"reason" binding:"required,max=50"`
+	Description string     `json:"description" binding:"required,min=10,max=5000"`
+}
+
+// DisputeMessageRequest adds a message to a dispute's thread
+type DisputeMessageRequest struct {
+	Body string `json:"body" binding:"required,max=5000"`
+}
+
+// DisputeReturnRequest represents the buyer's return shipping details
+type DisputeReturnRequest struct {
+	Courier        *string `json:"courier" binding:"omitempty,max=50"`
+	TrackingNumber *string `json:"tracking_number" binding:"omitempty,max=100"`
+}
+
+// ResolveDisputeRequest represents an admin's decision on a dispute
+type ResolveDisputeRequest struct {
+	Outcome      string   `json:"outcome" binding:"required,oneof=refund partial_refund return_and_refund no_refund"`
+	AtFault      string   `json:"at_fault" binding:"required,oneof=buyer seller none"`
+	RefundAmount *float64 `json:"refund_amount" binding:"omitempty,gt=0"` // Required for partial_refund
+	Notes        *string  `json:"notes" binding:"omitempty,max=5000"`
+}
@@ -118,8 +118,13 @@ type PromotedAuction struct {
 	Impressions     int        `json:"impressions"`
 	Clicks          int        `json:"clicks"`
 	BoostMultiplier float64    `json:"boost_multiplier"`
-	PaymentStatus   string     `json:"payment_status"`
+	PaymentStatus   string     `json:"payment_status"` // pending, paid, failed, refunded
+	PaymentID       *uuid.UUID `json:"payment_id,omitempty"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"` // Set when the worker switched it off
 	CreatedAt       time.Time  `json:"created_at"`
+
+	// Joined fields
+	AuctionTitle *string `json:"auction_title,omitempty"`
 }
 
 // PromotionPricing represents available promotion options
@@ -143,10 +148,28 @@ type CreatePromotionRequest struct {
 // PromotionResponse after creating promotion
 type PromotionResponse struct {
 	Promotion *PromotedAuction `json:"promotion"`
+	Payment   *Payment         `json:"payment,omitempty"` // Confirm it to activate the promotion
 	Message   string           `json:"message"`
 	ExpiresAt time.Time        `json:"expires_at"`
 }
 
+// PromotionDailyStat is one day of a promotion's impressions and clicks
+type PromotionDailyStat struct {
+	Day         string `json:"day"` // YYYY-MM-DD
+	Impressions int    `json:"impressions"`
+	Clicks      int    `json:"clicks"`
+}
+
+// PromotionReport shows a seller how a promotion performed
+type PromotionReport struct {
+	Promotion      *PromotedAuction     `json:"promotion"`
+	ClickThrough   float64              `json:"click_through_rate"` // clicks / impressions, 0-1
+	CostPerClick   *float64             `json:"cost_per_click,omitempty"`
+	BidsDuring     int                  `json:"bids_during_promotion"`
+	WatchersDuring int                  `json:"watchers_during_promotion"`
+	Daily          []PromotionDailyStat `json:"daily"`
+}
+
 // =============================================================================
 // SLOT PURCHASE MODELS
 // =============================================================================
@@ -161,9 +184,19 @@ type SlotPurchase struct {
 	AmountPaid           float64    `json:"amount_paid"`
 	OriginalWaitPosition int        `json:"original_wait_position"`
 	PurchaseType         string     `json:"purchase_type"` // skip_queue, extra_slot
-	Status               string     `json:"status"`        // pending, completed, refunded
+	Status               string     `json:"status"`        // pending, completed, failed, refunded
+	PaymentProvider      *string    `json:"payment_provider,omitempty"`
+	PaymentIntentID      *string    `json:"payment_intent_id,omitempty"`
 	CreatedAt            time.Time  `json:"created_at"`
 	ProcessedAt          *time.Time `json:"processed_at,omitempty"`
+	RefundedAt           *time.Time `json:"refunded_at,omitempty"`
+	RefundReason         *string    `json:"refund_reason,omitempty"`
+}
+
+// PurchaseSlotRequest buys a queue skip or an extra slot in the seller's home town
+type PurchaseSlotRequest struct {
+	CategoryID   uuid.UUID `json:"category_id" binding:"required"`
+	PurchaseType string    `json:"purchase_type" binding:"required,oneof=skip_queue extra_slot"`
 }
 
 // SlotPricing for category queues
@@ -200,6 +233,7 @@ type UserReputation struct {
 	CompletionRate         float64   `json:"completion_rate"` // % without disputes
 	TotalTransactions      int       `json:"total_transactions"`
 	SuccessfulTransactions int       `json:"successful_transactions"`
+	DisputedTransactions   int       `json:"disputed_transactions"` // Disputes this user was found at fault in
 	MemberSince            time.Time `json:"member_since"`
 	Badges                 []string  `json:"badges"`
 }
@@ -292,7 +326,20 @@ type FraudSignal struct {
 	ReviewedBy  *uuid.UUID `json:"reviewed_by,omitempty"`
 	ReviewedAt  *time.Time `json:"reviewed_at,omitempty"`
 	AutoFlagged bool       `json:"auto_flagged"`
-	CreatedAt   time.Time  `json:"created_at"`
+	// confirmed or dismissed once reviewed
+	ReviewAction *string   `json:"review_action,omitempty"`
+	ReviewNotes  *string   `json:"review_notes,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	// Joined fields
+	Username *string `json:"username,omitempty"`
+}
+
+// ReviewFraudSignalRequest represents an admin's verdict on fraud signals
+type ReviewFraudSignalRequest struct {
+	Action    string  `json:"action" binding:"required,oneof=confirmed dismissed"`
+	Notes     *string `json:"notes" binding:"omitempty,max=2000"`
+	ClearFlag bool    `json:"clear_flag"` // Only for a user's signals: lift users.is_flagged
 }
 
 // UserBehaviorMetrics for fraud detection
@@ -303,8 +350,15 @@ type UserBehaviorMetrics struct {
 	SameIPBiddersCount  int       `json:"same_ip_bidders_count"`
 	RapidBidCount       int       `json:"rapid_bid_count"`
 	SelfBidAttempts     int       `json:"self_bid_attempts"`
+	UnpaidStrikeCount   int       `json:"unpaid_strike_count"`
 	ShillBidProbability float64   `json:"shill_bid_probability"`
 	AccountAgeDays      int       `json:"account_age_days"`
 	RiskLevel           string    `json:"risk_level"` // low, medium, high, critical
 	LastCalculatedAt    time.Time `json:"last_calculated_at"`
+
+	// Joined fields
+	FraudScore *float64 `json:"fraud_score,omitempty"`
+	IsFlagged  *bool    `json:"is_flagged,omitempty"`
+	FlagReason *string  `json:"flag_reason,omitempty"`
+	Username   *string  `json:"username,omitempty"`
 }
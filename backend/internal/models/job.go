@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job run triggers
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// Job run statuses
+const (
+	JobRunRunning   = "running"
+	JobRunSucceeded = "succeeded"
+	JobRunFailed    = "failed"
+)
+
+// JobRun is one run of a background job
+type JobRun struct {
+	ID          uuid.UUID              `json:"id"`
+	JobName     string                 `json:"job_name"`
+	Trigger     string                 `json:"trigger"`
+	TriggeredBy *uuid.UUID             `json:"triggered_by,omitempty"`
+	Status      string                 `json:"status"`
+	Result      map[string]interface{} `json:"result,omitempty"`
+	Error       *string                `json:"error,omitempty"`
+	StartedAt   time.Time              `json:"started_at"`
+	FinishedAt  *time.Time             `json:"finished_at,omitempty"`
+	DurationMs  *int64                 `json:"duration_ms,omitempty"`
+}
+
+// JobInfo describes a background job and its latest run
+type JobInfo struct {
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	IntervalHours int     `json:"interval_hours"`
+	LastRun       *JobRun `json:"last_run,omitempty"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OfferStatus represents where an offer is in the negotiation
+type OfferStatus string
+
+const (
+	OfferStatusPending   OfferStatus = "pending"   // Waiting for the seller
+	OfferStatusCountered OfferStatus = "countered" // Waiting for the buyer
+	OfferStatusAccepted  OfferStatus = "accepted"
+	OfferStatusDeclined  OfferStatus = "declined"
+	OfferStatusExpired   OfferStatus = "expired"
+)
+
+// Offer represents a buyer's offer on an auction that allows offers
+type Offer struct {
+	ID            uuid.UUID   `json:"id"`
+	AuctionID     uuid.UUID   `json:"auction_id"`
+	BuyerID       uuid.UUID   `json:"buyer_id"`
+	SellerID      uuid.UUID   `json:"seller_id"`
+	Amount        float64     `json:"amount"`
+	CounterAmount *float64    `json:"counter_amount,omitempty"`
+	Message       *string     `json:"message,omitempty"`
+	Status        OfferStatus `json:"status"`
+	ExpiresAt     time.Time   `json:"expires_at"`
+	RespondedAt   *time.Time  `json:"responded_at,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+
+	// Joined fields
+	Buyer   *User    `json:"buyer,omitempty"`
+	Auction *Auction `json:"auction,omitempty"`
+}
+
+// IsOpen reports whether the offer still awaits a response
+func (o *Offer) IsOpen() bool {
+	return o.Status == OfferStatusPending || o.Status == OfferStatusCountered
+}
+
+// AgreedAmount is the price the auction sells for if the offer is accepted now
+func (o *Offer) AgreedAmount() float64 {
+	if o.Status == OfferStatusCountered && o.CounterAmount != nil {
+		return *o.CounterAmount
+	}
+	return o.Amount
+}
+
+// MakeOfferRequest represents offer submission input
+type MakeOfferRequest struct {
+	Amount  float64 `json:"amount" binding:"required,gt=0"`
+	Message *string `json:"message"`
+}
+
+// CounterOfferRequest represents a seller's counter-offer input
+type CounterOfferRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
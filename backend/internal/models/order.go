@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderStatus represents where a sale is between payment and release
+type OrderStatus string
+
+const (
+	OrderStatusAwaitingPayment OrderStatus = "awaiting_payment"
+	OrderStatusPaid            OrderStatus = "paid"       // Money held, waiting for the seller
+	OrderStatusDispatched      OrderStatus = "dispatched" // Sent by courier
+	OrderStatusCollected       OrderStatus = "collected"  // Handed over in person
+	OrderStatusCompleted       OrderStatus = "completed"  // Money released to the seller
+	OrderStatusDisputed        OrderStatus = "disputed"   // Money held until an admin resolves the dispute
+	OrderStatusCancelled       OrderStatus = "cancelled"
+	OrderStatusRefunded        OrderStatus = "refunded"
+)
+
+// How an order's auction was sold
+const (
+	OrderSourceAuction      = "auction"
+	OrderSourceBuyNow       = "buy_now"
+	OrderSourceOffer        = "offer"
+	OrderSourceSecondChance = "second_chance"
+)
+
+// Order is the checkout for a sold auction. The buyer's payment is held until they
+// confirm receipt or the release window runs out.
+type Order struct {
+	ID                    uuid.UUID   `json:"id"`
+	AuctionID             uuid.UUID   `json:"auction_id"`
+	BuyerID               uuid.UUID   `json:"buyer_id"`
+	SellerID              uuid.UUID   `json:"seller_id"`
+	Amount                float64     `json:"amount"`
+	Source                string      `json:"source"`
+	Status                OrderStatus `json:"status"`
+	PaymentID             *uuid.UUID  `json:"payment_id,omitempty"`
+	PaymentDueAt          time.Time   `json:"payment_due_at"`
+	PaymentReminderSentAt *time.Time  `json:"payment_reminder_sent_at,omitempty"`
+	PaidAt                *time.Time  `json:"paid_at,omitempty"`
+	DispatchedAt          *time.Time  `json:"dispatched_at,omitempty"`
+	Courier               *string     `json:"courier,omitempty"`
+	TrackingNumber        *string     `json:"tracking_number,omitempty"`
+	CollectedAt           *time.Time  `json:"collected_at,omitempty"`
+	ReceivedAt            *time.Time  `json:"received_at,omitempty"`
+	ReleaseDueAt          *time.Time  `json:"release_due_at,omitempty"`
+	ReleasedAt            *time.Time  `json:"released_at,omitempty"`
+	CancelledAt           *time.Time  `json:"cancelled_at,omitempty"`
+	CancelReason          *string     `json:"cancel_reason,omitempty"`
+	CreatedAt             time.Time   `json:"created_at"`
+	UpdatedAt             time.Time   `json:"updated_at"`
+
+	// Joined fields
+	Auction *Auction `json:"auction,omitempty"`
+}
+
+// DispatchOrderRequest represents the seller's shipping details
+type DispatchOrderRequest struct {
+	Courier        *string `json:"courier" binding:"omitempty,max=50"`
+	TrackingNumber *string `json:"tracking_number" binding:"omitempty,max=100"`
+}
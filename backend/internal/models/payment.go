@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentStatus represents the state of a payment in the ledger
+type PaymentStatus string
+
+const (
+	PaymentStatusPending           PaymentStatus = "pending"
+	PaymentStatusSucceeded         PaymentStatus = "succeeded"
+	PaymentStatusFailed            PaymentStatus = "failed"
+	PaymentStatusRefunded          PaymentStatus = "refunded"
+	PaymentStatusPartiallyRefunded PaymentStatus = "partially_refunded"
+)
+
+// What a payment is for
+const (
+	PaymentPurposePromotion    = "promotion"
+	PaymentPurposeSlotPurchase = "slot_purchase"
+	PaymentPurposeOrder        = "order"
+)
+
+// Payment is a ledger entry for money collected through the payment provider
+type Payment struct {
+	ID               uuid.UUID     `json:"id"`
+	UserID           *uuid.UUID    `json:"user_id,omitempty"`
+	Purpose          string        `json:"purpose"`
+	ReferenceID      *uuid.UUID    `json:"reference_id,omitempty"`
+	Provider         string        `json:"provider"`
+	ProviderIntentID string        `json:"provider_intent_id"`
+	Amount           float64       `json:"amount"`
+	Currency         string        `json:"currency"`
+	Status           PaymentStatus `json:"status"`
+	RefundedAmount   float64       `json:"refunded_amount"`
+	Description      *string       `json:"description,omitempty"`
+	FailureReason    *string       `json:"failure_reason,omitempty"`
+	ConfirmedAt      *time.Time    `json:"confirmed_at,omitempty"`
+	RefundedAt       *time.Time    `json:"refunded_at,omitempty"`
+	CreatedAt        time.Time     `json:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
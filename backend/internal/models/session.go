@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session revoke reasons
+const (
+	SessionRevokedLogout          = "logout"
+	SessionRevokedRemotely        = "signed_out_remotely"
+	SessionRevokedTokenReuse      = "token_reuse"
+	SessionRevokedAccountDisabled = "account_disabled"
+	SessionRevokedPasswordReset   = "password_reset"
+	SessionRevokedRoleChanged     = "admin_role_changed"
+)
+
+// UserSession is a device the user is signed in on
+type UserSession struct {
+	ID         uuid.UUID `json:"id"`
+	UserID     uuid.UUID `json:"user_id"`
+	DeviceName *string   `json:"device_name,omitempty"`
+	DeviceID   *string   `json:"device_id,omitempty"`
+	IPAddress  *string   `json:"ip_address,omitempty"`
+	UserAgent  *string   `json:"user_agent,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // The session making the request
+}
+
+// RefreshTokenRequest exchanges a refresh token for a new token pair
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UnpaidItemStrike records a seller's report that a winning buyer never paid
+type UnpaidItemStrike struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	AuctionID  uuid.UUID  `json:"auction_id"`
+	OrderID    *uuid.UUID `json:"order_id,omitempty"`
+	ReportedBy uuid.UUID  `json:"reported_by"`
+	Notes      *string    `json:"notes,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+
+	// Joined fields
+	AuctionTitle *string `json:"auction_title,omitempty"`
+}
+
+// BlockedBidder is a user a seller has barred from their auctions
+type BlockedBidder struct {
+	BidderID  uuid.UUID `json:"bidder_id"`
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Joined fields
+	Bidder *User `json:"bidder,omitempty"`
+}
+
+// ReportUnpaidItemRequest represents a seller's unpaid item report
+type ReportUnpaidItemRequest struct {
+	Notes *string `json:"notes" binding:"omitempty,max=1000"`
+}
+
+// BlockBidderRequest represents a seller blocking a bidder
+type BlockBidderRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Reason *string   `json:"reason" binding:"omitempty,max=500"`
+}
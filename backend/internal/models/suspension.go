@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Suspension appeal statuses
+const (
+	AppealPending  = "pending"
+	AppealApproved = "approved"
+	AppealRejected = "rejected"
+)
+
+// UserSuspension is a period during which a user may not use the app
+type UserSuspension struct {
+	ID          uuid.UUID         `json:"id"`
+	UserID      uuid.UUID         `json:"user_id"`
+	Reason      string            `json:"reason"`
+	SuspendedBy *uuid.UUID        `json:"suspended_by,omitempty"`
+	StartsAt    time.Time         `json:"starts_at"`
+	EndsAt      time.Time         `json:"ends_at"`
+	LiftedAt    *time.Time        `json:"lifted_at,omitempty"`
+	LiftedBy    *uuid.UUID        `json:"lifted_by,omitempty"`
+	Appeal      *SuspensionAppeal `json:"appeal,omitempty"`
+}
+
+// SuspensionAppeal is a suspended user's request to have their suspension lifted
+type SuspensionAppeal struct {
+	ID            uuid.UUID  `json:"id"`
+	SuspensionID  uuid.UUID  `json:"suspension_id"`
+	UserID        uuid.UUID  `json:"user_id"`
+	Message       string     `json:"message"`
+	Status        string     `json:"status"`
+	AdminResponse *string    `json:"admin_response,omitempty"`
+	ReviewedBy    *uuid.UUID `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+
+	// Joined fields
+	Username         *string    `json:"username,omitempty"`
+	SuspensionReason *string    `json:"suspension_reason,omitempty"`
+	SuspensionEndsAt *time.Time `json:"suspension_ends_at,omitempty"`
+}
+
+// SuspendUserRequest suspends a user until a time or for a number of hours
+type SuspendUserRequest struct {
+	Reason        string     `json:"reason" binding:"required,min=3,max=1000"`
+	Until         *time.Time `json:"until"`
+	DurationHours int        `json:"duration_hours" binding:"min=0"`
+}
+
+// CreateAppealRequest appeals the current suspension
+type CreateAppealRequest struct {
+	Message string `json:"message" binding:"required,min=10,max=2000"`
+}
+
+// ReviewAppealRequest approves (lifting the suspension) or rejects an appeal
+type ReviewAppealRequest struct {
+	Decision string `json:"decision" binding:"required,oneof=approved rejected"`
+	Response string `json:"response" binding:"max=2000"`
+}
@@ -34,6 +34,13 @@ type User struct {
 	// Push notifications
 	FcmToken *string `json:"-"` // FCM token for push notifications (not exposed in API)
 
+	// Admin dashboard access: super_admin, moderator, support or finance
+	AdminRole *string `json:"admin_role,omitempty"`
+
+	// Current suspension, if any
+	SuspendedUntil   *time.Time `json:"suspended_until,omitempty"`
+	SuspensionReason *string    `json:"suspension_reason,omitempty"`
+
 	// Reputation fields
 	Rating            float64   `json:"rating"`
 	RatingCount       int       `json:"rating_count"`
@@ -69,19 +76,24 @@ type RegisterRequest struct {
 	HomeTownID   uuid.UUID  `json:"home_town_id" binding:"required"`
 	HomeSuburbID *uuid.UUID `json:"home_suburb_id"`
 	Phone        *string    `json:"phone"`
+	DeviceName   string     `json:"device_name" binding:"max=100"` // Shown in the session list
 }
 
 // LoginRequest represents login input
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Email      string `json:"email" binding:"required"`
+	Password   string `json:"password" binding:"required"`
+	DeviceName string `json:"device_name" binding:"max=100"` // Shown in the session list
 }
 
 // AuthResponse represents authentication response
 type AuthResponse struct {
-	Token     string `json:"token"`
-	ExpiresAt int64  `json:"expires_at"`
-	User      *User  `json:"user"`
+	Token            string    `json:"token"`
+	ExpiresAt        int64     `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt int64     `json:"refresh_expires_at"`
+	SessionID        uuid.UUID `json:"session_id"`
+	User             *User     `json:"user"`
 }
 
 // UpdateProfileRequest represents profile update input
@@ -103,6 +115,7 @@ type GoogleAuthRequest struct {
 	IDToken      string     `json:"id_token" binding:"required"`
 	HomeTownID   *uuid.UUID `json:"home_town_id"`   // Optional for first-time sign-up
 	HomeSuburbID *uuid.UUID `json:"home_suburb_id"` // Optional for first-time sign-up
+	DeviceName   string     `json:"device_name" binding:"max=100"`
 }
 
 // PhoneAuthRequest represents a phone authentication request
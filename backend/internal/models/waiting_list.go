@@ -15,6 +15,7 @@ const (
 	WaitingStatusSlotAvailable WaitingListStatus = "slot_available"
 	WaitingStatusExpired       WaitingListStatus = "expired"
 	WaitingStatusCancelled     WaitingListStatus = "cancelled"
+	WaitingStatusClaimed       WaitingListStatus = "claimed" // Slot used; AuctionID is the auction that went live
 )
 
 // WaitingListEntry represents a waiting list entry
@@ -29,7 +30,8 @@ type WaitingListEntry struct {
 	AuctionDescription    *string           `json:"auction_description,omitempty"`
 	ExpectedStartingPrice *float64          `json:"expected_starting_price,omitempty"`
 	NotifiedAt            *time.Time        `json:"notified_at,omitempty"`
-	ExpiresAt             *time.Time        `json:"expires_at,omitempty"`
+	ExpiresAt             *time.Time        `json:"expires_at,omitempty"` // Claim deadline while slot_available
+	AuctionID             *uuid.UUID        `json:"auction_id,omitempty"`
 	CreatedAt             time.Time         `json:"created_at"`
 
 	// Joined fields
@@ -0,0 +1,225 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FakeProvider is a provider for development and tests that keeps intents in memory,
+// optionally mirrored to a JSON file so they survive restarts. Every confirmation
+// succeeds except amounts ending in .13 cents, which are declined so failure paths
+// can be tried. Webhooks are signed with HMAC-SHA256 like a real provider's.
+type FakeProvider struct {
+	currency      string
+	webhookSecret string
+	statePath     string
+
+	mu      sync.Mutex
+	intents map[string]*Intent
+}
+
+// NewFakeProvider creates a fake provider charging in the given currency by default.
+// statePath may be empty to keep everything in memory.
+func NewFakeProvider(currency, webhookSecret, statePath string) (*FakeProvider, error) {
+	p := &FakeProvider{
+		currency:      currency,
+		webhookSecret: webhookSecret,
+		statePath:     statePath,
+		intents:       make(map[string]*Intent),
+	}
+	if statePath == "" {
+		return p, nil
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fake payment state: %w", err)
+	}
+	if err := json.Unmarshal(data, &p.intents); err != nil {
+		return nil, fmt.Errorf("failed to parse fake payment state: %w", err)
+	}
+	return p, nil
+}
+
+// Name implements Provider
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// CreateIntent implements Provider
+func (p *FakeProvider) CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+
+	currency := req.Currency
+	if currency == "" {
+		currency = p.currency
+	}
+
+	intent := &Intent{
+		ID:        "fake_pi_" + uuid.New().String(),
+		Amount:    req.Amount,
+		Currency:  currency,
+		Status:    IntentRequiresConfirmation,
+		Metadata:  req.Metadata,
+		CreatedAt: time.Now(),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.intents[intent.ID] = intent
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+
+	copied := *intent
+	return &copied, nil
+}
+
+// Confirm implements Provider
+func (p *FakeProvider) Confirm(ctx context.Context, intentID string) (*Intent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+
+	if intent.Status == IntentRequiresConfirmation {
+		if int(math.Round(intent.Amount*100))%100 == 13 {
+			intent.Status = IntentFailed
+		} else {
+			intent.Status = IntentSucceeded
+		}
+		if err := p.save(); err != nil {
+			return nil, err
+		}
+	}
+
+	copied := *intent
+	return &copied, nil
+}
+
+// Refund implements Provider
+func (p *FakeProvider) Refund(ctx context.Context, intentID string, amount float64) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrIntentNotFound
+	}
+	if intent.Status != IntentSucceeded {
+		return nil, fmt.Errorf("%w: intent %s is %s", ErrInvalidRefund, intentID, intent.Status)
+	}
+	if amount <= 0 || intent.RefundedAmount+amount > intent.Amount+0.001 {
+		return nil, fmt.Errorf("%w: %.2f exceeds the refundable amount", ErrInvalidRefund, amount)
+	}
+
+	intent.RefundedAmount += amount
+	if intent.RefundedAmount >= intent.Amount-0.001 {
+		intent.Status = IntentRefunded
+	}
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+
+	return &Refund{
+		ID:        "fake_re_" + uuid.New().String(),
+		IntentID:  intentID,
+		Amount:    amount,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// VerifyWebhook implements Provider
+func (p *FakeProvider) VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error) {
+	if !hmac.Equal([]byte(signature), []byte(p.sign(payload))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event WebhookEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook: %w", err)
+	}
+	return &event, nil
+}
+
+// Webhook builds the signed webhook the provider would send for an intent's current
+// state, for development endpoints and tests that stand in for the real delivery
+func (p *FakeProvider) Webhook(intentID string) (payload []byte, signature string, err error) {
+	p.mu.Lock()
+	intent, ok := p.intents[intentID]
+	var copied Intent
+	if ok {
+		copied = *intent
+	}
+	p.mu.Unlock()
+	if !ok {
+		return nil, "", ErrIntentNotFound
+	}
+
+	var eventType string
+	switch copied.Status {
+	case IntentSucceeded:
+		eventType = EventPaymentSucceeded
+	case IntentFailed:
+		eventType = EventPaymentFailed
+	case IntentRefunded:
+		eventType = EventPaymentRefunded
+	default:
+		return nil, "", fmt.Errorf("intent %s is not confirmed yet", intentID)
+	}
+
+	payload, err = json.Marshal(WebhookEvent{
+		ID:        "fake_evt_" + uuid.New().String(),
+		Type:      eventType,
+		Intent:    copied,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode webhook: %w", err)
+	}
+	return payload, p.sign(payload), nil
+}
+
+func (p *FakeProvider) sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// save writes the intents to the state file. Callers hold p.mu.
+func (p *FakeProvider) save() error {
+	if p.statePath == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(p.intents, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fake payment state: %w", err)
+	}
+	tmp := p.statePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write fake payment state: %w", err)
+	}
+	if err := os.Rename(tmp, p.statePath); err != nil {
+		return fmt.Errorf("failed to write fake payment state: %w", err)
+	}
+	return nil
+}
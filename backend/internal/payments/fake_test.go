@@ -0,0 +1,141 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// confirmedIntent creates and confirms an intent on p
+func confirmedIntent(t *testing.T, p *FakeProvider, amount float64) *Intent {
+	t.Helper()
+	intent, err := Pay(context.Background(), p, IntentRequest{Amount: amount})
+	if err != nil {
+		t.Fatalf("Pay(%.2f): %v", amount, err)
+	}
+	return intent
+}
+
+func TestFakeProviderVerifyWebhook(t *testing.T) {
+	p, err := NewFakeProvider("ZAR", "whsec_test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	intent := confirmedIntent(t, p, 120)
+	payload, signature, err := p.Webhook(intent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := NewFakeProvider("ZAR", "whsec_other", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherSignature, err := other.Webhook(confirmedIntent(t, other, 120).ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := []byte(strings.Replace(string(payload), `"amount":120`, `"amount":1`, 1))
+	if string(tampered) == string(payload) {
+		t.Fatal("test payload was not tampered with")
+	}
+
+	tests := []struct {
+		name      string
+		payload   []byte
+		signature string
+	}{
+		{"empty signature", payload, ""},
+		{"tampered payload", tampered, signature},
+		{"signed with another secret", payload, otherSignature},
+		{"signature in upper case", payload, strings.ToUpper(signature)},
+		{"truncated signature", payload, signature[:len(signature)-2]},
+		{"signature with whitespace", payload, signature + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.VerifyWebhook(tt.payload, tt.signature); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("VerifyWebhook() error = %v, want %v", err, ErrInvalidSignature)
+			}
+		})
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		event, err := p.VerifyWebhook(payload, signature)
+		if err != nil {
+			t.Fatalf("VerifyWebhook() error = %v", err)
+		}
+		if event.Type != EventPaymentSucceeded || event.Intent.ID != intent.ID || event.Intent.Amount != 120 {
+			t.Errorf("VerifyWebhook() = %+v, want a payment.succeeded event for %s", event, intent.ID)
+		}
+	})
+
+	t.Run("signed payload that is not an event", func(t *testing.T) {
+		garbage := []byte("not json")
+		_, err := p.VerifyWebhook(garbage, p.sign(garbage))
+		if err == nil || errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("VerifyWebhook() error = %v, want a decode error", err)
+		}
+	})
+}
+
+func TestFakeProviderWebhookEvents(t *testing.T) {
+	p, err := NewFakeProvider("ZAR", "whsec_test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	declined, err := Pay(ctx, p, IntentRequest{Amount: 10.13})
+	if !errors.Is(err, ErrPaymentDeclined) {
+		t.Fatalf("Pay(10.13) error = %v, want %v", err, ErrPaymentDeclined)
+	}
+
+	partial := confirmedIntent(t, p, 100)
+	if _, err := p.Refund(ctx, partial.ID, 40); err != nil {
+		t.Fatal(err)
+	}
+	full := confirmedIntent(t, p, 100)
+	if _, err := p.Refund(ctx, full.ID, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		intentID string
+		typ      string
+		refunded float64
+	}{
+		{"declined payment", declined.ID, EventPaymentFailed, 0},
+		{"partial refund leaves the payment succeeded", partial.ID, EventPaymentSucceeded, 40},
+		{"full refund", full.ID, EventPaymentRefunded, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, signature, err := p.Webhook(tt.intentID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			event, err := p.VerifyWebhook(payload, signature)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if event.Type != tt.typ || event.Intent.RefundedAmount != tt.refunded {
+				t.Errorf("event = %s refunded %.2f, want %s refunded %.2f", event.Type, event.Intent.RefundedAmount, tt.typ, tt.refunded)
+			}
+		})
+	}
+
+	pending, err := p.CreateIntent(ctx, IntentRequest{Amount: 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := p.Webhook(pending.ID); err == nil {
+		t.Error("Webhook() for an unconfirmed intent succeeded")
+	}
+	if _, err := p.Refund(ctx, partial.ID, 61); !errors.Is(err, ErrInvalidRefund) {
+		t.Errorf("Refund() past the captured amount error = %v, want %v", err, ErrInvalidRefund)
+	}
+}
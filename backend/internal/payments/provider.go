@@ -0,0 +1,125 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/config"
+)
+
+// Intent statuses
+const (
+	IntentRequiresConfirmation = "requires_confirmation"
+	IntentSucceeded            = "succeeded"
+	IntentFailed               = "failed"
+	IntentRefunded             = "refunded"
+)
+
+// Webhook event types
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventPaymentRefunded  = "payment.refunded"
+)
+
+var (
+	// ErrIntentNotFound is returned for intent IDs the provider doesn't know
+	ErrIntentNotFound = errors.New("payment intent not found")
+	// ErrPaymentDeclined is returned when the provider refuses a payment
+	ErrPaymentDeclined = errors.New("payment declined")
+	// ErrInvalidRefund is returned when a refund doesn't fit what was captured
+	ErrInvalidRefund = errors.New("invalid refund")
+	// ErrInvalidSignature is returned for webhooks that were not sent by the provider
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// IntentRequest describes a payment to collect
+type IntentRequest struct {
+	Amount      float64
+	Currency    string // Empty for the provider's configured currency
+	Description string
+	Metadata    map[string]string // Echoed back on the intent, e.g. the purchase it pays for
+}
+
+// Intent is a payment as the provider sees it
+type Intent struct {
+	ID             string            `json:"id"`
+	Amount         float64           `json:"amount"`
+	Currency       string            `json:"currency"`
+	Status         string            `json:"status"`
+	RefundedAmount float64           `json:"refunded_amount"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// Refund records money returned against an intent
+type Refund struct {
+	ID        string
+	IntentID  string
+	Amount    float64
+	CreatedAt time.Time
+}
+
+// WebhookEvent is a verified notification from the provider about an intent
+type WebhookEvent struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	Intent    Intent    `json:"intent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Provider collects and refunds payments. Implementations must be safe for
+// concurrent use.
+type Provider interface {
+	// Name identifies the provider in stored payment records
+	Name() string
+	// CreateIntent starts a payment that still has to be confirmed
+	CreateIntent(ctx context.Context, req IntentRequest) (*Intent, error)
+	// Confirm captures the payment of an intent
+	Confirm(ctx context.Context, intentID string) (*Intent, error)
+	// Refund returns all or part of a captured payment
+	Refund(ctx context.Context, intentID string, amount float64) (*Refund, error)
+	// VerifyWebhook checks a webhook's signature and decodes its event
+	VerifyWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+// NewProvider returns the provider selected by PAYMENT_PROVIDER. In release
+// mode it refuses the fake provider and the default webhook secret, since
+// either would let anyone mark payments as paid.
+func NewProvider(cfg *config.Config) (Provider, error) {
+	if cfg.IsRelease() {
+		if cfg.PaymentProvider == "" || cfg.PaymentProvider == "fake" {
+			return nil, errors.New("the fake payment provider cannot be used in release mode, set PAYMENT_PROVIDER")
+		}
+		if cfg.PaymentWebhookSecret == "" || cfg.PaymentWebhookSecret == config.DefaultSecret {
+			return nil, errors.New("PAYMENT_WEBHOOK_SECRET must be set in release mode")
+		}
+	}
+
+	switch cfg.PaymentProvider {
+	case "", "fake":
+		log.Println("⚠️ Using fake payment provider, no real money is collected")
+		return NewFakeProvider(cfg.PaymentCurrency, cfg.PaymentWebhookSecret, cfg.PaymentFakeStateFile)
+	default:
+		return nil, fmt.Errorf("unknown payment provider %q", cfg.PaymentProvider)
+	}
+}
+
+// Pay creates and immediately confirms an intent, for purchases that are paid in one step
+func Pay(ctx context.Context, p Provider, req IntentRequest) (*Intent, error) {
+	intent, err := p.CreateIntent(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+	confirmed, err := p.Confirm(ctx, intent.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to confirm payment %s: %w", intent.ID, err)
+	}
+	if confirmed.Status != IntentSucceeded {
+		return confirmed, ErrPaymentDeclined
+	}
+	return confirmed, nil
+}
@@ -0,0 +1,42 @@
+package payments
+
+import (
+	"testing"
+
+	"github.com/airmass/backend/internal/config"
+)
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		ginMode  string
+		provider string
+		secret   string
+		wantErr  bool
+	}{
+		{"fake provider in debug mode", "debug", "fake", config.DefaultSecret, false},
+		{"default provider in debug mode", "debug", "", "whsec_test", false},
+		{"fake provider in release mode", "release", "fake", "whsec_live", true},
+		{"default provider in release mode", "release", "", "whsec_live", true},
+		{"default secret in release mode", "release", "stripe", config.DefaultSecret, true},
+		{"empty secret in release mode", "release", "stripe", "", true},
+		{"unknown provider", "debug", "stripe", "whsec_test", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				GinMode:              tt.ginMode,
+				PaymentProvider:      tt.provider,
+				PaymentCurrency:      "ZAR",
+				PaymentWebhookSecret: tt.secret,
+			}
+			p, err := NewProvider(cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewProvider() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && p.Name() != "fake" {
+				t.Errorf("NewProvider() = %s, want fake", p.Name())
+			}
+		})
+	}
+}
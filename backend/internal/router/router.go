@@ -1,12 +1,17 @@
 package router
 
 import (
+	"log"
+
 	"github.com/airmass/backend/internal/config"
 	"github.com/airmass/backend/internal/database"
 	"github.com/airmass/backend/internal/email"
 	"github.com/airmass/backend/internal/fcm"
 	"github.com/airmass/backend/internal/handlers"
 	"github.com/airmass/backend/internal/middleware"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/payments"
+	"github.com/airmass/backend/internal/services"
 	"github.com/airmass/backend/internal/websocket"
 	"github.com/airmass/backend/pkg/jwt"
 	"github.com/airmass/backend/pkg/storage"
@@ -14,10 +19,18 @@ import (
 )
 
 // SetupRouter configures all routes
-func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, cfg *config.Config) *gin.Engine {
+func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, cfg *config.Config, paymentProvider payments.Provider) *gin.Engine {
 	r := gin.Default()
 
+	// Only take the client IP from X-Forwarded-For when the request came through our
+	// own proxies
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Printf("Invalid TRUSTED_PROXIES, trusting no proxies: %v", err)
+		r.SetTrustedProxies(nil)
+	}
+
 	// Middleware
+	r.Use(middleware.RequestID())
 	r.Use(middleware.CORS())
 
 	// Services
@@ -25,12 +38,45 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 	fcmService, _ := fcm.NewFCMService(cfg) // FCM is optional, continues without it
 	storageService := storage.NewSupabaseStorage(cfg.SupabaseURL, cfg.SupabaseServiceKey, cfg.SupabaseBucket)
 
+	bidIncrementService := services.NewBidIncrementService(db)
+	biddingService := services.NewBiddingService(db, hub, fcmService, bidIncrementService)
+	notificationService := services.NewNotificationService(db, hub, fcmService)
+	slotService := services.NewSlotService(db)
+	paymentService := services.NewPaymentService(db, paymentProvider, notificationService)
+	slotPurchaseService := services.NewSlotPurchaseService(db, paymentService, notificationService)
+	orderService := services.NewOrderService(db, paymentService, notificationService)
+	strikeService := services.NewStrikeService(db, notificationService)
+	disputeService := services.NewDisputeService(db, paymentService, notificationService)
+	fraudService := services.NewFraudService(db)
+	activityService := services.NewActivityService(db)
+	auditService := services.NewAuditService(db)
+	jobService := services.NewJobService(db)
+	sessionService := services.NewSessionService(db, cfg.RefreshTokenDays)
+
+	suspensionService := services.NewSuspensionService(db, hub, sessionService)
+
+	// Tokens of signed-out sessions and banned or suspended users are refused
+	// everywhere the token is checked
+	jwtService.SetAccessCheck(sessionService.CheckAccess)
+
 	// Handlers
-	authHandler := handlers.NewAuthHandler(db, jwtService, emailService, fcmService)
+	authHandler := handlers.NewAuthHandler(db, jwtService, emailService, fcmService, strikeService, activityService, sessionService, suspensionService)
 	townHandler := handlers.NewTownHandler(db)
-	categoryHandler := handlers.NewCategoryHandler(db)
-	auctionHandler := handlers.NewAuctionHandler(db, hub, fcmService)
-	featuresHandler := handlers.NewFeaturesHandler(db, hub)
+	categoryHandler := handlers.NewCategoryHandler(db, slotService)
+	auctionHandler := handlers.NewAuctionHandler(db, hub, fcmService, biddingService, bidIncrementService, slotService, orderService, strikeService, fraudService, activityService, notificationService)
+	featuresHandler := handlers.NewFeaturesHandler(db, hub, biddingService, bidIncrementService, paymentService, strikeService, fraudService, activityService)
+	bidIncrementHandler := handlers.NewBidIncrementHandler(db, bidIncrementService)
+	secondChanceHandler := handlers.NewSecondChanceHandler(db, hub, orderService, notificationService)
+	offerHandler := handlers.NewOfferHandler(db, hub, orderService, strikeService, notificationService)
+	waitingListHandler := handlers.NewWaitingListHandler(db, slotService)
+	slotPurchaseHandler := handlers.NewSlotPurchaseHandler(db, slotService, slotPurchaseService)
+	paymentHandler := handlers.NewPaymentHandler(db, paymentService)
+	orderHandler := handlers.NewOrderHandler(db, orderService, notificationService)
+	strikeHandler := handlers.NewStrikeHandler(db, strikeService)
+	disputeHandler := handlers.NewDisputeHandler(db, disputeService, storageService)
+	fraudHandler := handlers.NewFraudHandler(db, fraudService)
+	suspensionHandler := handlers.NewSuspensionHandler(db, suspensionService)
+	auditHandler := handlers.NewAuditHandler(db, auditService)
 	notificationHandler := handlers.NewNotificationHandler(db, hub)
 	chatHandler := handlers.NewChatHandler(db, hub, fcmService)
 	shopChatHandler := handlers.NewShopChatHandler(db, hub, fcmService)
@@ -48,8 +94,11 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 
 	// Initialize Analytics & Jobs Handlers
 	analyticsHandler := handlers.NewAnalyticsHandler(db)
-	jobHandler := handlers.NewJobHandler(db)
-	adminHandler := handlers.NewAdminHandler(db)
+	jobHandler := handlers.NewJobHandler(db, jobService)
+	adminHandler := handlers.NewAdminHandler(db, sessionService)
+
+	// Test endpoints (and the fake payment confirmation) are never served in release mode
+	testEndpoints := cfg.EnableTestEndpoints && !cfg.IsRelease()
 
 	// API routes
 	api := r.Group("/api")
@@ -75,7 +124,8 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 			// auth.POST("/phone/signin", authHandler.PhoneSignIn)
 			// auth.POST("/phone/register", authHandler.PhoneRegister)
 
-			auth.POST("/refresh-token", middleware.Auth(jwtService), authHandler.RefreshToken)
+			auth.POST("/refresh-token", authHandler.RefreshToken)
+			auth.POST("/logout", middleware.AuthAllowSuspended(jwtService), authHandler.Logout)
 			auth.POST("/forgot-password", authHandler.ForgotPassword)
 			auth.POST("/reset-password", authHandler.ResetPassword)
 			auth.POST("/send-verification", middleware.Auth(jwtService), authHandler.SendVerificationEmail)
@@ -86,10 +136,17 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 		users := api.Group("/users")
 		{
 			users.GET("", middleware.Auth(jwtService), authHandler.GetUsers) // Add this line
-			users.GET("/me", middleware.Auth(jwtService), authHandler.GetMe)
+			users.GET("/me", middleware.AuthAllowSuspended(jwtService), authHandler.GetMe)
+			users.GET("/me/suspension", middleware.AuthAllowSuspended(jwtService), suspensionHandler.GetMySuspension)
+			users.POST("/me/suspension/appeal", middleware.AuthAllowSuspended(jwtService), suspensionHandler.AppealSuspension)
 			users.PUT("/me", middleware.Auth(jwtService), authHandler.UpdateProfile)
 			users.PUT("/me/town", middleware.Auth(jwtService), authHandler.UpdateTown)
 
+			// Signed-in devices
+			users.GET("/me/sessions", middleware.Auth(jwtService), authHandler.GetSessions)
+			users.DELETE("/me/sessions", middleware.Auth(jwtService), authHandler.RevokeOtherSessions)
+			users.DELETE("/me/sessions/:id", middleware.Auth(jwtService), authHandler.RevokeSession)
+
 			// User ratings & reputation
 			users.GET("/:userId/reputation", featuresHandler.GetUserReputation)
 			users.GET("/:userId/ratings", featuresHandler.GetUserRatings)
@@ -117,6 +174,11 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 			users.GET("/me/badges", middleware.Auth(jwtService), badgeHandler.GetMyBadges)
 			users.POST("/me/verification", middleware.Auth(jwtService), badgeHandler.SubmitVerification)
 			users.GET("/me/verification-status", middleware.Auth(jwtService), badgeHandler.GetMyVerificationStatus)
+
+			// Bidders blocked from my auctions
+			users.GET("/me/blocked-bidders", middleware.Auth(jwtService), strikeHandler.GetBlockedBidders)
+			users.POST("/me/blocked-bidders", middleware.Auth(jwtService), strikeHandler.BlockBidder)
+			users.DELETE("/me/blocked-bidders/:userId", middleware.Auth(jwtService), strikeHandler.UnblockBidder)
 		}
 
 		// Notifications
@@ -173,6 +235,8 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 			categories.GET("", categoryHandler.GetCategories)
 			categories.GET("/:id", categoryHandler.GetCategory)
 			categories.GET("/:id/slots/:townId", categoryHandler.GetCategorySlots)
+			categories.GET("/:id/waiting-list", middleware.Auth(jwtService), waitingListHandler.GetCategoryWaitingList)
+			categories.GET("/:id/slot-pricing", middleware.Auth(jwtService), slotPurchaseHandler.GetSlotPricing)
 		}
 
 		// Badges
@@ -206,9 +270,9 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 			// Tracking
 			stores.POST("/:id/track", storeHandler.TrackEvent)
 
-			// Admin Management
-			stores.POST("/:id/verify", middleware.Auth(jwtService), storeHandler.VerifyStore)
-			stores.DELETE("/:id", middleware.Auth(jwtService), storeHandler.DeleteStore)
+			// Admin Management (kept for the dashboard; same roles as /admin/stores)
+			stores.POST("/:id/verify", middleware.Auth(jwtService), middleware.RequireRole(models.AdminRoleModerator), middleware.Audit(auditService, "store.verify", services.AuditStore), storeHandler.VerifyStore)
+			stores.DELETE("/:id", middleware.Auth(jwtService), middleware.RequireRole(models.AdminRoleModerator), middleware.Audit(auditService, "store.delete", services.AuditStore), storeHandler.DeleteStore)
 		}
 
 		// Products
@@ -233,11 +297,15 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 			auctions.GET("/national", middleware.OptionalAuth(jwtService), auctionHandler.GetNationalAuctions)
 			auctions.GET("/:id", middleware.OptionalAuth(jwtService), auctionHandler.GetAuction)
 			auctions.POST("", middleware.Auth(jwtService), auctionHandler.CreateAuction)
+			auctions.PUT("/:id", middleware.Auth(jwtService), auctionHandler.UpdateAuction)
+			auctions.POST("/:id/publish", middleware.Auth(jwtService), auctionHandler.PublishAuction)
 			auctions.DELETE("/:id", middleware.Auth(jwtService), auctionHandler.CancelAuction)
 
 			// Bidding
 			auctions.GET("/:id/bids", auctionHandler.GetBidHistory)
+			auctions.GET("/:id/revisions", middleware.OptionalAuth(jwtService), auctionHandler.GetAuctionRevisions)
 			auctions.POST("/:id/bids", middleware.Auth(jwtService), auctionHandler.PlaceBid)
+			auctions.POST("/:id/buy-now", middleware.Auth(jwtService), auctionHandler.BuyNow)
 
 			// Chat (NEW)
 			auctions.POST("/:id/chat", middleware.Auth(jwtService), chatHandler.StartChat)
@@ -248,6 +316,89 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 
 			// Promotions
 			auctions.POST("/:id/promote", middleware.Auth(jwtService), featuresHandler.PromoteAuction)
+
+			// Offers
+			auctions.POST("/:id/offers", middleware.Auth(jwtService), offerHandler.MakeOffer)
+			auctions.GET("/:id/offers", middleware.Auth(jwtService), offerHandler.GetAuctionOffers)
+
+			// Second-chance offer (reserve not met)
+			auctions.POST("/:id/second-chance", middleware.Auth(jwtService), secondChanceHandler.CreateSecondChanceOffer)
+			auctions.POST("/:id/unpaid-report", middleware.Auth(jwtService), strikeHandler.ReportUnpaidItem)
+		}
+
+		// Offers management
+		offers := api.Group("/offers")
+		offers.Use(middleware.Auth(jwtService))
+		{
+			offers.GET("", offerHandler.GetMyOffers)
+			offers.POST("/:id/accept", offerHandler.AcceptOffer)
+			offers.POST("/:id/decline", offerHandler.DeclineOffer)
+			offers.POST("/:id/counter", offerHandler.CounterOffer)
+		}
+
+		// Category waiting list
+		waitingList := api.Group("/waiting-list")
+		waitingList.Use(middleware.Auth(jwtService))
+		{
+			waitingList.GET("", waitingListHandler.GetMyWaitingList)
+			waitingList.POST("", waitingListHandler.JoinWaitingList)
+			waitingList.DELETE("/:id", waitingListHandler.LeaveWaitingList)
+		}
+
+		// Payments (webhook is authenticated by the provider's signature)
+		api.POST("/payments/webhook", paymentHandler.Webhook)
+		paymentRoutes := api.Group("/payments")
+		paymentRoutes.Use(middleware.Auth(jwtService))
+		{
+			paymentRoutes.GET("", paymentHandler.GetMyPayments)
+			// Confirming by hand stands in for the provider's checkout, so it
+			// only exists where the test endpoints do
+			if testEndpoints {
+				paymentRoutes.POST("/:id/confirm", paymentHandler.ConfirmFakePayment)
+			}
+		}
+
+		// Orders for sold auctions (buyer pays, seller fulfils, payment held until receipt)
+		orders := api.Group("/orders")
+		orders.Use(middleware.Auth(jwtService))
+		{
+			orders.GET("", orderHandler.GetMyOrders)
+			orders.GET("/:id", orderHandler.GetOrder)
+			orders.POST("/:id/pay", orderHandler.PayOrder)
+			orders.POST("/:id/dispatch", orderHandler.DispatchOrder)
+			orders.POST("/:id/collected", orderHandler.MarkOrderCollected)
+			orders.POST("/:id/received", orderHandler.ConfirmOrderReceived)
+		}
+
+		// DISPUTE ENDPOINTS
+		disputes := api.Group("/disputes")
+		disputes.Use(middleware.Auth(jwtService))
+		{
+			disputes.POST("", disputeHandler.OpenDispute)
+			disputes.GET("", disputeHandler.GetMyDisputes)
+			disputes.GET("/:id", disputeHandler.GetDispute)
+			disputes.POST("/:id/messages", disputeHandler.AddDisputeMessage)
+			disputes.POST("/:id/evidence", disputeHandler.UploadDisputeEvidence)
+			disputes.POST("/:id/withdraw", disputeHandler.WithdrawDispute)
+			disputes.POST("/:id/return-sent", disputeHandler.MarkReturnSent)
+			disputes.POST("/:id/return-received", disputeHandler.MarkReturnReceived)
+		}
+
+		// Queue skips and extra slots
+		slotPurchases := api.Group("/slot-purchases")
+		slotPurchases.Use(middleware.Auth(jwtService))
+		{
+			slotPurchases.GET("", slotPurchaseHandler.GetMySlotPurchases)
+			slotPurchases.POST("", slotPurchaseHandler.PurchaseSlot)
+		}
+
+		// Second-chance offers (top bidder)
+		secondChance := api.Group("/second-chance-offers")
+		secondChance.Use(middleware.Auth(jwtService))
+		{
+			secondChance.GET("", secondChanceHandler.GetMySecondChanceOffers)
+			secondChance.POST("/:id/accept", secondChanceHandler.AcceptSecondChanceOffer)
+			secondChance.POST("/:id/decline", secondChanceHandler.DeclineSecondChanceOffer)
 		}
 
 		// Saved Searches & Alerts
@@ -271,6 +422,8 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 		{
 			promotions.GET("/pricing", featuresHandler.GetPromotionPricing)
 			promotions.POST("/:id", middleware.Auth(jwtService), featuresHandler.PromoteAuction)
+			promotions.GET("/mine", middleware.Auth(jwtService), featuresHandler.GetMyPromotions)
+			promotions.GET("/:id/report", middleware.Auth(jwtService), featuresHandler.GetPromotionReport)
 		}
 
 		// ANALYTICS ENDPOINTS
@@ -285,71 +438,134 @@ func SetupRouter(db *database.DB, jwtService *jwt.Service, hub *websocket.Hub, c
 			analytics.GET("/store/:id", middleware.OptionalAuth(jwtService), analyticsHandler.GetStoreAnalytics)
 		}
 
-		// JOBS (Background Tasks)
+		// JOBS (Background Tasks; internal job token or super admin)
 		jobs := api.Group("/jobs")
+		jobs.Use(middleware.InternalAuth(jwtService, cfg.JobToken))
 		{
+			jobs.GET("", jobHandler.ListJobs)
+			jobs.GET("/runs", jobHandler.GetJobRuns)
+			jobs.POST("/:name/run", middleware.Audit(auditService, "job.run", services.AuditJob), jobHandler.RunJob)
 			jobs.POST("/nudge-stale-stores", jobHandler.CheckStaleStores)
 		}
 
-		// TEST ENDPOINTS (REMOVE IN PRODUCTION)
-		testHandler := handlers.NewTestHandler(db, hub, fcmService)
-		api.POST("/test/end-auction/:id", testHandler.EndAuctionTest)
-		api.POST("/test/push-notification/:userId", testHandler.TestPushNotification)
-		api.POST("/test/set-ending-soon/:id", testHandler.SetAuctionEndingSoon)
-		api.POST("/test/update-email", testHandler.UpdateUserEmail)
-		api.POST("/test/restale-store/:slug", testHandler.RestaleStore)
+		// TEST ENDPOINTS (ENABLE_TEST_ENDPOINTS outside release mode only)
+		if testEndpoints {
+			testHandler := handlers.NewTestHandler(db, hub, fcmService)
+			test := api.Group("/test")
+			test.Use(middleware.InternalAuth(jwtService, cfg.JobToken))
+			{
+				test.POST("/end-auction/:id", testHandler.EndAuctionTest)
+				test.POST("/push-notification/:userId", testHandler.TestPushNotification)
+				test.POST("/set-ending-soon/:id", testHandler.SetAuctionEndingSoon)
+				test.POST("/update-email", testHandler.UpdateUserEmail)
+				test.POST("/restale-store/:slug", testHandler.RestaleStore)
+			}
+		} else if cfg.EnableTestEndpoints {
+			log.Println("ENABLE_TEST_ENDPOINTS is ignored in release mode")
+		}
+
+		// ADMIN ENDPOINTS (dashboard users only; each route lists the admin roles
+		// allowed besides super_admin)
+		anyAdmin := middleware.RequireRole(models.AdminRoleModerator, models.AdminRoleSupport, models.AdminRoleFinance)
+		moderator := middleware.RequireRole(models.AdminRoleModerator)
+		supportDesk := middleware.RequireRole(models.AdminRoleModerator, models.AdminRoleSupport)
+		disputeDesk := middleware.RequireRole(models.AdminRoleSupport, models.AdminRoleFinance)
+		finance := middleware.RequireRole(models.AdminRoleFinance)
+		superAdmin := middleware.RequireRole()
+
+		// Every change made through an admin route is written to the audit log
+		audit := func(action string, target services.AuditTarget) gin.HandlerFunc {
+			return middleware.Audit(auditService, action, target)
+		}
 
-		// ADMIN ENDPOINTS (Restricted to dashboard users, though currently using general Auth)
 		admin := api.Group("/admin")
 		admin.Use(middleware.Auth(jwtService))
 		{
-			admin.GET("/stats", adminHandler.GetPlatformStats)
-			admin.GET("/admins", adminHandler.ListAdmins)
-			admin.POST("/admins", adminHandler.AddAdmin)
-			admin.DELETE("/admins/:id", adminHandler.RemoveAdmin)
-			admin.GET("/bids", auctionHandler.GetAllBids)
-			admin.GET("/conversations", chatHandler.GetAllConversations)
-			admin.GET("/conversations/:id/messages", chatHandler.GetConversationMessagesAdmin)
-			admin.GET("/notifications", notificationHandler.GetAllNotifications)
-			admin.POST("/notifications", notificationHandler.SendAdminNotification)
+			admin.GET("/stats", anyAdmin, adminHandler.GetPlatformStats)
+			admin.GET("/admins", superAdmin, adminHandler.ListAdmins)
+			admin.POST("/admins", superAdmin, audit("admin.add", services.AuditUser), adminHandler.AddAdmin)
+			admin.PUT("/admins/:id/role", superAdmin, audit("admin.update_role", services.AuditUser), adminHandler.UpdateAdminRole)
+			admin.DELETE("/admins/:id", superAdmin, audit("admin.remove", services.AuditUser), adminHandler.RemoveAdmin)
+			admin.GET("/bids", anyAdmin, auctionHandler.GetAllBids)
+			admin.GET("/conversations", supportDesk, chatHandler.GetAllConversations)
+			admin.GET("/conversations/:id/messages", supportDesk, chatHandler.GetConversationMessagesAdmin)
+			admin.GET("/notifications", supportDesk, notificationHandler.GetAllNotifications)
+			admin.POST("/notifications", moderator, audit("notification.send", services.AuditNotification), notificationHandler.SendAdminNotification)
 
 			// Auctions
-			admin.GET("/auctions/:id", auctionHandler.GetAdminAuctionDetails)
-			admin.DELETE("/auctions/:id", auctionHandler.AdminCancelAuction)
-			admin.POST("/auctions/:id/approve", auctionHandler.AdminApproveAuction)
-			admin.PUT("/auctions/:id/status", auctionHandler.AdminUpdateAuctionStatus)
+			admin.GET("/auctions/:id", anyAdmin, auctionHandler.GetAdminAuctionDetails)
+			admin.DELETE("/auctions/:id", moderator, audit("auction.cancel", services.AuditAuction), auctionHandler.AdminCancelAuction)
+			admin.POST("/auctions/:id/approve", moderator, audit("auction.approve", services.AuditAuction), auctionHandler.AdminApproveAuction)
+			admin.PUT("/auctions/:id/status", moderator, audit("auction.update_status", services.AuditAuction), auctionHandler.AdminUpdateAuctionStatus)
 
 			// Users
-			admin.GET("/users/search", authHandler.SearchUsers)
-			admin.GET("/users/:id", authHandler.GetAdminUserDetails)
-			admin.PUT("/users/:id/status", authHandler.UpdateUserStatus)
-			admin.PUT("/users/:id/verify", authHandler.VerifyUserByAdmin)
+			admin.GET("/users/search", anyAdmin, authHandler.SearchUsers)
+			admin.GET("/users/:id", anyAdmin, authHandler.GetAdminUserDetails)
+			admin.PUT("/users/:id/status", moderator, audit("user.update_status", services.AuditUser), authHandler.UpdateUserStatus)
+			admin.PUT("/users/:id/verify", moderator, audit("user.verify", services.AuditUser), authHandler.VerifyUserByAdmin)
+			admin.POST("/users/:id/suspend", moderator, audit("user.suspend", services.AuditUser), suspensionHandler.SuspendUser)
+			admin.DELETE("/users/:id/suspension", moderator, audit("user.lift_suspension", services.AuditUser), suspensionHandler.LiftSuspension)
+
+			// Suspension appeals
+			admin.GET("/appeals", moderator, suspensionHandler.GetAppeals)
+			admin.POST("/appeals/:id/review", moderator, audit("appeal.review", services.AuditAppeal), suspensionHandler.ReviewAppeal)
 
 			// Categories
-			admin.POST("/categories", categoryHandler.CreateCategory)
-			admin.PUT("/categories/:id", categoryHandler.UpdateCategory)
-			admin.DELETE("/categories/:id", categoryHandler.DeleteCategory)
+			admin.POST("/categories", moderator, audit("category.create", services.AuditCategory), categoryHandler.CreateCategory)
+			admin.PUT("/categories/:id", moderator, audit("category.update", services.AuditCategory), categoryHandler.UpdateCategory)
+			admin.DELETE("/categories/:id", moderator, audit("category.delete", services.AuditCategory), categoryHandler.DeleteCategory)
+
+			// Category Slots
+			admin.GET("/category-slots", anyAdmin, categoryHandler.ListCategorySlots)
+			admin.PUT("/category-slots", moderator, audit("category_slot.upsert", services.AuditCategorySlot), categoryHandler.UpsertCategorySlot)
+			admin.DELETE("/category-slots/:id", moderator, audit("category_slot.delete", services.AuditCategorySlot), categoryHandler.DeleteCategorySlot)
+
+			// Bid Increments
+			admin.GET("/bid-increments", anyAdmin, bidIncrementHandler.ListBidIncrementTiers)
+			admin.POST("/bid-increments", finance, audit("bid_increment.create", services.AuditBidIncrement), bidIncrementHandler.CreateBidIncrementTier)
+			admin.PUT("/bid-increments/:id", finance, audit("bid_increment.update", services.AuditBidIncrement), bidIncrementHandler.UpdateBidIncrementTier)
+			admin.DELETE("/bid-increments/:id", finance, audit("bid_increment.delete", services.AuditBidIncrement), bidIncrementHandler.DeleteBidIncrementTier)
 
 			// Stores (Admin)
-			admin.GET("/stores/:id", storeHandler.AdminGetStore)
-			admin.PUT("/stores/:id", storeHandler.AdminUpdateStore)
-			admin.GET("/stores/:id/products", storeHandler.AdminGetStoreProducts)
-			admin.POST("/stores/:id/products", productHandler.AdminCreateProduct)
+			admin.GET("/stores/:id", supportDesk, storeHandler.AdminGetStore)
+			admin.PUT("/stores/:id", moderator, audit("store.update", services.AuditStore), storeHandler.AdminUpdateStore)
+			admin.GET("/stores/:id/products", supportDesk, storeHandler.AdminGetStoreProducts)
+			admin.POST("/stores/:id/products", moderator, audit("product.create", services.AuditStore), productHandler.AdminCreateProduct)
+			admin.POST("/stores/:id/verify", moderator, audit("store.verify", services.AuditStore), storeHandler.VerifyStore)
+			admin.DELETE("/stores/:id", moderator, audit("store.delete", services.AuditStore), storeHandler.DeleteStore)
 
 			// Products (Admin)
-			admin.PUT("/products/:id", productHandler.AdminUpdateProduct)
-			admin.DELETE("/products/:id", productHandler.AdminDeleteProduct)
+			admin.PUT("/products/:id", moderator, audit("product.update", services.AuditProduct), productHandler.AdminUpdateProduct)
+			admin.DELETE("/products/:id", moderator, audit("product.delete", services.AuditProduct), productHandler.AdminDeleteProduct)
 
 			// Towns (Admin)
-			admin.POST("/towns", townHandler.CreateTown)
-			admin.PUT("/towns/:id", townHandler.UpdateTown)
-			admin.DELETE("/towns/:id", townHandler.DeleteTown)
-			admin.POST("/towns/:id/suburbs", townHandler.CreateSuburb)
-			admin.DELETE("/towns/:id/suburbs/:suburbId", townHandler.DeleteSuburb)
+			admin.POST("/towns", moderator, audit("town.create", services.AuditTown), townHandler.CreateTown)
+			admin.PUT("/towns/:id", moderator, audit("town.update", services.AuditTown), townHandler.UpdateTown)
+			admin.DELETE("/towns/:id", moderator, audit("town.delete", services.AuditTown), townHandler.DeleteTown)
+			admin.POST("/towns/:id/suburbs", moderator, audit("suburb.create", services.AuditSuburb), townHandler.CreateSuburb)
+			admin.DELETE("/towns/:id/suburbs/:suburbId", moderator, audit("suburb.delete", services.AuditSuburb), townHandler.DeleteSuburb)
 
 			// Settings (Admin)
-			admin.GET("/settings", settingsHandler.GetAllSettings)
-			admin.PUT("/settings/:key", settingsHandler.UpdateSetting)
+			admin.GET("/settings", anyAdmin, settingsHandler.GetAllSettings)
+			admin.PUT("/settings/:key", superAdmin, audit("setting.update", services.AuditSetting), settingsHandler.UpdateSetting)
+
+			// Disputes (Admin)
+			admin.GET("/disputes", disputeDesk, disputeHandler.AdminListDisputes)
+			admin.GET("/disputes/:id", disputeDesk, disputeHandler.AdminGetDispute)
+			admin.POST("/disputes/:id/messages", disputeDesk, audit("dispute.message", services.AuditDispute), disputeHandler.AdminAddDisputeMessage)
+			admin.POST("/disputes/:id/resolve", finance, audit("dispute.resolve", services.AuditDispute), disputeHandler.ResolveDispute)
+
+			// Fraud review (Admin)
+			admin.GET("/fraud/signals", moderator, fraudHandler.GetFraudSignals)
+			admin.POST("/fraud/signals/:id/review", moderator, audit("fraud_signal.review", services.AuditFraudSignal), fraudHandler.ReviewFraudSignal)
+			admin.GET("/fraud/users", moderator, fraudHandler.GetRiskyUsers)
+			admin.GET("/fraud/users/:id", moderator, fraudHandler.GetUserFraudProfile)
+			admin.POST("/fraud/users/:id/review", moderator, audit("fraud_user.review", services.AuditUser), fraudHandler.ReviewUserFraud)
+			admin.POST("/fraud/users/:id/rescan", moderator, audit("fraud_user.rescan", services.AuditUser), fraudHandler.RescanUser)
+
+			// Audit log (Admin)
+			admin.GET("/audit-log", superAdmin, auditHandler.GetAuditLog)
+			admin.GET("/audit-log/export", superAdmin, auditHandler.ExportAuditLog)
 		}
 	}
 
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Default for the activity log setting in app_settings
+const defaultActivityLogRetentionDays = 180
+
+// ActivityService records logins, bids and listings with the client they came from
+type ActivityService struct {
+	db *database.DB
+}
+
+func NewActivityService(db *database.DB) *ActivityService {
+	return &ActivityService{db: db}
+}
+
+// Record appends an entry to the activity log. Failures are logged and otherwise
+// ignored so they never fail the action itself.
+func (s *ActivityService) Record(ctx context.Context, userID uuid.UUID, action, entityType string, entityID *uuid.UUID, client models.ClientInfo, metadata map[string]interface{}) {
+	if _, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO activity_log (user_id, action, entity_type, entity_id, ip_address, user_agent, device_id, metadata)
+		VALUES ($1, $2, NULLIF($3, ''), $4, NULLIF($5, '')::inet, NULLIF($6, ''), NULLIF($7, ''), $8)`,
+		userID, action, entityType, entityID, client.IPAddress, client.UserAgent, client.DeviceID, metadata,
+	); err != nil {
+		log.Printf("Failed to record %s activity for user %s: %v", action, userID, err)
+	}
+}
+
+// Recent returns the user's latest activity, newest first
+func (s *ActivityService) Recent(ctx context.Context, userID uuid.UUID, limit int) ([]models.ActivityLogEntry, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, user_id, action, entity_type, entity_id, host(ip_address), user_agent, device_id, metadata, created_at
+		FROM activity_log
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2`,
+		userID, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch activity: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.ActivityLogEntry{}
+	for rows.Next() {
+		var e models.ActivityLogEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Action, &e.EntityType, &e.EntityID, &e.IPAddress,
+			&e.UserAgent, &e.DeviceID, &e.Metadata, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to read activity: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// IPUsage returns the IPs the user has been seen on, most recent first, with how many
+// other accounts used the same IP
+func (s *ActivityService) IPUsage(ctx context.Context, userID uuid.UUID) ([]models.ActivityIPUsage, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT host(l.ip_address), COUNT(*), MAX(l.created_at),
+			(SELECT COUNT(DISTINCT o.user_id) FROM activity_log o
+			 WHERE o.ip_address = l.ip_address AND o.user_id <> $1)
+		FROM activity_log l
+		WHERE l.user_id = $1 AND l.ip_address IS NOT NULL
+		GROUP BY l.ip_address
+		ORDER BY MAX(l.created_at) DESC
+		LIMIT 50`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch IP usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := []models.ActivityIPUsage{}
+	for rows.Next() {
+		var u models.ActivityIPUsage
+		if err := rows.Scan(&u.IPAddress, &u.Uses, &u.LastSeenAt, &u.OtherUsers); err != nil {
+			return nil, fmt.Errorf("failed to read IP usage: %w", err)
+		}
+		usage = append(usage, u)
+	}
+	return usage, rows.Err()
+}
+
+// Prune deletes entries older than the retention setting (0 keeps everything)
+func (s *ActivityService) Prune(ctx context.Context) (int64, error) {
+	days := intSetting(ctx, s.db, "activity_log_retention_days", defaultActivityLogRetentionDays)
+	if days == 0 {
+		return 0, nil
+	}
+	tag, err := s.db.Pool.Exec(ctx,
+		"DELETE FROM activity_log WHERE created_at < $1", time.Now().AddDate(0, 0, -days),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune activity log: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// maxAuditExport is how many entries one CSV export returns at most
+const maxAuditExport = 10000
+
+// auditHiddenFields are never copied into audit snapshots
+var auditHiddenFields = []string{"password_hash", "fcm_token"}
+
+// AuditTarget is the kind of row an admin route changes and the route param holding
+// its key, so the row can be captured before and after the change
+type AuditTarget struct {
+	EntityType string
+	Param      string
+	table      string
+	keyColumn  string
+	textKey    bool
+}
+
+// Audit targets for the admin routes
+var (
+	AuditAuction      = AuditTarget{EntityType: "auction", Param: "id", table: "auctions", keyColumn: "id"}
+	AuditUser         = AuditTarget{EntityType: "user", Param: "id", table: "users", keyColumn: "id"}
+	AuditStore        = AuditTarget{EntityType: "store", Param: "id", table: "stores", keyColumn: "id"}
+	AuditProduct      = AuditTarget{EntityType: "product", Param: "id", table: "products", keyColumn: "id"}
+	AuditCategory     = AuditTarget{EntityType: "category", Param: "id", table: "categories", keyColumn: "id"}
+	AuditCategorySlot = AuditTarget{EntityType: "category_slot", Param: "id", table: "category_slots", keyColumn: "id"}
+	AuditBidIncrement = AuditTarget{EntityType: "bid_increment", Param: "id", table: "bid_increment_tiers", keyColumn: "id"}
+	AuditTown         = AuditTarget{EntityType: "town", Param: "id", table: "towns", keyColumn: "id"}
+	AuditSuburb       = AuditTarget{EntityType: "suburb", Param: "suburbId", table: "suburbs", keyColumn: "id"}
+	AuditSetting      = AuditTarget{EntityType: "setting", Param: "key", table: "app_settings", keyColumn: "key", textKey: true}
+	AuditDispute      = AuditTarget{EntityType: "dispute", Param: "id", table: "disputes", keyColumn: "id"}
+	AuditFraudSignal  = AuditTarget{EntityType: "fraud_signal", Param: "id", table: "fraud_signals", keyColumn: "id"}
+	AuditAppeal       = AuditTarget{EntityType: "suspension_appeal", Param: "id", table: "suspension_appeals", keyColumn: "id"}
+	AuditNotification = AuditTarget{EntityType: "notification"}
+	AuditJob          = AuditTarget{EntityType: "job", Param: "name"}
+)
+
+// AuditFilter narrows the audit log
+type AuditFilter struct {
+	ActorID    *uuid.UUID
+	Action     string
+	EntityType string
+	EntityID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+// AuditService records and searches the admin audit log
+type AuditService struct {
+	db *database.DB
+}
+
+func NewAuditService(db *database.DB) *AuditService {
+	return &AuditService{db: db}
+}
+
+// Snapshot returns the target row with the given key as JSON fields, or nil when the
+// target has no table or the row does not exist
+func (s *AuditService) Snapshot(ctx context.Context, target AuditTarget, key string) map[string]interface{} {
+	if target.table == "" || key == "" {
+		return nil
+	}
+	var arg interface{} = key
+	if !target.textKey {
+		id, err := uuid.Parse(key)
+		if err != nil {
+			return nil
+		}
+		arg = id
+	}
+
+	var row map[string]interface{}
+	err := s.db.Pool.QueryRow(ctx,
+		fmt.Sprintf("SELECT to_jsonb(t) FROM %s t WHERE t.%s = $1", target.table, target.keyColumn), arg,
+	).Scan(&row)
+	if err != nil {
+		return nil
+	}
+	for _, field := range auditHiddenFields {
+		delete(row, field)
+	}
+	return row
+}
+
+// Diff returns the fields that differ between two snapshots as
+// {"field": {"before": ..., "after": ...}}
+func Diff(before, after map[string]interface{}) map[string]interface{} {
+	changes := map[string]interface{}{}
+	for field, old := range before {
+		if value, ok := after[field]; !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = map[string]interface{}{"before": old, "after": after[field]}
+		}
+	}
+	for field, value := range after {
+		if _, ok := before[field]; !ok {
+			changes[field] = map[string]interface{}{"before": nil, "after": value}
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return changes
+}
+
+// Record appends an entry to the audit log. Changes are worked out from Before and
+// After when they are not set.
+func (s *AuditService) Record(ctx context.Context, e *models.AdminAuditEntry) {
+	if e.Changes == nil && (e.Before != nil || e.After != nil) {
+		e.Changes = Diff(e.Before, e.After)
+	}
+	// nil maps must be stored as NULL rather than JSON null
+	jsonb := func(v map[string]interface{}) interface{} {
+		if v == nil {
+			return nil
+		}
+		return v
+	}
+	if _, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO admin_audit_log (
+			actor_id, actor_role, action, entity_type, entity_id, before_state, after_state, changes,
+			request_body, method, path, status_code, ip_address, user_agent, request_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13::inet, $14, $15)`,
+		e.ActorID, e.ActorRole, e.Action, e.EntityType, e.EntityID,
+		jsonb(e.Before), jsonb(e.After), jsonb(e.Changes),
+		e.RequestBody, e.Method, e.Path, e.StatusCode, e.IPAddress, e.UserAgent, e.RequestID,
+	); err != nil {
+		log.Printf("Failed to record admin audit entry %s: %v", e.Action, err)
+	}
+}
+
+const auditColumns = `
+	l.id, l.actor_id, l.actor_role, l.action, l.entity_type, l.entity_id,
+	l.before_state, l.after_state, l.changes, l.request_body,
+	l.method, l.path, l.status_code, host(l.ip_address), l.user_agent, l.request_id, l.created_at,
+	u.username`
+
+func auditScanArgs(e *models.AdminAuditEntry) []interface{} {
+	return []interface{}{
+		&e.ID, &e.ActorID, &e.ActorRole, &e.Action, &e.EntityType, &e.EntityID,
+		&e.Before, &e.After, &e.Changes, &e.RequestBody,
+		&e.Method, &e.Path, &e.StatusCode, &e.IPAddress, &e.UserAgent, &e.RequestID, &e.CreatedAt,
+		&e.ActorUsername,
+	}
+}
+
+// List returns audit entries matching the filter, newest first, with the total count
+func (s *AuditService) List(ctx context.Context, filter AuditFilter) ([]models.AdminAuditEntry, int, error) {
+	where := []string{"1=1"}
+	args := []interface{}{}
+	if filter.ActorID != nil {
+		args = append(args, *filter.ActorID)
+		where = append(where, fmt.Sprintf("l.actor_id = $%d", len(args)))
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		where = append(where, fmt.Sprintf("l.action = $%d", len(args)))
+	}
+	if filter.EntityType != "" {
+		args = append(args, filter.EntityType)
+		where = append(where, fmt.Sprintf("l.entity_type = $%d", len(args)))
+	}
+	if filter.EntityID != "" {
+		args = append(args, filter.EntityID)
+		where = append(where, fmt.Sprintf("l.entity_id = $%d", len(args)))
+	}
+	if filter.From != nil {
+		args = append(args, *filter.From)
+		where = append(where, fmt.Sprintf("l.created_at >= $%d", len(args)))
+	}
+	if filter.To != nil {
+		args = append(args, *filter.To)
+		where = append(where, fmt.Sprintf("l.created_at < $%d", len(args)))
+	}
+	if filter.Limit <= 0 || filter.Limit > maxAuditExport {
+		filter.Limit = maxAuditExport
+	}
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s, COUNT(*) OVER()
+		FROM admin_audit_log l
+		LEFT JOIN users u ON u.id = l.actor_id
+		WHERE %s
+		ORDER BY l.created_at DESC
+		LIMIT $%d OFFSET $%d`,
+		auditColumns, strings.Join(where, " AND "), len(args)-1, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch audit log: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.AdminAuditEntry{}
+	total := 0
+	for rows.Next() {
+		var e models.AdminAuditEntry
+		if err := rows.Scan(append(auditScanArgs(&e), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to read audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, total, rows.Err()
+}
+
+// auditJSON renders a JSON column for the CSV export
+func auditJSON(v interface{}) string {
+	if v == nil || reflect.ValueOf(v).IsZero() {
+		return ""
+	}
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+// CSVRecords returns the entries matching the filter (up to maxAuditExport) as CSV
+// rows, header first
+func (s *AuditService) CSVRecords(ctx context.Context, filter AuditFilter) ([][]string, error) {
+	filter.Limit, filter.Offset = maxAuditExport, 0
+	entries, _, err := s.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	str := func(p *string) string {
+		if p == nil {
+			return ""
+		}
+		return *p
+	}
+	records := [][]string{{
+		"created_at", "actor_id", "actor_username", "actor_role", "action", "entity_type", "entity_id",
+		"method", "path", "status_code", "ip_address", "user_agent", "request_id", "changes", "request_body",
+	}}
+	for _, e := range entries {
+		actorID := ""
+		if e.ActorID != nil {
+			actorID = e.ActorID.String()
+		}
+		records = append(records, []string{
+			e.CreatedAt.UTC().Format(time.RFC3339), actorID, str(e.ActorUsername), str(e.ActorRole),
+			e.Action, str(e.EntityType), str(e.EntityID),
+			e.Method, e.Path, fmt.Sprint(e.StatusCode), str(e.IPAddress), str(e.UserAgent), str(e.RequestID),
+			auditJSON(e.Changes), auditJSON(e.RequestBody),
+		})
+	}
+	return records, nil
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// bidIncrementCacheTTL bounds how stale the in-memory ladder can get on instances
+// that did not handle the admin change themselves
+const bidIncrementCacheTTL = time.Minute
+
+// fallbackBidIncrement matches get_bid_increment when no tier covers a price
+const fallbackBidIncrement = 1.00
+
+// defaultBidIncrementTiers mirrors the seed rows in 009_tiered_bidding.sql and is only
+// used until the table has been read once (e.g. the database is unreachable at startup)
+// $0 - $4.99 → +$1, $5 - $19.99 → +$2, $20 - $99.99 → +$5, $100 - $499.99 → +$10, $500+ → +$25
+var defaultBidIncrementTiers = []models.BidIncrementTier{
+	{MinPrice: 0, MaxPrice: floatPtr(4.99), Increment: 1, IsActive: true},
+	{MinPrice: 5, MaxPrice: floatPtr(19.99), Increment: 2, IsActive: true},
+	{MinPrice: 20, MaxPrice: floatPtr(99.99), Increment: 5, IsActive: true},
+	{MinPrice: 100, MaxPrice: floatPtr(499.99), Increment: 10, IsActive: true},
+	{MinPrice: 500, Increment: 25, IsActive: true},
+}
+
+// BidIncrementService is the single source of truth for bid increments.
+// The ladder lives in bid_increment_tiers; the SQL get_bid_increment function used by
+// the bid trigger resolves scopes with the same rules.
+type BidIncrementService struct {
+	db *database.DB
+
+	mu       sync.RWMutex
+	tiers    []models.BidIncrementTier // Active tiers, ordered by min_price DESC
+	loadedAt time.Time
+}
+
+func NewBidIncrementService(db *database.DB) *BidIncrementService {
+	return &BidIncrementService{db: db}
+}
+
+// Increment returns the bid increment for a price in the given category and town.
+// Pass uuid.Nil for either to skip that override. The most specific ladder wins:
+// category+town, then category, then town, then the global ladder.
+func (s *BidIncrementService) Increment(price float64, categoryID, townID uuid.UUID) float64 {
+	tiers := s.activeTiers()
+
+	ladder := scopedLadder(tiers, categoryID, townID)
+	for _, t := range ladder {
+		if price >= t.MinPrice && (t.MaxPrice == nil || price <= *t.MaxPrice) {
+			return t.Increment
+		}
+	}
+	return fallbackBidIncrement
+}
+
+// NextBid returns the minimum valid bid after the given price
+func (s *BidIncrementService) NextBid(price float64, categoryID, townID uuid.UUID) float64 {
+	return roundCents(price + s.Increment(price, categoryID, townID))
+}
+
+// AlignToLadder returns the smallest amount at or above the requested one that can be
+// reached from the current price by stepping up the increment ladder. A bid is aligned
+// when AlignToLadder returns the requested amount unchanged.
+func (s *BidIncrementService) AlignToLadder(currentPrice, amount float64, categoryID, townID uuid.UUID) float64 {
+	ladder := scopedLadder(s.activeTiers(), categoryID, townID)
+
+	price := toCents(currentPrice)
+	target := toCents(amount)
+	// Each pass covers one tier, so the loop is bounded by the ladder size
+	for i := 0; i <= len(ladder)+1 && price < target; i++ {
+		step := toCents(s.Increment(fromCents(price), categoryID, townID))
+		if step <= 0 {
+			step = toCents(fallbackBidIncrement)
+		}
+
+		// The increment is constant until the price reaches the next tier
+		limit := target
+		for _, t := range ladder {
+			if boundary := toCents(t.MinPrice); boundary > price && boundary < limit {
+				limit = boundary
+			}
+		}
+
+		steps := (limit - price + step - 1) / step
+		if steps < 1 {
+			steps = 1
+		}
+		price += steps * step
+	}
+	if price < target {
+		// Ladder with gaps: fall back to whole fallback steps from here
+		step := toCents(fallbackBidIncrement)
+		price += (target - price + step - 1) / step * step
+	}
+	return fromCents(price)
+}
+
+// Invalidate drops the cached ladder so the next lookup reads the table again
+func (s *BidIncrementService) Invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+// activeTiers returns the cached tiers, reloading them when the cache has expired.
+// A failed reload keeps serving the previous ladder.
+func (s *BidIncrementService) activeTiers() []models.BidIncrementTier {
+	s.mu.RLock()
+	tiers, fresh := s.tiers, time.Since(s.loadedAt) < bidIncrementCacheTTL
+	s.mu.RUnlock()
+	if fresh {
+		return tiers
+	}
+
+	loaded, err := s.loadTiers(context.Background())
+	if err != nil {
+		log.Printf("Failed to load bid increment tiers: %v", err)
+		if tiers == nil {
+			return defaultBidIncrementTiers
+		}
+		return tiers
+	}
+
+	s.mu.Lock()
+	s.tiers = loaded
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+	return loaded
+}
+
+func (s *BidIncrementService) loadTiers(ctx context.Context) ([]models.BidIncrementTier, error) {
+	if s.db == nil || s.db.Pool == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT id, category_id, town_id, min_price, max_price, increment, is_active, created_at, updated_at
+		FROM bid_increment_tiers
+		WHERE is_active = true
+		ORDER BY min_price DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tiers := []models.BidIncrementTier{}
+	for rows.Next() {
+		var t models.BidIncrementTier
+		if err := rows.Scan(&t.ID, &t.CategoryID, &t.TownID, &t.MinPrice, &t.MaxPrice,
+			&t.Increment, &t.IsActive, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		tiers = append(tiers, t)
+	}
+	return tiers, rows.Err()
+}
+
+// scopedLadder picks the most specific ladder that has at least one tier
+func scopedLadder(tiers []models.BidIncrementTier, categoryID, townID uuid.UUID) []models.BidIncrementTier {
+	scopes := []struct{ category, town uuid.UUID }{
+		{categoryID, townID},
+		{categoryID, uuid.Nil},
+		{uuid.Nil, townID},
+		{uuid.Nil, uuid.Nil},
+	}
+	for _, scope := range scopes {
+		var ladder []models.BidIncrementTier
+		for _, t := range tiers {
+			if sameScope(t.CategoryID, scope.category) && sameScope(t.TownID, scope.town) {
+				ladder = append(ladder, t)
+			}
+		}
+		if len(ladder) > 0 {
+			sort.SliceStable(ladder, func(i, j int) bool { return ladder[i].MinPrice > ladder[j].MinPrice })
+			return ladder
+		}
+	}
+	return nil
+}
+
+func sameScope(tierScope *uuid.UUID, want uuid.UUID) bool {
+	if tierScope == nil {
+		return want == uuid.Nil
+	}
+	return *tierScope == want
+}
+
+// TiersOverlap reports whether two tier price ranges share any price
+func TiersOverlap(aMin float64, aMax *float64, bMin float64, bMax *float64) bool {
+	aBelowB := aMax != nil && *aMax < bMin
+	bBelowA := bMax != nil && *bMax < aMin
+	return !aBelowB && !bBelowA
+}
+
+func toCents(amount float64) int64 {
+	return int64(math.Round(amount * 100))
+}
+
+func fromCents(cents int64) float64 {
+	return float64(cents) / 100
+}
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+func TestAlignToLadder(t *testing.T) {
+	tests := []struct {
+		name         string
+		currentPrice float64
+		amount       float64
+		want         float64
+	}{
+		{"next bid is aligned", 10, 12, 12},
+		{"several steps up is aligned", 10, 16, 16},
+		{"between steps rounds up", 10, 13, 14},
+		{"at or below the current price stays at it", 10, 8, 10},
+		{"steps follow the tier of each price", 18, 25, 25},
+		{"crossing a tier rounds up in the new tier", 18, 21, 25},
+		{"one step may end past a tier boundary", 19, 21, 21},
+		{"ladder runs from an off-step price", 13.37, 15, 15.37},
+		{"top tier", 500, 530, 550},
+	}
+
+	increments := testIncrements()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := increments.AlignToLadder(tt.currentPrice, tt.amount, uuid.Nil, uuid.Nil); got != tt.want {
+				t.Errorf("AlignToLadder(%.2f, %.2f) = %.2f, want %.2f", tt.currentPrice, tt.amount, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScopedLadder(t *testing.T) {
+	category, town, other := uuid.New(), uuid.New(), uuid.New()
+	tiers := append([]models.BidIncrementTier{
+		{MinPrice: 0, Increment: 3, IsActive: true, CategoryID: &category},
+		{MinPrice: 0, Increment: 4, IsActive: true, TownID: &town},
+		{MinPrice: 0, Increment: 7, IsActive: true, CategoryID: &category, TownID: &town},
+	}, defaultBidIncrementTiers...)
+	increments := &BidIncrementService{tiers: tiers, loadedAt: time.Now()}
+
+	tests := []struct {
+		name     string
+		category uuid.UUID
+		town     uuid.UUID
+		want     float64
+	}{
+		{"category and town ladder wins", category, town, 7},
+		{"category ladder before town ladder", category, other, 3},
+		{"town ladder when the category has none", other, town, 4},
+		{"global ladder when neither has one", other, other, 2},
+		{"global ladder without a scope", uuid.Nil, uuid.Nil, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := increments.Increment(10, tt.category, tt.town); got != tt.want {
+				t.Errorf("Increment(10) = %.2f, want %.2f", got, tt.want)
+			}
+		})
+	}
+
+	ladder := scopedLadder(tiers, other, other)
+	if len(ladder) != len(defaultBidIncrementTiers) {
+		t.Fatalf("global ladder has %d tiers, want %d", len(ladder), len(defaultBidIncrementTiers))
+	}
+	for i := 1; i < len(ladder); i++ {
+		if ladder[i-1].MinPrice < ladder[i].MinPrice {
+			t.Errorf("ladder is not ordered by min price descending: %.2f before %.2f", ladder[i-1].MinPrice, ladder[i].MinPrice)
+		}
+	}
+
+	if got := increments.AlignToLadder(10, 14, category, other); got != 16 {
+		t.Errorf("AlignToLadder on the category ladder = %.2f, want 16", got)
+	}
+}
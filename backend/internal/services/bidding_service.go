@@ -0,0 +1,518 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/fcm"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/websocket"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// BiddingService resolves proxy (auto) bids and pushes the resulting state
+type BiddingService struct {
+	db         *database.DB
+	hub        *websocket.Hub
+	fcmService *fcm.FCMService
+	increments *BidIncrementService
+}
+
+func NewBiddingService(db *database.DB, hub *websocket.Hub, fcmService *fcm.FCMService, increments *BidIncrementService) *BiddingService {
+	return &BiddingService{
+		db:         db,
+		hub:        hub,
+		fcmService: fcmService,
+		increments: increments,
+	}
+}
+
+// ProxyBidOutcome describes what the proxy engine did inside a bid transaction
+type ProxyBidOutcome struct {
+	PlacedBids   []models.Bid // Bids written on behalf of auto-bidders, in insert order
+	FinalPrice   float64
+	HighBidderID *uuid.UUID
+	OutbidUsers  []uuid.UUID // Bidders who held the lead during this round but lost it
+	Deactivated  int
+
+	// Increment scope of the auction
+	categoryID uuid.UUID
+	townID     uuid.UUID
+}
+
+// WasOutbid reports whether the user was already notified as outbid by this outcome
+func (o *ProxyBidOutcome) WasOutbid(userID uuid.UUID) bool {
+	for _, id := range o.OutbidUsers {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
+
+// proxyBidder is an active auto-bid competing for the lead
+type proxyBidder struct {
+	autoBidID uuid.UUID
+	userID    uuid.UUID
+	maxAmount float64
+	maxSetAt  time.Time // When the maximum was last set; earlier wins a tie
+}
+
+// UpsertAutoBid creates or re-arms the user's auto-bid with a new maximum. ipAddress
+// is where the user set it from; the proxy bids it places are recorded with it.
+// Changing the maximum or re-arming a stopped auto-bid moves max_set_at, so it only
+// wins ties from then on. Call inside the bid transaction before ResolveAutoBids.
+func (s *BiddingService) UpsertAutoBid(ctx context.Context, tx pgx.Tx, auctionID, userID uuid.UUID, maxAmount float64, ipAddress string) (uuid.UUID, error) {
+	var autoBidID uuid.UUID
+	err := tx.QueryRow(ctx,
+		`INSERT INTO auto_bids (auction_id, user_id, max_amount, is_active, ip_address)
+		 VALUES ($1, $2, $3, true, NULLIF($4, ''))
+		 ON CONFLICT (auction_id, user_id) DO UPDATE SET
+		   max_amount = EXCLUDED.max_amount,
+		   max_set_at = CASE
+		     WHEN auto_bids.is_active AND auto_bids.max_amount = EXCLUDED.max_amount THEN auto_bids.max_set_at
+		     ELSE NOW()
+		   END,
+		   is_active = true,
+		   ip_address = COALESCE(EXCLUDED.ip_address, auto_bids.ip_address),
+		   updated_at = NOW(),
+		   deactivated_at = NULL,
+		   deactivation_reason = NULL
+		 RETURNING id`,
+		auctionID, userID, maxAmount, ipAddress,
+	).Scan(&autoBidID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to set auto-bid: %w", err)
+	}
+	return autoBidID, nil
+}
+
+// ResolveAutoBids runs the eBay-style second-price resolution for every active auto-bid
+// on an auction. It must be called inside the same transaction that holds the
+// auction row lock (SELECT ... FOR UPDATE), after any manual bid has been inserted.
+//
+// Proxy bids are exempt from the increment ladder that client bids must follow
+// (BID_NOT_ALIGNED): the runner-up is shown at exactly the maximum they authorised and
+// the winner pays one increment above it, capped at their own maximum. Aligning either
+// amount would bid past a user's maximum or move the price away from the second-price
+// rule. Client bids are aligned from the current price, so they stay valid afterwards.
+func (s *BiddingService) ResolveAutoBids(ctx context.Context, tx pgx.Tx, auctionID uuid.UUID) (*ProxyBidOutcome, error) {
+	// Current state (the bid trigger keeps current_price in sync with the latest bid)
+	var currentPrice float64
+	var categoryID, townID uuid.UUID
+	if err := tx.QueryRow(ctx,
+		"SELECT COALESCE(current_price, starting_price), category_id, town_id FROM auctions WHERE id = $1",
+		auctionID,
+	).Scan(&currentPrice, &categoryID, &townID); err != nil {
+		return nil, fmt.Errorf("failed to read auction price: %w", err)
+	}
+
+	var highBidderID *uuid.UUID
+	tx.QueryRow(ctx,
+		"SELECT bidder_id FROM bids WHERE auction_id = $1 AND is_winning = true",
+		auctionID,
+	).Scan(&highBidderID)
+
+	outcome := &ProxyBidOutcome{
+		FinalPrice:   currentPrice,
+		HighBidderID: highBidderID,
+		categoryID:   categoryID,
+		townID:       townID,
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id, user_id, max_amount, max_set_at
+		FROM auto_bids
+		WHERE auction_id = $1 AND is_active = true
+		ORDER BY max_amount DESC, max_set_at ASC, created_at ASC
+		FOR UPDATE`,
+		auctionID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load auto-bids: %w", err)
+	}
+	var proxies []proxyBidder
+	for rows.Next() {
+		var p proxyBidder
+		if err := rows.Scan(&p.autoBidID, &p.userID, &p.maxAmount, &p.maxSetAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan auto-bid: %w", err)
+		}
+		proxies = append(proxies, p)
+	}
+	rows.Close()
+
+	if len(proxies) == 0 {
+		return outcome, nil
+	}
+
+	res := resolveProxyBids(currentPrice, highBidderID, proxies, func(price float64) float64 {
+		return s.increments.Increment(price, categoryID, townID)
+	})
+
+	if res.winner != nil {
+		if res.runnerUp != nil {
+			bid, err := s.insertAutoBid(ctx, tx, auctionID, *res.runnerUp, res.runnerUpBid)
+			if err != nil {
+				return nil, err
+			}
+			outcome.PlacedBids = append(outcome.PlacedBids, *bid)
+		}
+
+		bid, err := s.insertAutoBid(ctx, tx, auctionID, *res.winner, res.finalPrice)
+		if err != nil {
+			return nil, err
+		}
+		for i := range outcome.PlacedBids {
+			outcome.PlacedBids[i].IsWinning = false
+		}
+		outcome.PlacedBids = append(outcome.PlacedBids, *bid)
+
+		// The bid trigger moves current_price and is_winning to the latest bid; refuse
+		// to commit a round whose recorded state disagrees with what was resolved
+		var recordedPrice float64
+		var leading bool
+		if err := tx.QueryRow(ctx, `
+			SELECT a.current_price, b.is_winning
+			FROM auctions a JOIN bids b ON b.id = $2
+			WHERE a.id = $1`,
+			auctionID, bid.ID,
+		).Scan(&recordedPrice, &leading); err != nil {
+			return nil, fmt.Errorf("failed to read auction after auto-bids: %w", err)
+		}
+		if toCents(recordedPrice) != toCents(res.finalPrice) || !leading {
+			return nil, fmt.Errorf("auction %s did not record auto-bid %s as leading at %.2f", auctionID, bid.ID, res.finalPrice)
+		}
+
+		winnerID := res.winner.userID
+		outcome.HighBidderID = &winnerID
+		outcome.FinalPrice = res.finalPrice
+		outcome.OutbidUsers = res.outbid
+	}
+
+	for _, d := range res.deactivate {
+		if _, err := tx.Exec(ctx, `
+			UPDATE auto_bids
+			SET is_active = false, deactivated_at = NOW(), deactivation_reason = $1, updated_at = NOW()
+			WHERE id = $2`,
+			d.reason, d.autoBidID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to deactivate auto-bid: %w", err)
+		}
+		outcome.Deactivated++
+	}
+
+	return outcome, nil
+}
+
+// proxyResolution is what resolveProxyBids decided, before anything is written
+type proxyResolution struct {
+	winner      *proxyBidder // Nil when no proxy takes the lead
+	runnerUp    *proxyBidder // Shown at runnerUpBid before the winner's bid, when set
+	runnerUpBid float64
+	finalPrice  float64
+	outbid      []uuid.UUID // Bidders who held the lead during this round but lost it
+	deactivate  []proxyDeactivation
+}
+
+// proxyDeactivation is an auto-bid that can no longer compete
+type proxyDeactivation struct {
+	autoBidID uuid.UUID
+	reason    string
+}
+
+// resolveProxyBids works out a proxy round from the current price and high bidder.
+// proxies must be ordered by max_amount DESC, max_set_at ASC; increment returns the
+// auction's increment at a price.
+func resolveProxyBids(currentPrice float64, highBidderID *uuid.UUID, proxies []proxyBidder, increment func(float64) float64) proxyResolution {
+	var res proxyResolution
+	finalPrice := currentPrice
+	leaderID := highBidderID
+
+	// The high bidder defends with their auto-bid max (or their standing bid if they have none)
+	floor := roundCents(currentPrice + increment(currentPrice))
+	var defender *proxyBidder
+	var challengers []proxyBidder
+	for _, p := range proxies {
+		if highBidderID != nil && p.userID == *highBidderID {
+			pp := p
+			defender = &pp
+			continue
+		}
+		if p.maxAmount >= floor {
+			challengers = append(challengers, p)
+		}
+	}
+
+	if len(challengers) > 0 {
+		// challengers[0] is the strongest; equal maximums are won by whoever set theirs first
+		top := challengers[0]
+		defenderMax := currentPrice
+		if defender != nil && defender.maxAmount > defenderMax {
+			defenderMax = defender.maxAmount
+		}
+		defenderHolds := highBidderID != nil &&
+			(defenderMax > top.maxAmount ||
+				(defender != nil && defenderMax == top.maxAmount && !defender.maxSetAt.After(top.maxSetAt)))
+
+		var winner proxyBidder
+		var runnerUp *proxyBidder
+		var secondPrice float64
+		hasSecond := false
+
+		if defenderHolds {
+			winner = *defender
+			runnerUp = &top
+			secondPrice = top.maxAmount
+			hasSecond = true
+		} else {
+			winner = top
+			if len(challengers) > 1 && (highBidderID == nil || challengers[1].maxAmount >= defenderMax) {
+				r := challengers[1]
+				runnerUp = &r
+				secondPrice = r.maxAmount
+				hasSecond = true
+			} else if highBidderID != nil {
+				secondPrice = defenderMax
+				hasSecond = true
+				if defender != nil && defenderMax > currentPrice {
+					runnerUp = defender
+				}
+			}
+		}
+
+		finalPrice = floor
+		if hasSecond {
+			finalPrice = math.Max(finalPrice, roundCents(secondPrice+increment(secondPrice)))
+		}
+		finalPrice = math.Min(finalPrice, winner.maxAmount)
+
+		// The runner-up's proxy is shown at its maximum so the bid history explains the price
+		if runnerUp != nil && secondPrice > currentPrice {
+			res.runnerUp = runnerUp
+			res.runnerUpBid = secondPrice
+		}
+		res.winner = &winner
+
+		// Everyone who led at some point in this round, except the final winner, was outbid
+		seen := map[uuid.UUID]bool{winner.userID: true}
+		if highBidderID != nil && !seen[*highBidderID] {
+			seen[*highBidderID] = true
+			res.outbid = append(res.outbid, *highBidderID)
+		}
+		if runnerUp != nil && !seen[runnerUp.userID] {
+			seen[runnerUp.userID] = true
+			res.outbid = append(res.outbid, runnerUp.userID)
+		}
+
+		winnerID := winner.userID
+		leaderID = &winnerID
+	}
+	res.finalPrice = finalPrice
+
+	// Deactivate proxies that can no longer compete
+	nextFloor := roundCents(finalPrice + increment(finalPrice))
+	for _, p := range proxies {
+		if p.maxAmount >= nextFloor {
+			continue
+		}
+		reason := "outbid"
+		if leaderID != nil && p.userID == *leaderID {
+			reason = "max_reached"
+		}
+		res.deactivate = append(res.deactivate, proxyDeactivation{autoBidID: p.autoBidID, reason: reason})
+	}
+	return res
+}
+
+// insertAutoBid writes a proxy bid and records it against the auto-bid
+func (s *BiddingService) insertAutoBid(ctx context.Context, tx pgx.Tx, auctionID uuid.UUID, p proxyBidder, amount float64) (*models.Bid, error) {
+	bid := models.Bid{
+		AuctionID:  auctionID,
+		BidderID:   p.userID,
+		Amount:     amount,
+		IsWinning:  true,
+		IsAutoBid:  true,
+		MaxAutoBid: &p.maxAmount,
+	}
+	// clock_timestamp keeps bids written in one transaction in their real order
+	err := tx.QueryRow(ctx, `
+		INSERT INTO bids (auction_id, bidder_id, amount, is_auto_bid, max_auto_bid, ip_address, created_at)
+		VALUES ($1, $2, $3, true, $4, (SELECT ip_address FROM auto_bids WHERE id = $5), clock_timestamp())
+		RETURNING id, created_at`,
+		auctionID, p.userID, amount, p.maxAmount, p.autoBidID,
+	).Scan(&bid.ID, &bid.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to place auto-bid: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE auto_bids SET current_bid_amount = $1, updated_at = NOW() WHERE id = $2",
+		amount, p.autoBidID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to update auto-bid: %w", err)
+	}
+
+	return &bid, nil
+}
+
+// Anti-sniping defaults, used when app_settings has no valid value
+const (
+	defaultAntiSnipeExtensionMinutes    = 5
+	defaultAntiSnipeMaxExtensionMinutes = 60
+)
+
+// AntiSnipeExtension describes an end-time extension made by ApplyAntiSnipe
+type AntiSnipeExtension struct {
+	Extended        bool
+	PreviousEndTime time.Time
+	NewEndTime      time.Time
+	TotalExtension  time.Duration // How far past the original end time the auction now runs
+	CapReached      bool          // No further extensions are possible
+}
+
+// ApplyAntiSnipe extends the auction when a bid lands within its anti_snipe_minutes
+// window: the auction then ends anti_snipe_extension_minutes after the bid, but never more
+// than anti_snipe_max_extension_minutes past the original end time. Call inside the bid
+// transaction that holds the auction row lock, after the bids have been written.
+func (s *BiddingService) ApplyAntiSnipe(ctx context.Context, tx pgx.Tx, auctionID uuid.UUID) (*AntiSnipeExtension, error) {
+	var endTime, originalEndTime *time.Time
+	var windowMinutes int
+	if err := tx.QueryRow(ctx,
+		"SELECT end_time, original_end_time, COALESCE(anti_snipe_minutes, 0) FROM auctions WHERE id = $1",
+		auctionID,
+	).Scan(&endTime, &originalEndTime, &windowMinutes); err != nil {
+		return nil, fmt.Errorf("failed to read auction end time: %w", err)
+	}
+
+	result := &AntiSnipeExtension{}
+	if endTime == nil || windowMinutes <= 0 {
+		return result, nil
+	}
+	result.PreviousEndTime = *endTime
+	result.NewEndTime = *endTime
+
+	original := *endTime
+	if originalEndTime != nil {
+		original = *originalEndTime
+	}
+	result.TotalExtension = endTime.Sub(original)
+
+	now := time.Now()
+	if endTime.Sub(now) >= time.Duration(windowMinutes)*time.Minute {
+		return result, nil
+	}
+
+	extension := intSetting(ctx, s.db, "anti_snipe_extension_minutes", defaultAntiSnipeExtensionMinutes)
+	maxExtension := intSetting(ctx, s.db, "anti_snipe_max_extension_minutes", defaultAntiSnipeMaxExtensionMinutes)
+
+	newEndTime := now.Add(time.Duration(extension) * time.Minute)
+	limit := original.Add(time.Duration(maxExtension) * time.Minute)
+	if !newEndTime.Before(limit) {
+		newEndTime = limit
+		result.CapReached = true
+	}
+	if !newEndTime.After(*endTime) {
+		return result, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE auctions
+		SET end_time = $1, original_end_time = COALESCE(original_end_time, $2), updated_at = NOW()
+		WHERE id = $3`,
+		newEndTime, original, auctionID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to extend auction: %w", err)
+	}
+
+	result.Extended = true
+	result.NewEndTime = newEndTime
+	result.TotalExtension = newEndTime.Sub(original)
+	return result, nil
+}
+
+// BroadcastExtension tells the auction room the end time moved. Call after commit.
+func (s *BiddingService) BroadcastExtension(auctionID uuid.UUID, ext *AntiSnipeExtension) {
+	if ext == nil || !ext.Extended {
+		return
+	}
+	s.hub.BroadcastToAuction(auctionID, websocket.MessageTypeAuctionExtended, map[string]interface{}{
+		"auction_id":              auctionID,
+		"previous_end_time":       ext.PreviousEndTime,
+		"new_end_time":            ext.NewEndTime,
+		"total_extension_minutes": int(ext.TotalExtension.Minutes()),
+		"cap_reached":             ext.CapReached,
+	})
+}
+
+// BroadcastOutcome pushes the auto-bids placed by the engine to the auction room and
+// notifies every bidder who lost the lead. Call after the transaction has committed.
+func (s *BiddingService) BroadcastOutcome(auctionID uuid.UUID, outcome *ProxyBidOutcome) {
+	if outcome == nil || len(outcome.PlacedBids) == 0 {
+		return
+	}
+
+	nextIncrement := s.increments.Increment(outcome.FinalPrice, outcome.categoryID, outcome.townID)
+	for _, bid := range outcome.PlacedBids {
+		bidIncrement := s.increments.Increment(bid.Amount, outcome.categoryID, outcome.townID)
+		s.hub.BroadcastToAuction(auctionID, websocket.MessageTypeBidNew, map[string]interface{}{
+			"bid_id":          bid.ID,
+			"amount":          bid.Amount,
+			"bidder_id":       bid.BidderID,
+			"is_auto_bid":     true,
+			"next_bid_amount": roundCents(bid.Amount + bidIncrement),
+			"next_increment":  bidIncrement,
+		})
+	}
+
+	s.hub.BroadcastToAuction(auctionID, websocket.MessageTypeAuctionUpdate, map[string]interface{}{
+		"action":          "price_update",
+		"auction_id":      auctionID,
+		"current_price":   outcome.FinalPrice,
+		"high_bidder_id":  outcome.HighBidderID,
+		"next_bid_amount": roundCents(outcome.FinalPrice + nextIncrement),
+		"next_increment":  nextIncrement,
+	})
+
+	for _, userID := range outcome.OutbidUsers {
+		s.NotifyOutbid(userID, auctionID, outcome.FinalPrice)
+	}
+}
+
+// NotifyOutbid tells a bidder they lost the lead via WebSocket, inbox and push
+func (s *BiddingService) NotifyOutbid(userID, auctionID uuid.UUID, newAmount float64) {
+	s.hub.BroadcastToUser(userID, websocket.MessageTypeBidOutbid, map[string]interface{}{
+		"auction_id": auctionID,
+		"new_amount": newAmount,
+	})
+
+	s.db.Pool.Exec(context.Background(),
+		`INSERT INTO notifications (user_id, type, title, body, related_auction_id)
+		VALUES ($1, 'outbid', 'You''ve been outbid!', $2, $3)`,
+		userID, fmt.Sprintf("Someone bid $%.2f", newAmount), auctionID,
+	)
+
+	go func() {
+		var fcmToken *string
+		var auctionTitle string
+		s.db.Pool.QueryRow(context.Background(),
+			"SELECT fcm_token FROM users WHERE id = $1", userID).Scan(&fcmToken)
+		s.db.Pool.QueryRow(context.Background(),
+			"SELECT title FROM auctions WHERE id = $1", auctionID).Scan(&auctionTitle)
+
+		if fcmToken != nil && *fcmToken != "" {
+			if err := s.fcmService.SendBidNotification(*fcmToken, auctionTitle, newAmount, auctionID.String()); err != nil {
+				log.Printf("Failed to send outbid push notification: %v", err)
+			}
+		}
+	}()
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// testIncrements returns an increment service serving the default ladder without a database
+func testIncrements() *BidIncrementService {
+	return &BidIncrementService{tiers: defaultBidIncrementTiers, loadedAt: time.Now()}
+}
+
+func TestResolveProxyBids(t *testing.T) {
+	userA, userB, userH := uuid.New(), uuid.New(), uuid.New()
+	autoA, autoB := uuid.New(), uuid.New()
+	t1 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	proxyA := func(max float64) proxyBidder {
+		return proxyBidder{autoBidID: autoA, userID: userA, maxAmount: max, maxSetAt: t1}
+	}
+	proxyB := func(max float64) proxyBidder {
+		return proxyBidder{autoBidID: autoB, userID: userB, maxAmount: max, maxSetAt: t2}
+	}
+	// A's auto-bid predates B's, but its maximum was raised after B set theirs
+	raisedA := func(max float64) proxyBidder {
+		return proxyBidder{autoBidID: autoA, userID: userA, maxAmount: max, maxSetAt: t2.Add(time.Minute)}
+	}
+
+	tests := []struct {
+		name         string
+		currentPrice float64
+		highBidder   *uuid.UUID
+		proxies      []proxyBidder // Ordered as ResolveAutoBids loads them
+		winner       *uuid.UUID
+		runnerUp     *uuid.UUID
+		runnerUpBid  float64
+		finalPrice   float64
+		outbid       []uuid.UUID
+		deactivate   []proxyDeactivation
+	}{
+		{
+			name:         "single proxy opens one increment above the start",
+			currentPrice: 10,
+			proxies:      []proxyBidder{proxyA(50)},
+			winner:       &userA,
+			finalPrice:   12,
+		},
+		{
+			name:         "higher maximum wins one increment above the runner-up",
+			currentPrice: 10,
+			proxies:      []proxyBidder{proxyB(50), proxyA(30)},
+			winner:       &userB,
+			runnerUp:     &userA,
+			runnerUpBid:  30,
+			finalPrice:   35,
+			outbid:       []uuid.UUID{userA},
+			deactivate:   []proxyDeactivation{{autoA, "outbid"}},
+		},
+		{
+			name:         "winner pays no more than their maximum",
+			currentPrice: 10,
+			proxies:      []proxyBidder{proxyB(32), proxyA(30)},
+			winner:       &userB,
+			runnerUp:     &userA,
+			runnerUpBid:  30,
+			finalPrice:   32,
+			outbid:       []uuid.UUID{userA},
+			deactivate:   []proxyDeactivation{{autoB, "max_reached"}, {autoA, "outbid"}},
+		},
+		{
+			name:         "equal maximums go to whoever set theirs first",
+			currentPrice: 10,
+			proxies:      []proxyBidder{proxyA(40), proxyB(40)},
+			winner:       &userA,
+			runnerUp:     &userB,
+			runnerUpBid:  40,
+			finalPrice:   40,
+			outbid:       []uuid.UUID{userB},
+			deactivate:   []proxyDeactivation{{autoA, "max_reached"}, {autoB, "outbid"}},
+		},
+		{
+			name:         "raising a maximum to match a rival's does not win the tie",
+			currentPrice: 10,
+			proxies:      []proxyBidder{proxyB(40), raisedA(40)},
+			winner:       &userB,
+			runnerUp:     &userA,
+			runnerUpBid:  40,
+			finalPrice:   40,
+			outbid:       []uuid.UUID{userA},
+			deactivate:   []proxyDeactivation{{autoB, "max_reached"}, {autoA, "outbid"}},
+		},
+		{
+			name:         "high bidder holds a tie they set first",
+			currentPrice: 20,
+			highBidder:   &userA,
+			proxies:      []proxyBidder{proxyA(40), proxyB(40)},
+			winner:       &userA,
+			runnerUp:     &userB,
+			runnerUpBid:  40,
+			finalPrice:   40,
+			outbid:       []uuid.UUID{userB},
+			deactivate:   []proxyDeactivation{{autoA, "max_reached"}, {autoB, "outbid"}},
+		},
+		{
+			name:         "later proxy with a tie does not take the lead",
+			currentPrice: 20,
+			highBidder:   &userB,
+			proxies:      []proxyBidder{proxyA(40), proxyB(40)},
+			winner:       &userA,
+			runnerUp:     &userB,
+			runnerUpBid:  40,
+			finalPrice:   40,
+			outbid:       []uuid.UUID{userB},
+			deactivate:   []proxyDeactivation{{autoA, "max_reached"}, {autoB, "outbid"}},
+		},
+		{
+			name:         "proxy beats a manual high bid by one increment",
+			currentPrice: 20,
+			highBidder:   &userH,
+			proxies:      []proxyBidder{proxyB(60)},
+			winner:       &userB,
+			finalPrice:   25,
+			outbid:       []uuid.UUID{userH},
+		},
+		{
+			name:         "maximum below the next increment cannot challenge",
+			currentPrice: 20,
+			highBidder:   &userA,
+			proxies:      []proxyBidder{proxyB(24), proxyA(22)},
+			finalPrice:   20,
+			deactivate:   []proxyDeactivation{{autoB, "outbid"}, {autoA, "max_reached"}},
+		},
+	}
+
+	increments := testIncrements()
+	increment := func(price float64) float64 { return increments.Increment(price, uuid.Nil, uuid.Nil) }
+	userOf := func(p *proxyBidder) *uuid.UUID {
+		if p == nil {
+			return nil
+		}
+		return &p.userID
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := resolveProxyBids(tt.currentPrice, tt.highBidder, tt.proxies, increment)
+
+			if got := userOf(res.winner); !reflect.DeepEqual(got, tt.winner) {
+				t.Errorf("winner = %v, want %v", got, tt.winner)
+			}
+			if got := userOf(res.runnerUp); !reflect.DeepEqual(got, tt.runnerUp) {
+				t.Errorf("runner-up = %v, want %v", got, tt.runnerUp)
+			}
+			if res.runnerUpBid != tt.runnerUpBid {
+				t.Errorf("runner-up bid = %.2f, want %.2f", res.runnerUpBid, tt.runnerUpBid)
+			}
+			if res.finalPrice != tt.finalPrice {
+				t.Errorf("final price = %.2f, want %.2f", res.finalPrice, tt.finalPrice)
+			}
+			if !reflect.DeepEqual(res.outbid, tt.outbid) {
+				t.Errorf("outbid = %v, want %v", res.outbid, tt.outbid)
+			}
+			if !reflect.DeepEqual(res.deactivate, tt.deactivate) {
+				t.Errorf("deactivate = %v, want %v", res.deactivate, tt.deactivate)
+			}
+		})
+	}
+}
+
+// Proxy bids are exempt from the ladder, but an uncapped winner still lands exactly one
+// increment above the runner-up's bid, which is where a client bid would have to go
+func TestResolveProxyBidsWinnerIsNextBidAfterRunnerUp(t *testing.T) {
+	increments := testIncrements()
+	increment := func(price float64) float64 { return increments.Increment(price, uuid.Nil, uuid.Nil) }
+	t1 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, runnerUpMax := range []float64{4.99, 13.37, 19.99, 33.5, 99.99, 250.01} {
+		winner := proxyBidder{autoBidID: uuid.New(), userID: uuid.New(), maxAmount: 1000, maxSetAt: t1}
+		runnerUp := proxyBidder{autoBidID: uuid.New(), userID: uuid.New(), maxAmount: runnerUpMax, maxSetAt: t1.Add(time.Second)}
+
+		res := resolveProxyBids(1, nil, []proxyBidder{winner, runnerUp}, increment)
+
+		if res.runnerUpBid != runnerUpMax {
+			t.Errorf("runner-up max %.2f: shown at %.2f, want its maximum", runnerUpMax, res.runnerUpBid)
+		}
+		next := increments.NextBid(runnerUpMax, uuid.Nil, uuid.Nil)
+		if res.finalPrice != next {
+			t.Errorf("runner-up max %.2f: final price %.2f, want %.2f", runnerUpMax, res.finalPrice, next)
+		}
+		if aligned := increments.AlignToLadder(res.runnerUpBid, res.finalPrice, uuid.Nil, uuid.Nil); aligned != res.finalPrice {
+			t.Errorf("runner-up max %.2f: final price %.2f is off the ladder, aligned to %.2f", runnerUpMax, res.finalPrice, aligned)
+		}
+	}
+}
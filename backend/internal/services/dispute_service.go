@@ -0,0 +1,560 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Default for the dispute setting in app_settings
+const defaultDisputeWindowDays = 30
+
+// lostDisputesHighSeverity is how many disputes a user must have lost in the last
+// lostDisputesWindow for a new loss to be a high severity fraud signal
+const (
+	lostDisputesHighSeverity = 3
+	lostDisputesWindow       = 180 * 24 * time.Hour
+)
+
+// Errors returned by DisputeService
+var (
+	ErrDisputeOrderNotFound = errors.New("order not found")
+	ErrDisputeReason        = errors.New("reason is not valid for your side of the order")
+	ErrOrderNotDisputable   = errors.New("order cannot be disputed")
+	ErrDisputeWindowClosed  = errors.New("dispute window has closed")
+	ErrDisputeExists        = errors.New("order already has an open dispute")
+	ErrDisputeState         = errors.New("dispute is not in a state that allows this")
+	ErrDisputeRefundAmount  = errors.New("refund amount must be more than 0 and less than the order amount")
+)
+
+const disputeColumns = `d.id, d.order_id, d.auction_id, d.buyer_id, d.seller_id, d.opened_by, d.reason, d.description,
+	d.status, d.order_status_before, d.outcome, d.at_fault, d.refund_amount, d.resolution_notes, d.resolved_by,
+	d.resolved_at, d.return_courier, d.return_tracking_number, d.return_sent_at, d.return_received_at,
+	d.created_at, d.updated_at, a.title, o.amount`
+
+func disputeScanArgs(d *models.Dispute) []interface{} {
+	return []interface{}{&d.ID, &d.OrderID, &d.AuctionID, &d.BuyerID, &d.SellerID, &d.OpenedBy, &d.Reason, &d.Description,
+		&d.Status, &d.OrderStatusBefore, &d.Outcome, &d.AtFault, &d.RefundAmount, &d.ResolutionNotes, &d.ResolvedBy,
+		&d.ResolvedAt, &d.ReturnCourier, &d.ReturnTrackingNumber, &d.ReturnSentAt, &d.ReturnReceivedAt,
+		&d.CreatedAt, &d.UpdatedAt, &d.AuctionTitle, &d.OrderAmount}
+}
+
+// DisputeService opens disputes on orders, holds the payment while they are open and
+// carries out the admin's decision
+type DisputeService struct {
+	db              *database.DB
+	payments        *PaymentService
+	notificationSvc *NotificationService
+}
+
+func NewDisputeService(db *database.DB, paymentSvc *PaymentService, notificationSvc *NotificationService) *DisputeService {
+	return &DisputeService{db: db, payments: paymentSvc, notificationSvc: notificationSvc}
+}
+
+// Get returns a dispute with its messages and evidence
+func (s *DisputeService) Get(ctx context.Context, disputeID uuid.UUID) (*models.Dispute, error) {
+	var d models.Dispute
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT `+disputeColumns+`
+		FROM disputes d
+		JOIN auctions a ON a.id = d.auction_id
+		JOIN orders o ON o.id = d.order_id
+		WHERE d.id = $1`,
+		disputeID,
+	).Scan(disputeScanArgs(&d)...)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Messages = []models.DisputeMessage{}
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT m.id, m.dispute_id, m.sender_id, m.is_admin, m.body, m.created_at, u.username
+		FROM dispute_messages m
+		LEFT JOIN users u ON u.id = m.sender_id
+		WHERE m.dispute_id = $1
+		ORDER BY m.created_at`,
+		disputeID,
+	)
+	if err == nil {
+		for rows.Next() {
+			var m models.DisputeMessage
+			if err := rows.Scan(&m.ID, &m.DisputeID, &m.SenderID, &m.IsAdmin, &m.Body, &m.CreatedAt, &m.SenderUsername); err == nil {
+				d.Messages = append(d.Messages, m)
+			}
+		}
+		rows.Close()
+	}
+
+	d.Evidence = []models.DisputeEvidence{}
+	rows, err = s.db.Pool.Query(ctx, `
+		SELECT id, dispute_id, uploaded_by, url, filename, content_type, size_bytes, created_at
+		FROM dispute_evidence
+		WHERE dispute_id = $1
+		ORDER BY created_at`,
+		disputeID,
+	)
+	if err == nil {
+		for rows.Next() {
+			var e models.DisputeEvidence
+			if err := rows.Scan(&e.ID, &e.DisputeID, &e.UploadedBy, &e.URL, &e.Filename, &e.ContentType, &e.SizeBytes, &e.CreatedAt); err == nil {
+				d.Evidence = append(d.Evidence, e)
+			}
+		}
+		rows.Close()
+	}
+
+	return &d, nil
+}
+
+// List returns disputes newest first, narrowed to those userID is the buyer or seller
+// of when it is set, and to one status when status is not empty
+func (s *DisputeService) List(ctx context.Context, userID *uuid.UUID, status string) ([]models.Dispute, error) {
+	query := `
+		SELECT ` + disputeColumns + `
+		FROM disputes d
+		JOIN auctions a ON a.id = d.auction_id
+		JOIN orders o ON o.id = d.order_id
+		WHERE 1=1`
+	args := []interface{}{}
+	if userID != nil {
+		args = append(args, *userID)
+		query += fmt.Sprintf(" AND (d.buyer_id = $%d OR d.seller_id = $%d)", len(args), len(args))
+	}
+	if status != "" {
+		args = append(args, status)
+		query += fmt.Sprintf(" AND d.status = $%d", len(args))
+	}
+	query += " ORDER BY d.created_at DESC LIMIT 100"
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list disputes: %w", err)
+	}
+	defer rows.Close()
+
+	disputes := []models.Dispute{}
+	for rows.Next() {
+		var d models.Dispute
+		if err := rows.Scan(disputeScanArgs(&d)...); err != nil {
+			log.Printf("Error scanning dispute: %v", err)
+			continue
+		}
+		disputes = append(disputes, d)
+	}
+	return disputes, nil
+}
+
+// Open starts a dispute on one of the user's orders. When only an auction is given,
+// the user's latest order for it is used. The order stays disputed, and its payment
+// held, until the dispute is withdrawn or resolved.
+func (s *DisputeService) Open(ctx context.Context, userID uuid.UUID, req *models.OpenDisputeRequest) (*models.Dispute, error) {
+	orderID := uuid.Nil
+	if req.OrderID != nil {
+		orderID = *req.OrderID
+	} else {
+		err := s.db.Pool.QueryRow(ctx, `
+			SELECT id FROM orders
+			WHERE auction_id = $1 AND (buyer_id = $2 OR seller_id = $2)
+			ORDER BY created_at DESC
+			LIMIT 1`,
+			*req.AuctionID, userID,
+		).Scan(&orderID)
+		if err != nil {
+			return nil, ErrDisputeOrderNotFound
+		}
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var buyerID, sellerID, auctionID uuid.UUID
+	var status models.OrderStatus
+	var releasedAt *time.Time
+	err = tx.QueryRow(ctx,
+		"SELECT buyer_id, seller_id, auction_id, status, released_at FROM orders WHERE id = $1 FOR UPDATE",
+		orderID,
+	).Scan(&buyerID, &sellerID, &auctionID, &status, &releasedAt)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && userID != buyerID && userID != sellerID) {
+		return nil, ErrDisputeOrderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock order %s: %w", orderID, err)
+	}
+
+	reasons := models.DisputeBuyerReasons
+	if userID == sellerID {
+		reasons = models.DisputeSellerReasons
+	}
+	validReason := false
+	for _, r := range reasons {
+		if r == req.Reason {
+			validReason = true
+			break
+		}
+	}
+	if !validReason {
+		return nil, ErrDisputeReason
+	}
+
+	switch status {
+	case models.OrderStatusPaid, models.OrderStatusDispatched, models.OrderStatusCollected:
+	case models.OrderStatusCompleted:
+		days := intSetting(ctx, s.db, "dispute_window_days", defaultDisputeWindowDays)
+		if releasedAt == nil || time.Since(*releasedAt) > time.Duration(days)*24*time.Hour {
+			return nil, ErrDisputeWindowClosed
+		}
+	case models.OrderStatusDisputed:
+		return nil, ErrDisputeExists
+	default:
+		return nil, ErrOrderNotDisputable
+	}
+
+	var disputeID uuid.UUID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO disputes (order_id, auction_id, buyer_id, seller_id, opened_by, reason, description, order_status_before)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`,
+		orderID, auctionID, buyerID, sellerID, userID, req.Reason, req.Description, string(status),
+	).Scan(&disputeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dispute on order %s: %w", orderID, err)
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE orders SET status = 'disputed', updated_at = NOW() WHERE id = $1",
+		orderID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to hold order %s: %w", orderID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit dispute: %w", err)
+	}
+
+	d, err := s.Get(ctx, disputeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load dispute %s: %w", disputeID, err)
+	}
+
+	other := sellerID
+	if userID == sellerID {
+		other = buyerID
+	}
+	s.notificationSvc.SendDisputeOpenedNotification(ctx, other, d.ID, d.AuctionID, *d.AuctionTitle, d.Reason)
+	return d, nil
+}
+
+// AddMessage adds a message to an unfinished dispute's thread and tells the other
+// parties. Admin messages go to both the buyer and the seller.
+func (s *DisputeService) AddMessage(ctx context.Context, d *models.Dispute, senderID uuid.UUID, isAdmin bool, body string) (*models.DisputeMessage, error) {
+	if d.Status != models.DisputeStatusOpen && d.Status != models.DisputeStatusAwaitingReturn {
+		return nil, ErrDisputeState
+	}
+
+	m := models.DisputeMessage{DisputeID: d.ID, SenderID: senderID, IsAdmin: isAdmin, Body: body}
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO dispute_messages (dispute_id, sender_id, is_admin, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		d.ID, senderID, isAdmin, body,
+	).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add dispute message: %w", err)
+	}
+	s.db.Pool.Exec(ctx, "UPDATE disputes SET updated_at = NOW() WHERE id = $1", d.ID)
+
+	for _, userID := range []uuid.UUID{d.BuyerID, d.SellerID} {
+		if userID != senderID {
+			s.notificationSvc.SendDisputeMessageNotification(ctx, userID, d.ID, d.AuctionID, *d.AuctionTitle, isAdmin)
+		}
+	}
+	return &m, nil
+}
+
+// AddEvidence records a file uploaded to an unfinished dispute
+func (s *DisputeService) AddEvidence(ctx context.Context, d *models.Dispute, userID uuid.UUID, url, filename, contentType string, size int64) (*models.DisputeEvidence, error) {
+	if d.Status != models.DisputeStatusOpen && d.Status != models.DisputeStatusAwaitingReturn {
+		return nil, ErrDisputeState
+	}
+
+	var e models.DisputeEvidence
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO dispute_evidence (dispute_id, uploaded_by, url, filename, content_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, dispute_id, uploaded_by, url, filename, content_type, size_bytes, created_at`,
+		d.ID, userID, url, filename, contentType, size,
+	).Scan(&e.ID, &e.DisputeID, &e.UploadedBy, &e.URL, &e.Filename, &e.ContentType, &e.SizeBytes, &e.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add dispute evidence: %w", err)
+	}
+	return &e, nil
+}
+
+// Withdraw closes an open dispute at its opener's request and puts the order back
+// where it was
+func (s *DisputeService) Withdraw(ctx context.Context, d *models.Dispute, userID uuid.UUID) error {
+	if d.OpenedBy != userID || d.Status != models.DisputeStatusOpen {
+		return ErrDisputeState
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		"UPDATE disputes SET status = 'withdrawn', updated_at = NOW() WHERE id = $1 AND status = 'open'",
+		d.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to withdraw dispute %s: %w", d.ID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDisputeState
+	}
+	if _, err := tx.Exec(ctx,
+		"UPDATE orders SET status = $1, updated_at = NOW() WHERE id = $2 AND status = 'disputed'",
+		string(d.OrderStatusBefore), d.OrderID,
+	); err != nil {
+		return fmt.Errorf("failed to restore order %s: %w", d.OrderID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit withdrawal: %w", err)
+	}
+
+	d.Status = models.DisputeStatusWithdrawn
+	s.notifyResolved(ctx, d)
+	return nil
+}
+
+// Resolve carries out an admin's decision. return_and_refund waits for the item to go
+// back to the seller; every other outcome settles the order's payment straight away.
+func (s *DisputeService) Resolve(ctx context.Context, d *models.Dispute, adminID uuid.UUID, req *models.ResolveDisputeRequest) error {
+	if d.Status != models.DisputeStatusOpen && d.Status != models.DisputeStatusAwaitingReturn {
+		return ErrDisputeState
+	}
+	if req.Outcome == models.DisputeOutcomeReturnAndRefund && d.Status != models.DisputeStatusOpen {
+		return ErrDisputeState
+	}
+
+	var refundAmount *float64
+	if req.Outcome == models.DisputeOutcomePartialRefund {
+		if req.RefundAmount == nil || d.OrderAmount == nil || *req.RefundAmount >= *d.OrderAmount {
+			return ErrDisputeRefundAmount
+		}
+		refundAmount = req.RefundAmount
+	}
+
+	if req.Outcome == models.DisputeOutcomeReturnAndRefund {
+		result, err := s.db.Pool.Exec(ctx, `
+			UPDATE disputes
+			SET status = 'awaiting_return', outcome = $1, at_fault = $2, resolution_notes = $3, resolved_by = $4, updated_at = NOW()
+			WHERE id = $5 AND status = 'open'`,
+			req.Outcome, req.AtFault, req.Notes, adminID, d.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update dispute %s: %w", d.ID, err)
+		}
+		if result.RowsAffected() == 0 {
+			return ErrDisputeState
+		}
+		d.Status = models.DisputeStatusAwaitingReturn
+		d.Outcome, d.AtFault, d.ResolutionNotes, d.ResolvedBy = &req.Outcome, &req.AtFault, req.Notes, &adminID
+		s.notifyResolved(ctx, d)
+		return nil
+	}
+
+	return s.settle(ctx, d, d.Status, req.Outcome, req.AtFault, refundAmount, req.Notes, &adminID)
+}
+
+// MarkReturnSent lets the buyer record that they sent the item back
+func (s *DisputeService) MarkReturnSent(ctx context.Context, d *models.Dispute, buyerID uuid.UUID, req *models.DisputeReturnRequest) error {
+	if d.BuyerID != buyerID || d.Status != models.DisputeStatusAwaitingReturn || d.ReturnSentAt != nil {
+		return ErrDisputeState
+	}
+
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE disputes
+		SET return_sent_at = NOW(), return_courier = $1, return_tracking_number = $2, updated_at = NOW()
+		WHERE id = $3 AND status = 'awaiting_return' AND return_sent_at IS NULL`,
+		req.Courier, req.TrackingNumber, d.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record return of dispute %s: %w", d.ID, err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrDisputeState
+	}
+
+	s.notificationSvc.SendDisputeReturnSentNotification(ctx, d.SellerID, d.ID, d.AuctionID, *d.AuctionTitle, req.Courier, req.TrackingNumber)
+	return nil
+}
+
+// MarkReturnReceived lets the seller confirm the returned item arrived, which refunds
+// the buyer in full and resolves the dispute
+func (s *DisputeService) MarkReturnReceived(ctx context.Context, d *models.Dispute, sellerID uuid.UUID) error {
+	if d.SellerID != sellerID || d.Status != models.DisputeStatusAwaitingReturn {
+		return ErrDisputeState
+	}
+	atFault := models.DisputeFaultNone
+	if d.AtFault != nil {
+		atFault = *d.AtFault
+	}
+	return s.settle(ctx, d, models.DisputeStatusAwaitingReturn, models.DisputeOutcomeReturnAndRefund, atFault, nil, d.ResolutionNotes, d.ResolvedBy)
+}
+
+// settle resolves the dispute, moves the order's money as the outcome says and records
+// the result against both users. The dispute is claimed first so two admins cannot
+// settle it twice; it is put back if the refund fails.
+func (s *DisputeService) settle(ctx context.Context, d *models.Dispute, from models.DisputeStatus, outcome, atFault string, refundAmount *float64, notes *string, resolvedBy *uuid.UUID) error {
+	prevOutcome, prevAtFault := d.Outcome, d.AtFault
+	err := s.db.Pool.QueryRow(ctx, `
+		UPDATE disputes
+		SET status = 'resolved', outcome = $1, at_fault = $2, refund_amount = $3, resolution_notes = $4,
+			resolved_by = $5, resolved_at = NOW(),
+			return_received_at = CASE WHEN status = 'awaiting_return' THEN NOW() ELSE return_received_at END,
+			updated_at = NOW()
+		WHERE id = $6 AND status = $7
+		RETURNING id`,
+		outcome, atFault, refundAmount, notes, resolvedBy, d.ID, string(from),
+	).Scan(&d.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrDisputeState
+	}
+	if err != nil {
+		return fmt.Errorf("failed to resolve dispute %s: %w", d.ID, err)
+	}
+	if resolved, err := s.Get(ctx, d.ID); err == nil {
+		*d = *resolved
+	}
+
+	var paymentID *uuid.UUID
+	s.db.Pool.QueryRow(ctx, "SELECT payment_id FROM orders WHERE id = $1", d.OrderID).Scan(&paymentID)
+
+	switch outcome {
+	case models.DisputeOutcomeRefund, models.DisputeOutcomeReturnAndRefund, models.DisputeOutcomePartialRefund:
+		amount := 0.0
+		if refundAmount != nil {
+			amount = *refundAmount
+		}
+		if paymentID != nil {
+			if err := s.payments.Refund(ctx, *paymentID, amount, "dispute "+d.ID.String()); err != nil {
+				s.db.Pool.Exec(ctx, `
+					UPDATE disputes
+					SET status = $1, outcome = $2, at_fault = $3, refund_amount = NULL, resolved_at = NULL,
+						return_received_at = NULL, updated_at = NOW()
+					WHERE id = $4`,
+					string(from), prevOutcome, prevAtFault, d.ID,
+				)
+				return fmt.Errorf("failed to refund dispute %s: %w", d.ID, err)
+			}
+		}
+	}
+
+	// A full refund marks the order refunded through the payment; anything else
+	// completes it and releases what is left to the seller
+	status := models.OrderStatusCompleted
+	if outcome == models.DisputeOutcomeRefund || outcome == models.DisputeOutcomeReturnAndRefund {
+		status = models.OrderStatusRefunded
+	}
+	if _, err := s.db.Pool.Exec(ctx, `
+		UPDATE orders
+		SET status = $1, released_at = CASE WHEN $1 = 'completed' THEN COALESCE(released_at, NOW()) ELSE released_at END, updated_at = NOW()
+		WHERE id = $2 AND status = 'disputed'`,
+		string(status), d.OrderID,
+	); err != nil {
+		log.Printf("Error settling order %s of dispute %s: %v", d.OrderID, d.ID, err)
+	}
+
+	s.recordOutcome(ctx, d, atFault)
+	s.notifyResolved(ctx, d)
+	return nil
+}
+
+// recordOutcome counts the dispute in both users' transaction stats and raises a fraud
+// signal against the side found at fault
+func (s *DisputeService) recordOutcome(ctx context.Context, d *models.Dispute, atFault string) {
+	if d.OrderStatusBefore == models.OrderStatusCompleted {
+		// The order was already counted as successful when it completed
+		var loserID *uuid.UUID
+		switch atFault {
+		case models.DisputeFaultBuyer:
+			loserID = &d.BuyerID
+		case models.DisputeFaultSeller:
+			loserID = &d.SellerID
+		}
+		if loserID != nil {
+			if err := recordLostDispute(ctx, s.db, *loserID); err != nil {
+				log.Printf("Error recording dispute %s: %v", d.ID, err)
+			}
+		}
+	} else if err := recordTransaction(ctx, s.db, d.BuyerID, d.SellerID, atFault); err != nil {
+		log.Printf("Error recording dispute %s: %v", d.ID, err)
+	}
+
+	var userID uuid.UUID
+	var column string
+	switch atFault {
+	case models.DisputeFaultBuyer:
+		userID, column = d.BuyerID, "buyer_id"
+	case models.DisputeFaultSeller:
+		userID, column = d.SellerID, "seller_id"
+	default:
+		return
+	}
+
+	var lost int
+	s.db.Pool.QueryRow(ctx,
+		"SELECT COUNT(*) FROM disputes WHERE "+column+" = $1 AND at_fault = $2 AND status = 'resolved' AND resolved_at > $3",
+		userID, atFault, time.Now().Add(-lostDisputesWindow),
+	).Scan(&lost)
+	severity := "medium"
+	if lost >= lostDisputesHighSeverity {
+		severity = "high"
+	}
+
+	details, _ := json.Marshal(map[string]interface{}{
+		"dispute_id":    d.ID,
+		"order_id":      d.OrderID,
+		"reason":        d.Reason,
+		"outcome":       d.Outcome,
+		"role":          atFault,
+		"recent_losses": lost,
+	})
+	if _, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO fraud_signals (user_id, auction_id, signal_type, severity, details)
+		VALUES ($1, $2, 'dispute_lost', $3, $4)`,
+		userID, d.AuctionID, severity, details,
+	); err != nil {
+		log.Printf("Error recording fraud signal for dispute %s: %v", d.ID, err)
+		return
+	}
+
+	// The update (not the insert) runs the fraud scoring trigger
+	s.db.Pool.Exec(ctx, "INSERT INTO user_behavior_metrics (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING", userID)
+	if _, err := s.db.Pool.Exec(ctx,
+		"UPDATE user_behavior_metrics SET last_calculated_at = NOW() WHERE user_id = $1", userID,
+	); err != nil {
+		log.Printf("Failed to rescore user %s after dispute %s: %v", userID, d.ID, err)
+	}
+}
+
+// notifyResolved tells the buyer and the seller where the dispute ended up
+func (s *DisputeService) notifyResolved(ctx context.Context, d *models.Dispute) {
+	title := ""
+	if d.AuctionTitle != nil {
+		title = *d.AuctionTitle
+	}
+	s.notificationSvc.SendDisputeResolvedNotification(ctx, d.BuyerID, d, title, "buyer")
+	s.notificationSvc.SendDisputeResolvedNotification(ctx, d.SellerID, d, title, "seller")
+}
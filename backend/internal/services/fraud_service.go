@@ -0,0 +1,522 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Fraud signal types raised by FraudService
+const (
+	SignalSelfBidding         = "self_bidding"
+	SignalShillBidding        = "shill_bidding"
+	SignalSameIPBidders       = "same_ip_bidders"
+	SignalRapidBids           = "rapid_bids"
+	SignalCancellation        = "cancellation_pattern"
+	SignalNewAccountOneSeller = "new_account_single_seller"
+)
+
+const (
+	// fraudLookback is how far back bidding behaviour is analysed
+	fraudLookback = 30 * 24 * time.Hour
+	// rapidBidGap is how soon after the previous one a manual bid counts as rapid
+	rapidBidGap = 5 * time.Second
+	// newAccountDays is the age below which an account counts as new
+	newAccountDays = 30
+	// fraudSignalDedupWindow stops the same unreviewed signal being raised again on
+	// every worker run
+	fraudSignalDedupWindow = 7 * 24 * time.Hour
+	// fraudSignalScore is what each unreviewed signal adds to calculate_fraud_score
+	fraudSignalScore = 5
+)
+
+// ErrFraudSignalNotFound is returned when a signal to review does not exist
+var ErrFraudSignalNotFound = errors.New("fraud signal not found")
+
+// FraudService analyses bidding behaviour, records fraud signals and keeps
+// user_behavior_metrics and users.fraud_score up to date
+type FraudService struct {
+	db *database.DB
+}
+
+func NewFraudService(db *database.DB) *FraudService {
+	return &FraudService{db: db}
+}
+
+// FraudSignalFilter narrows the admin review queue
+type FraudSignalFilter struct {
+	UserID     *uuid.UUID
+	Reviewed   *bool
+	Severity   string
+	SignalType string
+	Limit      int
+	Offset     int
+}
+
+const fraudSignalColumns = `
+	f.id, f.user_id, f.auction_id, f.signal_type, COALESCE(f.severity, 'low'),
+	COALESCE(f.details, '{}'::jsonb)::text, COALESCE(f.score_impact, 0), host(f.ip_address),
+	COALESCE(f.is_reviewed, false), f.reviewed_by, f.reviewed_at, COALESCE(f.auto_flagged, false),
+	f.review_action, f.review_notes, f.created_at, u.username`
+
+func fraudSignalScanArgs(f *models.FraudSignal) []interface{} {
+	return []interface{}{
+		&f.ID, &f.UserID, &f.AuctionID, &f.SignalType, &f.Severity,
+		&f.Details, &f.ScoreImpact, &f.IPAddress,
+		&f.IsReviewed, &f.ReviewedBy, &f.ReviewedAt, &f.AutoFlagged,
+		&f.ReviewAction, &f.ReviewNotes, &f.CreatedAt, &f.Username,
+	}
+}
+
+const behaviorMetricsColumns = `
+	m.user_id, COALESCE(m.bid_cancel_rate, 0), m.avg_bid_time_before_end_mins,
+	COALESCE(m.same_ip_bidders_count, 0), COALESCE(m.rapid_bid_count, 0), COALESCE(m.self_bid_attempts, 0),
+	COALESCE(m.unpaid_strike_count, 0), COALESCE(m.shill_bid_probability, 0), COALESCE(m.account_age_days, 0),
+	COALESCE(m.risk_level, 'low'), COALESCE(m.last_calculated_at, NOW()),
+	u.fraud_score, u.is_flagged, u.flag_reason, u.username`
+
+func behaviorMetricsScanArgs(m *models.UserBehaviorMetrics) []interface{} {
+	return []interface{}{
+		&m.UserID, &m.BidCancelRate, &m.AvgBidTimeBeforeEnd,
+		&m.SameIPBiddersCount, &m.RapidBidCount, &m.SelfBidAttempts,
+		&m.UnpaidStrikeCount, &m.ShillBidProbability, &m.AccountAgeDays,
+		&m.RiskLevel, &m.LastCalculatedAt,
+		&m.FraudScore, &m.IsFlagged, &m.FlagReason, &m.Username,
+	}
+}
+
+// RecordSelfBidAttempt counts a seller trying to bid on their own auction
+func (s *FraudService) RecordSelfBidAttempt(ctx context.Context, userID uuid.UUID) {
+	var attempts int
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO user_behavior_metrics (user_id, self_bid_attempts) VALUES ($1, 1)
+		ON CONFLICT (user_id) DO UPDATE SET self_bid_attempts = COALESCE(user_behavior_metrics.self_bid_attempts, 0) + 1
+		RETURNING self_bid_attempts`,
+		userID,
+	).Scan(&attempts)
+	if err != nil {
+		log.Printf("Failed to record self-bid attempt for user %s: %v", userID, err)
+		return
+	}
+
+	severity := "low"
+	if attempts >= 3 {
+		severity = "medium"
+	}
+	s.raiseSignal(ctx, userID, nil, SignalSelfBidding, severity, nil, map[string]interface{}{
+		"attempts": attempts,
+	})
+	if err := s.Rescore(ctx, userID); err != nil {
+		log.Printf("Failed to rescore user %s: %v", userID, err)
+	}
+}
+
+// Analyze recalculates the user's bidding behaviour over the lookback window, raises
+// signals for shill bidding patterns and rescores them
+func (s *FraudService) Analyze(ctx context.Context, userID uuid.UUID) (*models.UserBehaviorMetrics, error) {
+	since := time.Now().Add(-fraudLookback)
+
+	var accountAgeDays int
+	err := s.db.Pool.QueryRow(ctx,
+		"SELECT EXTRACT(DAY FROM NOW() - COALESCE(created_at, NOW()))::int FROM users WHERE id = $1", userID,
+	).Scan(&accountAgeDays)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("user %s not found: %w", userID, err)
+		}
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+
+	// Other bidders seen on the user's IPs, and auctions where one of them bid against
+	// the user from the same IP
+	var sameIPBidders, sameIPAuctions int
+	var sharedIP *string
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(DISTINCT o.bidder_id),
+			COUNT(DISTINCT o.auction_id) FILTER (WHERE o.auction_id = b.auction_id),
+			MODE() WITHIN GROUP (ORDER BY b.ip_address)
+		FROM bids b
+		JOIN bids o ON o.ip_address = b.ip_address AND o.bidder_id <> b.bidder_id AND o.created_at > $2
+		WHERE b.bidder_id = $1 AND b.ip_address IS NOT NULL AND b.created_at > $2`,
+		userID, since,
+	).Scan(&sameIPBidders, &sameIPAuctions, &sharedIP)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count same-IP bidders: %w", err)
+	}
+
+	var rapidBids int
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*) FROM (
+			SELECT created_at - LAG(created_at) OVER (ORDER BY created_at) AS gap
+			FROM bids
+			WHERE bidder_id = $1 AND COALESCE(is_auto_bid, false) = false AND created_at > $2
+		) g
+		WHERE g.gap < make_interval(secs => $3)`,
+		userID, since, rapidBidGap.Seconds(),
+	).Scan(&rapidBids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rapid bids: %w", err)
+	}
+
+	var autoBids, cancelledAutoBids int
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE deactivation_reason = 'user_cancelled')
+		FROM auto_bids WHERE user_id = $1 AND created_at > $2`,
+		userID, since,
+	).Scan(&autoBids, &cancelledAutoBids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count auto-bids: %w", err)
+	}
+	cancelRate := 0.0
+	if autoBids >= 3 {
+		cancelRate = math.Round(float64(cancelledAutoBids)/float64(autoBids)*10000) / 100
+	}
+
+	var avgMinsBeforeEnd *int
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT AVG(EXTRACT(EPOCH FROM a.end_time - b.created_at) / 60)::int
+		FROM bids b
+		JOIN auctions a ON a.id = b.auction_id
+		WHERE b.bidder_id = $1 AND COALESCE(b.is_auto_bid, false) = false
+			AND b.created_at > $2 AND a.end_time IS NOT NULL`,
+		userID, since,
+	).Scan(&avgMinsBeforeEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to average bid timing: %w", err)
+	}
+
+	// The seller the user bids on most. Shill bidders concentrate on one seller and bid
+	// prices up across their auctions without winning them.
+	var topSellerID *uuid.UUID
+	var sellerBids, sellerAuctions, sellerWins, totalBids, sellers int
+	err = s.db.Pool.QueryRow(ctx, `
+		SELECT a.seller_id, COUNT(*), COUNT(DISTINCT a.id),
+			COUNT(DISTINCT a.id) FILTER (WHERE a.winner_id = $1),
+			(SUM(COUNT(*)) OVER ())::int, (COUNT(*) OVER ())::int
+		FROM bids b
+		JOIN auctions a ON a.id = b.auction_id
+		WHERE b.bidder_id = $1 AND b.created_at > $2
+		GROUP BY a.seller_id
+		ORDER BY COUNT(*) DESC
+		LIMIT 1`,
+		userID, since,
+	).Scan(&topSellerID, &sellerBids, &sellerAuctions, &sellerWins, &totalBids, &sellers)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load seller concentration: %w", err)
+	}
+
+	// Bids from an IP the top seller has also bid from
+	var sellerIPMatch bool
+	if topSellerID != nil {
+		s.db.Pool.QueryRow(ctx, `
+			SELECT EXISTS(
+				SELECT 1 FROM bids b
+				JOIN bids sb ON sb.ip_address = b.ip_address AND sb.bidder_id = $2 AND sb.created_at > $3
+				WHERE b.bidder_id = $1 AND b.ip_address IS NOT NULL AND b.created_at > $3
+			)`,
+			userID, *topSellerID, since,
+		).Scan(&sellerIPMatch)
+	}
+
+	shill := 0.0
+	topShare := 0.0
+	if totalBids > 0 {
+		topShare = float64(sellerBids) / float64(totalBids)
+	}
+	if sellerBids >= 5 {
+		shill += 40 * topShare
+	}
+	if sellerAuctions >= 3 && sellerWins == 0 {
+		shill += 20
+	}
+	newAccountOneSeller := accountAgeDays < newAccountDays && sellers == 1 && totalBids >= 3
+	if newAccountOneSeller {
+		shill += 25
+	}
+	if sameIPAuctions > 0 {
+		shill += 15
+	}
+	if sellerIPMatch {
+		shill += 30
+	}
+	shill = math.Round(math.Min(shill, 100)*100) / 100
+
+	if _, err := s.db.Pool.Exec(ctx,
+		"INSERT INTO user_behavior_metrics (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING",
+		userID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to create behaviour metrics: %w", err)
+	}
+	if _, err := s.db.Pool.Exec(ctx, `
+		UPDATE user_behavior_metrics SET
+			bid_cancel_rate = $2,
+			avg_bid_time_before_end_mins = $3,
+			same_ip_bidders_count = $4,
+			rapid_bid_count = $5,
+			shill_bid_probability = $6,
+			account_age_days = $7,
+			last_calculated_at = NOW()
+		WHERE user_id = $1`,
+		userID, cancelRate, avgMinsBeforeEnd, sameIPBidders, rapidBids, shill, accountAgeDays,
+	); err != nil {
+		return nil, fmt.Errorf("failed to update behaviour metrics: %w", err)
+	}
+
+	if shill >= 30 && topSellerID != nil {
+		severity := "medium"
+		if shill >= 50 {
+			severity = "high"
+		}
+		s.raiseSignal(ctx, userID, nil, SignalShillBidding, severity, nil, map[string]interface{}{
+			"probability":     shill,
+			"seller_id":       *topSellerID,
+			"seller_bids":     sellerBids,
+			"total_bids":      totalBids,
+			"seller_auctions": sellerAuctions,
+			"seller_wins":     sellerWins,
+			"seller_ip_match": sellerIPMatch,
+		})
+	}
+	if sameIPBidders > 0 {
+		severity := "medium"
+		if sameIPBidders >= 3 {
+			severity = "high"
+		}
+		s.raiseSignal(ctx, userID, nil, SignalSameIPBidders, severity, sharedIP, map[string]interface{}{
+			"other_bidders":      sameIPBidders,
+			"same_auction_count": sameIPAuctions,
+		})
+	}
+	if rapidBids >= 10 {
+		severity := "low"
+		if rapidBids >= 30 {
+			severity = "medium"
+		}
+		s.raiseSignal(ctx, userID, nil, SignalRapidBids, severity, nil, map[string]interface{}{
+			"rapid_bids":  rapidBids,
+			"gap_seconds": rapidBidGap.Seconds(),
+		})
+	}
+	if cancelRate >= 30 && autoBids >= 5 {
+		s.raiseSignal(ctx, userID, nil, SignalCancellation, "medium", nil, map[string]interface{}{
+			"cancel_rate": cancelRate,
+			"auto_bids":   autoBids,
+			"cancelled":   cancelledAutoBids,
+		})
+	}
+	if newAccountOneSeller {
+		s.raiseSignal(ctx, userID, nil, SignalNewAccountOneSeller, "medium", nil, map[string]interface{}{
+			"account_age_days": accountAgeDays,
+			"seller_id":        *topSellerID,
+			"bids":             totalBids,
+		})
+	}
+
+	if err := s.Rescore(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.Metrics(ctx, userID)
+}
+
+// raiseSignal records an automatic fraud signal unless the same one is already waiting
+// for review
+func (s *FraudService) raiseSignal(ctx context.Context, userID uuid.UUID, auctionID *uuid.UUID, signalType, severity string, ipAddress *string, details map[string]interface{}) {
+	detailsJSON, _ := json.Marshal(details)
+	if _, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO fraud_signals (user_id, auction_id, signal_type, severity, details, score_impact, ip_address, auto_flagged)
+		SELECT $1, $2, $3, $4, $5, $6, $7::inet, true
+		WHERE NOT EXISTS (
+			SELECT 1 FROM fraud_signals
+			WHERE user_id = $1 AND signal_type = $3 AND is_reviewed = false AND created_at > $8
+		)`,
+		userID, auctionID, signalType, severity, detailsJSON, fraudSignalScore, ipAddress,
+		time.Now().Add(-fraudSignalDedupWindow),
+	); err != nil {
+		log.Printf("Error recording %s fraud signal for user %s: %v", signalType, userID, err)
+	}
+}
+
+// Rescore reruns the fraud scoring trigger on the user's metrics and stores the score
+// on the user. The trigger scores the row as it was before the update, so this must
+// run after the metrics themselves have been written.
+func (s *FraudService) Rescore(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.db.Pool.Exec(ctx,
+		"INSERT INTO user_behavior_metrics (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING",
+		userID,
+	); err != nil {
+		return fmt.Errorf("failed to create behaviour metrics: %w", err)
+	}
+	if _, err := s.db.Pool.Exec(ctx,
+		"UPDATE user_behavior_metrics SET last_calculated_at = NOW() WHERE user_id = $1", userID,
+	); err != nil {
+		return fmt.Errorf("failed to rescore user: %w", err)
+	}
+	if _, err := s.db.Pool.Exec(ctx,
+		"UPDATE users SET fraud_score = calculate_fraud_score($1) WHERE id = $1", userID,
+	); err != nil {
+		return fmt.Errorf("failed to store fraud score: %w", err)
+	}
+	return nil
+}
+
+// Metrics returns the user's behaviour metrics with their fraud score and flag
+func (s *FraudService) Metrics(ctx context.Context, userID uuid.UUID) (*models.UserBehaviorMetrics, error) {
+	var m models.UserBehaviorMetrics
+	err := s.db.Pool.QueryRow(ctx,
+		"SELECT "+behaviorMetricsColumns+" FROM user_behavior_metrics m JOIN users u ON u.id = m.user_id WHERE m.user_id = $1",
+		userID,
+	).Scan(behaviorMetricsScanArgs(&m)...)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// ListMetrics returns users by fraud score, optionally only those at a risk level
+func (s *FraudService) ListMetrics(ctx context.Context, riskLevel string, limit, offset int) ([]models.UserBehaviorMetrics, int, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT `+behaviorMetricsColumns+`, COUNT(*) OVER()
+		FROM user_behavior_metrics m
+		JOIN users u ON u.id = m.user_id
+		WHERE ($1 = '' OR m.risk_level = $1)
+		ORDER BY COALESCE(u.fraud_score, 0) DESC, m.shill_bid_probability DESC
+		LIMIT $2 OFFSET $3`,
+		riskLevel, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch behaviour metrics: %w", err)
+	}
+	defer rows.Close()
+
+	metrics := []models.UserBehaviorMetrics{}
+	total := 0
+	for rows.Next() {
+		var m models.UserBehaviorMetrics
+		if err := rows.Scan(append(behaviorMetricsScanArgs(&m), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to read behaviour metrics: %w", err)
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, total, rows.Err()
+}
+
+// ListSignals returns the review queue: unreviewed and most severe signals first
+func (s *FraudService) ListSignals(ctx context.Context, filter FraudSignalFilter) ([]models.FraudSignal, int, error) {
+	where := []string{"1=1"}
+	args := []interface{}{}
+	if filter.UserID != nil {
+		args = append(args, *filter.UserID)
+		where = append(where, fmt.Sprintf("f.user_id = $%d", len(args)))
+	}
+	if filter.Reviewed != nil {
+		args = append(args, *filter.Reviewed)
+		where = append(where, fmt.Sprintf("COALESCE(f.is_reviewed, false) = $%d", len(args)))
+	}
+	if filter.Severity != "" {
+		args = append(args, filter.Severity)
+		where = append(where, fmt.Sprintf("f.severity = $%d", len(args)))
+	}
+	if filter.SignalType != "" {
+		args = append(args, filter.SignalType)
+		where = append(where, fmt.Sprintf("f.signal_type = $%d", len(args)))
+	}
+	args = append(args, filter.Limit, filter.Offset)
+
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s, COUNT(*) OVER()
+		FROM fraud_signals f
+		LEFT JOIN users u ON u.id = f.user_id
+		WHERE %s
+		ORDER BY COALESCE(f.is_reviewed, false),
+			CASE f.severity WHEN 'critical' THEN 0 WHEN 'high' THEN 1 WHEN 'medium' THEN 2 ELSE 3 END,
+			f.created_at DESC
+		LIMIT $%d OFFSET $%d`,
+		fraudSignalColumns, strings.Join(where, " AND "), len(args)-1, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch fraud signals: %w", err)
+	}
+	defer rows.Close()
+
+	signals := []models.FraudSignal{}
+	total := 0
+	for rows.Next() {
+		var f models.FraudSignal
+		if err := rows.Scan(append(fraudSignalScanArgs(&f), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to read fraud signal: %w", err)
+		}
+		signals = append(signals, f)
+	}
+	return signals, total, rows.Err()
+}
+
+// ReviewSignal records an admin's verdict on a signal. Reviewed signals no longer count
+// towards the user's fraud score.
+func (s *FraudService) ReviewSignal(ctx context.Context, signalID, adminID uuid.UUID, action string, notes *string) (*models.FraudSignal, error) {
+	var userID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx, `
+		UPDATE fraud_signals
+		SET is_reviewed = true, reviewed_by = $2, reviewed_at = NOW(), review_action = $3, review_notes = $4
+		WHERE id = $1
+		RETURNING user_id`,
+		signalID, adminID, action, notes,
+	).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrFraudSignalNotFound
+		}
+		return nil, fmt.Errorf("failed to review fraud signal: %w", err)
+	}
+
+	if err := s.Rescore(ctx, userID); err != nil {
+		log.Printf("Failed to rescore user %s after review: %v", userID, err)
+	}
+
+	var f models.FraudSignal
+	err = s.db.Pool.QueryRow(ctx,
+		"SELECT "+fraudSignalColumns+" FROM fraud_signals f LEFT JOIN users u ON u.id = f.user_id WHERE f.id = $1",
+		signalID,
+	).Scan(fraudSignalScanArgs(&f)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load fraud signal: %w", err)
+	}
+	return &f, nil
+}
+
+// ReviewUser marks all of the user's open signals reviewed with the same verdict.
+// clearFlag lifts the automatic flag, for users found not to be committing fraud.
+func (s *FraudService) ReviewUser(ctx context.Context, userID, adminID uuid.UUID, action string, notes *string, clearFlag bool) (int64, error) {
+	tag, err := s.db.Pool.Exec(ctx, `
+		UPDATE fraud_signals
+		SET is_reviewed = true, reviewed_by = $2, reviewed_at = NOW(), review_action = $3, review_notes = $4
+		WHERE user_id = $1 AND is_reviewed = false`,
+		userID, adminID, action, notes,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to review fraud signals: %w", err)
+	}
+
+	if clearFlag {
+		if _, err := s.db.Pool.Exec(ctx,
+			"UPDATE users SET is_flagged = false, flag_reason = NULL WHERE id = $1", userID,
+		); err != nil {
+			return 0, fmt.Errorf("failed to clear flag: %w", err)
+		}
+	}
+
+	if err := s.Rescore(ctx, userID); err != nil {
+		log.Printf("Failed to rescore user %s after review: %v", userID, err)
+	}
+	return tag.RowsAffected(), nil
+}
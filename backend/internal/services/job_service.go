@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Background jobs
+const (
+	JobNudgeStaleStores = "nudge_stale_stores"
+	JobPruneSessions    = "prune_sessions"
+)
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// jobDefinition is a background job and the setting holding its interval in hours
+type jobDefinition struct {
+	description     string
+	intervalSetting string
+	defaultInterval int
+	run             func(ctx context.Context) (map[string]interface{}, error)
+}
+
+// JobService runs the background jobs and keeps their run history
+type JobService struct {
+	db   *database.DB
+	jobs map[string]jobDefinition
+}
+
+func NewJobService(db *database.DB) *JobService {
+	s := &JobService{db: db}
+	s.jobs = map[string]jobDefinition{
+		JobNudgeStaleStores: {
+			description:     "Nudge sellers whose products have not been confirmed for 30 days",
+			intervalSetting: "job_nudge_stale_stores_interval_hours",
+			defaultInterval: 24,
+			run:             s.nudgeStaleStores,
+		},
+		JobPruneSessions: {
+			description:     "Delete sessions that expired or were signed out over 30 days ago",
+			intervalSetting: "job_prune_sessions_interval_hours",
+			defaultInterval: 24,
+			run:             s.pruneSessions,
+		},
+	}
+	return s
+}
+
+// Names returns the job names in order
+func (s *JobService) Names() []string {
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IntervalHours returns how often a job is scheduled; 0 turns the schedule off
+func (s *JobService) IntervalHours(ctx context.Context, name string) int {
+	job, ok := s.jobs[name]
+	if !ok {
+		return 0
+	}
+	return intSetting(ctx, s.db, job.intervalSetting, job.defaultInterval)
+}
+
+// Due reports whether a scheduled job has not started within its interval
+func (s *JobService) Due(ctx context.Context, name string) bool {
+	hours := s.IntervalHours(ctx, name)
+	if hours == 0 {
+		return false
+	}
+	var due bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT NOT EXISTS (
+			SELECT 1 FROM job_runs
+			WHERE job_name = $1 AND started_at > NOW() - make_interval(hours => $2)
+		)`, name, hours,
+	).Scan(&due)
+	if err != nil {
+		log.Printf("Error checking schedule for job %s: %v", name, err)
+		return false
+	}
+	return due
+}
+
+const jobRunColumns = `
+	id, job_name, trigger, triggered_by, status, result, error, started_at, finished_at,
+	(EXTRACT(EPOCH FROM (finished_at - started_at)) * 1000)::BIGINT`
+
+func jobRunScanArgs(r *models.JobRun) []interface{} {
+	return []interface{}{
+		&r.ID, &r.JobName, &r.Trigger, &r.TriggeredBy, &r.Status, &r.Result, &r.Error,
+		&r.StartedAt, &r.FinishedAt, &r.DurationMs,
+	}
+}
+
+// Run runs a job now and records the run. Only one run of a job happens at a time,
+// across all server instances; a job that fails is recorded as failed and returned
+// without an error.
+func (s *JobService) Run(ctx context.Context, name, trigger string, triggeredBy *uuid.UUID) (*models.JobRun, error) {
+	job, ok := s.jobs[name]
+	if !ok {
+		return nil, ErrUnknownJob
+	}
+
+	// The advisory lock belongs to this connection, so hold it for the whole run
+	conn, err := s.db.Pool.Acquire(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtext('job:' || $1))", name).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to lock job: %w", err)
+	}
+	if !locked {
+		return nil, ErrJobRunning
+	}
+	defer conn.Exec(context.Background(), "SELECT pg_advisory_unlock(hashtext('job:' || $1))", name)
+
+	var runID uuid.UUID
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO job_runs (job_name, trigger, triggered_by, status)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`,
+		name, trigger, triggeredBy, models.JobRunRunning,
+	).Scan(&runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record job run: %w", err)
+	}
+
+	result, runErr := job.run(ctx)
+	status := models.JobRunSucceeded
+	var resultJSON interface{}
+	if result != nil {
+		resultJSON = result
+	}
+	var errText *string
+	if runErr != nil {
+		status = models.JobRunFailed
+		msg := runErr.Error()
+		errText = &msg
+	}
+
+	// Record the outcome even if ctx was cancelled during the run
+	var run models.JobRun
+	err = s.db.Pool.QueryRow(context.Background(), fmt.Sprintf(`
+		UPDATE job_runs SET status = $2, result = $3, error = $4, finished_at = NOW()
+		WHERE id = $1
+		RETURNING %s`, jobRunColumns),
+		runID, status, resultJSON, errText,
+	).Scan(jobRunScanArgs(&run)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record job result: %w", err)
+	}
+	return &run, nil
+}
+
+// LastRun returns a job's latest run, or nil if it has never run
+func (s *JobService) LastRun(ctx context.Context, name string) (*models.JobRun, error) {
+	runs, _, err := s.Runs(ctx, name, 1, 0)
+	if err != nil || len(runs) == 0 {
+		return nil, err
+	}
+	return &runs[0], nil
+}
+
+// Runs returns job runs, newest first, optionally for one job, with the total count
+func (s *JobService) Runs(ctx context.Context, name string, limit, offset int) ([]models.JobRun, int, error) {
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s, COUNT(*) OVER()
+		FROM job_runs
+		WHERE $1 = '' OR job_name = $1
+		ORDER BY started_at DESC
+		LIMIT $2 OFFSET $3`, jobRunColumns),
+		name, limit, offset,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch job runs: %w", err)
+	}
+	defer rows.Close()
+
+	runs := []models.JobRun{}
+	total := 0
+	for rows.Next() {
+		var r models.JobRun
+		if err := rows.Scan(append(jobRunScanArgs(&r), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to read job run: %w", err)
+		}
+		runs = append(runs, r)
+	}
+	return runs, total, rows.Err()
+}
+
+// Jobs describes every job with its schedule and latest run
+func (s *JobService) Jobs(ctx context.Context) ([]models.JobInfo, error) {
+	jobs := []models.JobInfo{}
+	for _, name := range s.Names() {
+		last, err := s.LastRun(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, models.JobInfo{
+			Name:          name,
+			Description:   s.jobs[name].description,
+			IntervalHours: s.IntervalHours(ctx, name),
+			LastRun:       last,
+		})
+	}
+	return jobs, nil
+}
+
+// nudgeStaleStores notifies the owners of active stores whose available products
+// have all gone unconfirmed for 30 days. A store is nudged at most once a week.
+func (s *JobService) nudgeStaleStores(ctx context.Context) (map[string]interface{}, error) {
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT s.id, s.user_id, s.store_name
+		FROM stores s
+		JOIN products p ON s.id = p.store_id
+		WHERE s.is_active = true AND p.is_available = true
+			AND NOT EXISTS (
+				SELECT 1 FROM notifications n
+				WHERE n.user_id = s.user_id
+					AND n.data->>'type' = 'freshness_nudge'
+					AND n.data->>'store_id' = s.id::text
+					AND n.created_at > NOW() - INTERVAL '7 days'
+			)
+		GROUP BY s.id
+		HAVING MAX(p.last_confirmed_at) < NOW() - INTERVAL '30 days'`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale stores: %w", err)
+	}
+
+	type staleStore struct {
+		id, userID uuid.UUID
+		name       string
+	}
+	var stores []staleStore
+	for rows.Next() {
+		var st staleStore
+		if err := rows.Scan(&st.id, &st.userID, &st.name); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to read stale store: %w", err)
+		}
+		stores = append(stores, st)
+	}
+	rows.Close()
+
+	nudged := 0
+	for _, st := range stores {
+		message := fmt.Sprintf("Your store '%s' is looking dusty! View your dashboard to boost visibility.", st.name)
+		metaJSON, _ := json.Marshal(map[string]string{"type": "freshness_nudge", "store_id": st.id.String()})
+
+		_, err := s.db.Pool.Exec(ctx, `
+			INSERT INTO notifications (user_id, type, title, body, data)
+			VALUES ($1, 'system', 'Boost Your Visibility', $2, $3)`,
+			st.userID, message, metaJSON,
+		)
+		if err != nil {
+			log.Printf("Error nudging stale store %s: %v", st.id, err)
+			continue
+		}
+		nudged++
+	}
+
+	return map[string]interface{}{"stores_found": len(stores), "stores_nudged": nudged}, nil
+}
+
+// pruneSessions deletes sessions (and their retired refresh tokens) that ended over
+// 30 days ago
+func (s *JobService) pruneSessions(ctx context.Context) (map[string]interface{}, error) {
+	result, err := s.db.Pool.Exec(ctx, `
+		DELETE FROM user_sessions
+		WHERE expires_at < NOW() - INTERVAL '30 days' OR revoked_at < NOW() - INTERVAL '30 days'`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune sessions: %w", err)
+	}
+	return map[string]interface{}{"sessions_deleted": result.RowsAffected()}, nil
+}
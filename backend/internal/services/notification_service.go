@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/airmass/backend/internal/database"
 	"github.com/airmass/backend/internal/fcm"
+	"github.com/airmass/backend/internal/models"
 	"github.com/airmass/backend/internal/websocket"
 	"github.com/google/uuid"
 )
@@ -171,3 +174,584 @@ func (s *NotificationService) CreateConversation(ctx context.Context, auctionID,
 	log.Printf("✅ Created conversation %s between %s and %s", conversationID, participant1, participant2)
 	return conversationID, nil
 }
+
+// SendReserveNotMetSellerNotification tells the seller the auction ended below their reserve
+func (s *NotificationService) SendReserveNotMetSellerNotification(ctx context.Context, sellerID, auctionID uuid.UUID, auctionTitle string, highestBid float64) error {
+	title := "⏰ Reserve Not Met"
+	body := fmt.Sprintf("Your auction '%s' ended at R%.2f, below your reserve. You can send the top bidder a second-chance offer.", auctionTitle, highestBid)
+
+	err := s.notifyUser(ctx, sellerID, auctionID, "reserve_not_met", title, body, map[string]interface{}{
+		"auction_id":  auctionID,
+		"highest_bid": highestBid,
+		"role":        "seller",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reserve not met notification: %w", err)
+	}
+
+	log.Printf("✅ Sent 'reserve not met' notification to seller %s for auction %s", sellerID, auctionID)
+	return nil
+}
+
+// SendReserveNotMetBidderNotification tells the top bidder they did not win because of the reserve
+func (s *NotificationService) SendReserveNotMetBidderNotification(ctx context.Context, bidderID, auctionID uuid.UUID, auctionTitle string, highestBid float64) error {
+	title := "⏰ Reserve Not Met"
+	body := fmt.Sprintf("'%s' ended with your bid of R%.2f, but the seller's reserve was not met. The seller may still send you an offer.", auctionTitle, highestBid)
+
+	err := s.notifyUser(ctx, bidderID, auctionID, "reserve_not_met", title, body, map[string]interface{}{
+		"auction_id":  auctionID,
+		"highest_bid": highestBid,
+		"role":        "bidder",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create reserve not met notification: %w", err)
+	}
+
+	s.sendPush(bidderID, title, body, map[string]string{
+		"type":       "reserve_not_met",
+		"auction_id": auctionID.String(),
+		"route":      "/auction/" + auctionID.String(),
+	})
+
+	log.Printf("✅ Sent 'reserve not met' notification to bidder %s for auction %s", bidderID, auctionID)
+	return nil
+}
+
+// SendSecondChanceOfferNotification tells the top bidder the seller is offering them the item
+func (s *NotificationService) SendSecondChanceOfferNotification(ctx context.Context, bidderID, offerID, auctionID uuid.UUID, auctionTitle string, amount float64) error {
+	title := "🤝 Second-Chance Offer"
+	body := fmt.Sprintf("The seller of '%s' is offering it to you for R%.2f.", auctionTitle, amount)
+
+	err := s.notifyUser(ctx, bidderID, auctionID, "second_chance_offer", title, body, map[string]interface{}{
+		"auction_id": auctionID,
+		"offer_id":   offerID,
+		"amount":     amount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create second-chance offer notification: %w", err)
+	}
+
+	s.sendPush(bidderID, title, body, map[string]string{
+		"type":       "second_chance_offer",
+		"auction_id": auctionID.String(),
+		"offer_id":   offerID.String(),
+		"route":      "/auction/" + auctionID.String(),
+	})
+
+	log.Printf("✅ Sent 'second-chance offer' notification to user %s for auction %s", bidderID, auctionID)
+	return nil
+}
+
+// SendSecondChanceDeclinedNotification tells the seller the top bidder declined their offer
+func (s *NotificationService) SendSecondChanceDeclinedNotification(ctx context.Context, sellerID, auctionID uuid.UUID, auctionTitle string) error {
+	title := "Second-Chance Offer Declined"
+	body := fmt.Sprintf("The top bidder declined your offer for '%s'. You can relist it anytime.", auctionTitle)
+
+	err := s.notifyUser(ctx, sellerID, auctionID, "second_chance_declined", title, body, map[string]interface{}{
+		"auction_id": auctionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create second-chance declined notification: %w", err)
+	}
+	return nil
+}
+
+// notifyUser stores an inbox notification and pushes it over WebSocket.
+// Pass uuid.Nil as auctionID for notifications that are not about an auction.
+func (s *NotificationService) notifyUser(ctx context.Context, userID, auctionID uuid.UUID, notificationType, title, body string, data map[string]interface{}) error {
+	jsonData, _ := json.Marshal(data)
+
+	var relatedAuctionID *uuid.UUID
+	if auctionID != uuid.Nil {
+		relatedAuctionID = &auctionID
+	}
+
+	notificationID := uuid.New()
+	_, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO notifications (id, user_id, type, title, body, related_auction_id, data, is_read, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, false, NOW())
+	`, notificationID, userID, notificationType, title, body, relatedAuctionID, jsonData)
+	if err != nil {
+		return err
+	}
+
+	s.hub.BroadcastToUser(userID, websocket.MessageTypeNotification, map[string]interface{}{
+		"id":                 notificationID,
+		"type":               notificationType,
+		"title":              title,
+		"body":               body,
+		"related_auction_id": relatedAuctionID,
+		"is_read":            false,
+		"data":               data,
+	})
+	return nil
+}
+
+// sendPush sends an FCM push to the user's device in the background
+func (s *NotificationService) sendPush(userID uuid.UUID, title, body string, data map[string]string) {
+	go func() {
+		var fcmToken *string
+		s.db.Pool.QueryRow(context.Background(),
+			"SELECT fcm_token FROM users WHERE id = $1", userID).Scan(&fcmToken)
+		if fcmToken != nil && *fcmToken != "" {
+			if err := s.fcmService.SendToDevice(*fcmToken, title, body, data); err != nil {
+				log.Printf("Failed to send push notification: %v", err)
+			}
+		}
+	}()
+}
+
+// SendOfferNotification tells one side of an offer negotiation what just happened.
+// The offer itself goes out as a dedicated WebSocket event so open screens can update
+// in place; the inbox notification and push carry the human-readable message.
+func (s *NotificationService) SendOfferNotification(ctx context.Context, userID uuid.UUID, event websocket.MessageType, offer *models.Offer, auctionTitle string) error {
+	var notificationType, title, body string
+	switch event {
+	case websocket.MessageTypeOfferNew:
+		notificationType, title = "offer_received", "💬 New Offer"
+		body = fmt.Sprintf("You received an offer of R%.2f for '%s'.", offer.Amount, auctionTitle)
+	case websocket.MessageTypeOfferCountered:
+		notificationType, title = "offer_countered", "🔁 Counter-Offer"
+		body = fmt.Sprintf("The seller countered your offer on '%s' with R%.2f.", auctionTitle, offer.AgreedAmount())
+	case websocket.MessageTypeOfferAccepted:
+		notificationType, title = "offer_accepted", "🤝 Offer Accepted"
+		body = fmt.Sprintf("The offer of R%.2f for '%s' was accepted.", offer.AgreedAmount(), auctionTitle)
+	case websocket.MessageTypeOfferDeclined:
+		notificationType, title = "offer_declined", "Offer Declined"
+		body = fmt.Sprintf("The offer on '%s' was declined.", auctionTitle)
+	case websocket.MessageTypeOfferExpired:
+		notificationType, title = "offer_expired", "⏰ Offer Expired"
+		body = fmt.Sprintf("The offer on '%s' expired without a response.", auctionTitle)
+	default:
+		return fmt.Errorf("unsupported offer event: %s", event)
+	}
+
+	s.hub.BroadcastToUser(userID, event, offer)
+
+	err := s.notifyUser(ctx, userID, offer.AuctionID, notificationType, title, body, map[string]interface{}{
+		"auction_id": offer.AuctionID,
+		"offer_id":   offer.ID,
+		"status":     offer.Status,
+		"amount":     offer.AgreedAmount(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create offer notification: %w", err)
+	}
+
+	s.sendPush(userID, title, body, map[string]string{
+		"type":       notificationType,
+		"auction_id": offer.AuctionID.String(),
+		"offer_id":   offer.ID.String(),
+		"route":      "/auction/" + offer.AuctionID.String(),
+	})
+	return nil
+}
+
+// SendAuctionLiveNotifications tells the seller their scheduled auction started and
+// lets everyone following the seller's store know it is open for bids
+func (s *NotificationService) SendAuctionLiveNotifications(ctx context.Context, sellerID, auctionID uuid.UUID, auctionTitle string) error {
+	title := "🚀 Your Auction Is Live"
+	body := fmt.Sprintf("'%s' is now live and open for bids.", auctionTitle)
+	if err := s.notifyUser(ctx, sellerID, auctionID, "auction_live", title, body, map[string]interface{}{
+		"auction_id": auctionID,
+	}); err != nil {
+		return fmt.Errorf("failed to create auction live notification: %w", err)
+	}
+	s.sendPush(sellerID, title, body, map[string]string{
+		"type":       "auction_live",
+		"auction_id": auctionID.String(),
+		"route":      "/auction/" + auctionID.String(),
+	})
+
+	rows, err := s.db.Pool.Query(ctx, `
+		SELECT sf.user_id, st.store_name, u.fcm_token
+		FROM store_followers sf
+		JOIN stores st ON st.id = sf.store_id
+		JOIN users u ON u.id = sf.user_id
+		WHERE st.user_id = $1 AND sf.user_id != $1
+	`, sellerID)
+	if err != nil {
+		return fmt.Errorf("failed to load followers: %w", err)
+	}
+
+	type follower struct {
+		userID   uuid.UUID
+		fcmToken *string
+	}
+	var followers []follower
+	var storeName string
+	for rows.Next() {
+		var f follower
+		if err := rows.Scan(&f.userID, &storeName, &f.fcmToken); err != nil {
+			continue
+		}
+		followers = append(followers, f)
+	}
+	rows.Close()
+
+	if len(followers) == 0 {
+		return nil
+	}
+
+	followerTitle := fmt.Sprintf("🔔 New from %s", storeName)
+	followerBody := fmt.Sprintf("'%s' just went live. Place your bid!", auctionTitle)
+	var tokens []string
+	for _, f := range followers {
+		if err := s.notifyUser(ctx, f.userID, auctionID, string(models.NotificationNewAuction), followerTitle, followerBody, map[string]interface{}{
+			"auction_id": auctionID,
+			"seller_id":  sellerID,
+		}); err != nil {
+			log.Printf("Failed to notify follower %s: %v", f.userID, err)
+		}
+		if f.fcmToken != nil && *f.fcmToken != "" {
+			tokens = append(tokens, *f.fcmToken)
+		}
+	}
+
+	if len(tokens) == 0 {
+		return nil
+	}
+	go func() {
+		err := s.fcmService.SendToMultipleDevices(tokens, followerTitle, followerBody, map[string]string{
+			"type":       string(models.NotificationNewAuction),
+			"auction_id": auctionID.String(),
+			"route":      "/auction/" + auctionID.String(),
+		})
+		if err != nil {
+			log.Printf("Failed to send follower push notifications: %v", err)
+		}
+	}()
+
+	log.Printf("✅ Sent 'auction live' notifications for auction %s to seller and %d followers", auctionID, len(followers))
+	return nil
+}
+
+// SendAuctionQueuedNotification tells the seller their scheduled auction is waiting for a free slot
+func (s *NotificationService) SendAuctionQueuedNotification(ctx context.Context, sellerID, auctionID uuid.UUID, auctionTitle string) error {
+	title := "⏳ Auction Queued"
+	body := fmt.Sprintf("'%s' was due to start, but its category is full. It will go live automatically when a slot opens.", auctionTitle)
+	if err := s.notifyUser(ctx, sellerID, auctionID, "auction_queued", title, body, map[string]interface{}{
+		"auction_id": auctionID,
+	}); err != nil {
+		return fmt.Errorf("failed to create auction queued notification: %w", err)
+	}
+	return nil
+}
+
+// SendSlotAvailableNotification offers a waiting-list member a slot they must use before expiresAt
+func (s *NotificationService) SendSlotAvailableNotification(ctx context.Context, userID, entryID, categoryID uuid.UUID, categoryName string, expiresAt time.Time) error {
+	title := "🎟️ Your Slot Is Ready"
+	body := fmt.Sprintf("A slot opened up in %s. Create your auction before %s to claim it.",
+		categoryName, expiresAt.Format("2 Jan 15:04"))
+	err := s.notifyUser(ctx, userID, uuid.Nil, string(models.NotificationSlotAvailable), title, body, map[string]interface{}{
+		"waiting_list_id": entryID,
+		"category_id":     categoryID,
+		"expires_at":      expiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create slot available notification: %w", err)
+	}
+	s.sendPush(userID, title, body, map[string]string{
+		"type":            string(models.NotificationSlotAvailable),
+		"waiting_list_id": entryID.String(),
+		"category_id":     categoryID.String(),
+		"route":           "/create-auction?category_id=" + categoryID.String(),
+	})
+	return nil
+}
+
+// SendSlotExpiredNotification tells a waiting-list member their slot offer lapsed
+func (s *NotificationService) SendSlotExpiredNotification(ctx context.Context, userID, entryID, categoryID uuid.UUID, categoryName string) error {
+	title := "⌛ Slot Offer Expired"
+	body := fmt.Sprintf("Your slot in %s was not used in time and has passed to the next seller. You can join the waiting list again.", categoryName)
+	err := s.notifyUser(ctx, userID, uuid.Nil, "slot_expired", title, body, map[string]interface{}{
+		"waiting_list_id": entryID,
+		"category_id":     categoryID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create slot expired notification: %w", err)
+	}
+	return nil
+}
+
+// SendSlotPurchaseRefundedNotification tells a seller their skip-queue or extra-slot
+// purchase was refunded because it could not be used
+func (s *NotificationService) SendSlotPurchaseRefundedNotification(ctx context.Context, userID, purchaseID, categoryID uuid.UUID, categoryName string, amount float64) error {
+	title := "💸 Slot Purchase Refunded"
+	body := fmt.Sprintf("Your slot purchase in %s could not be used, so R%.2f has been refunded.", categoryName, amount)
+	err := s.notifyUser(ctx, userID, uuid.Nil, "slot_purchase_refunded", title, body, map[string]interface{}{
+		"purchase_id": purchaseID,
+		"category_id": categoryID,
+		"amount":      amount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create slot purchase refunded notification: %w", err)
+	}
+	return nil
+}
+
+// SendPaymentReminderNotification reminds a buyer to pay for an order before its deadline
+func (s *NotificationService) SendPaymentReminderNotification(ctx context.Context, buyerID, orderID, auctionID uuid.UUID, auctionTitle string, amount float64, dueAt time.Time) error {
+	title := "💳 Payment Reminder"
+	body := fmt.Sprintf("Please pay R%.2f for '%s' before %s, or the item will go to the next bidder.",
+		amount, auctionTitle, dueAt.Format("2 Jan 15:04"))
+	err := s.notifyUser(ctx, buyerID, auctionID, string(models.NotificationPaymentReminder), title, body, map[string]interface{}{
+		"order_id":       orderID,
+		"auction_id":     auctionID,
+		"amount":         amount,
+		"payment_due_at": dueAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create payment reminder notification: %w", err)
+	}
+	s.sendPush(buyerID, title, body, map[string]string{
+		"type":     string(models.NotificationPaymentReminder),
+		"order_id": orderID.String(),
+		"route":    "/orders/" + orderID.String(),
+	})
+	return nil
+}
+
+// SendOrderPaidNotification tells the seller the buyer paid and the money is held for them
+func (s *NotificationService) SendOrderPaidNotification(ctx context.Context, sellerID, orderID, auctionID uuid.UUID, auctionTitle string, amount float64) error {
+	title := "💰 Buyer Has Paid"
+	body := fmt.Sprintf("The buyer paid R%.2f for '%s'. We hold the money until they receive the item, so dispatch it or arrange collection.",
+		amount, auctionTitle)
+	err := s.notifyUser(ctx, sellerID, auctionID, "order_paid", title, body, map[string]interface{}{
+		"order_id":   orderID,
+		"auction_id": auctionID,
+		"amount":     amount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create order paid notification: %w", err)
+	}
+	s.sendPush(sellerID, title, body, map[string]string{
+		"type":     "order_paid",
+		"order_id": orderID.String(),
+		"route":    "/orders/" + orderID.String(),
+	})
+	return nil
+}
+
+// SendOrderFulfilledNotification tells the buyer the seller dispatched or handed over
+// their item, and when the payment is released if they don't confirm receipt
+func (s *NotificationService) SendOrderFulfilledNotification(ctx context.Context, buyerID uuid.UUID, order *models.Order, auctionTitle string) error {
+	var notificationType, title, body string
+	switch order.Status {
+	case models.OrderStatusDispatched:
+		notificationType, title = "order_dispatched", "📦 Item Dispatched"
+		body = fmt.Sprintf("'%s' is on its way.", auctionTitle)
+		if order.TrackingNumber != nil {
+			body = fmt.Sprintf("'%s' is on its way. Tracking number: %s.", auctionTitle, *order.TrackingNumber)
+		}
+	case models.OrderStatusCollected:
+		notificationType, title = "order_collected", "🤝 Item Collected"
+		body = fmt.Sprintf("The seller marked '%s' as collected.", auctionTitle)
+	default:
+		return nil
+	}
+	if order.ReleaseDueAt != nil {
+		body += fmt.Sprintf(" Confirm when you have it; payment is released to the seller on %s otherwise.",
+			order.ReleaseDueAt.Format("2 Jan"))
+	}
+
+	err := s.notifyUser(ctx, buyerID, order.AuctionID, notificationType, title, body, map[string]interface{}{
+		"order_id":        order.ID,
+		"auction_id":      order.AuctionID,
+		"tracking_number": order.TrackingNumber,
+		"courier":         order.Courier,
+		"release_due_at":  order.ReleaseDueAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create %s notification: %w", notificationType, err)
+	}
+	s.sendPush(buyerID, title, body, map[string]string{
+		"type":     notificationType,
+		"order_id": order.ID.String(),
+		"route":    "/orders/" + order.ID.String(),
+	})
+	return nil
+}
+
+// SendOrderReleasedNotification tells the seller the held payment has been released to them
+func (s *NotificationService) SendOrderReleasedNotification(ctx context.Context, sellerID, orderID, auctionID uuid.UUID, auctionTitle string, amount float64, received bool) error {
+	title := "✅ Payment Released"
+	body := fmt.Sprintf("The buyer confirmed they received '%s'. R%.2f has been released to you.", auctionTitle, amount)
+	if !received {
+		body = fmt.Sprintf("The confirmation window for '%s' has passed. R%.2f has been released to you.", auctionTitle, amount)
+	}
+	err := s.notifyUser(ctx, sellerID, auctionID, "order_released", title, body, map[string]interface{}{
+		"order_id":   orderID,
+		"auction_id": auctionID,
+		"amount":     amount,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create order released notification: %w", err)
+	}
+	return nil
+}
+
+// SendOrderCancelledNotification tells the buyer or seller that an order was cancelled
+// because the buyer did not pay in time. nextBidderOffered tells the seller whether the
+// item was offered to the next bidder.
+func (s *NotificationService) SendOrderCancelledNotification(ctx context.Context, userID, orderID, auctionID uuid.UUID, auctionTitle, role string, nextBidderOffered bool) error {
+	title := "❌ Order Cancelled"
+	var body string
+	switch {
+	case role == "buyer":
+		body = fmt.Sprintf("You did not pay for '%s' in time, so the order was cancelled.", auctionTitle)
+	case nextBidderOffered:
+		body = fmt.Sprintf("The buyer of '%s' did not pay in time. We have offered it to the next highest bidder.", auctionTitle)
+	default:
+		body = fmt.Sprintf("The buyer of '%s' did not pay in time and no other bidder can take it. You can relist it anytime.", auctionTitle)
+	}
+	err := s.notifyUser(ctx, userID, auctionID, "order_cancelled", title, body, map[string]interface{}{
+		"order_id":   orderID,
+		"auction_id": auctionID,
+		"reason":     "non_payment",
+		"role":       role,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create order cancelled notification: %w", err)
+	}
+	return nil
+}
+
+// SendUnpaidStrikeNotification tells a buyer a seller reported them for not paying, and
+// whether bidding is now restricted
+func (s *NotificationService) SendUnpaidStrikeNotification(ctx context.Context, userID, auctionID uuid.UUID, auctionTitle string, strikeCount, strikeLimit int, restricted bool) error {
+	title := "⚠️ Unpaid Item Strike"
+	body := fmt.Sprintf("The seller of '%s' reported that you did not pay for it. This is strike %d on your account.", auctionTitle, strikeCount)
+	if restricted {
+		body += " You can no longer bid until older strikes expire."
+	} else if strikeLimit > 0 {
+		body += fmt.Sprintf(" Bidding is restricted at %d strikes.", strikeLimit)
+	}
+	err := s.notifyUser(ctx, userID, auctionID, "unpaid_item_strike", title, body, map[string]interface{}{
+		"auction_id":   auctionID,
+		"strike_count": strikeCount,
+		"restricted":   restricted,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create unpaid item strike notification: %w", err)
+	}
+	s.sendPush(userID, title, body, map[string]string{
+		"type":       "unpaid_item_strike",
+		"auction_id": auctionID.String(),
+	})
+	return nil
+}
+
+// SendPromotionEndedNotification tells a seller their promotion has finished and how it did
+func (s *NotificationService) SendPromotionEndedNotification(ctx context.Context, sellerID, promotionID, auctionID uuid.UUID, auctionTitle, promotionType string, impressions, clicks int) error {
+	title := "📣 Promotion Ended"
+	body := fmt.Sprintf("Your %s promotion for '%s' has ended. It was seen %d times and opened %d times.", promotionType, auctionTitle, impressions, clicks)
+	err := s.notifyUser(ctx, sellerID, auctionID, "promotion_ended", title, body, map[string]interface{}{
+		"promotion_id":   promotionID,
+		"auction_id":     auctionID,
+		"promotion_type": promotionType,
+		"impressions":    impressions,
+		"clicks":         clicks,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create promotion ended notification: %w", err)
+	}
+	return nil
+}
+
+// SendDisputeOpenedNotification tells the other side of an order that a dispute was opened
+func (s *NotificationService) SendDisputeOpenedNotification(ctx context.Context, userID, disputeID, auctionID uuid.UUID, auctionTitle, reason string) error {
+	title := "⚖️ Dispute Opened"
+	body := fmt.Sprintf("A dispute was opened on '%s' (%s). Reply with your side and any evidence; the payment is on hold until it is resolved.",
+		auctionTitle, strings.ReplaceAll(reason, "_", " "))
+	err := s.notifyUser(ctx, userID, auctionID, "dispute_opened", title, body, map[string]interface{}{
+		"dispute_id": disputeID,
+		"auction_id": auctionID,
+		"reason":     reason,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create dispute opened notification: %w", err)
+	}
+	s.sendPush(userID, title, body, map[string]string{
+		"type":       "dispute_opened",
+		"dispute_id": disputeID.String(),
+	})
+	return nil
+}
+
+// SendDisputeMessageNotification tells a party of a dispute about a new message in its thread
+func (s *NotificationService) SendDisputeMessageNotification(ctx context.Context, userID, disputeID, auctionID uuid.UUID, auctionTitle string, fromAdmin bool) error {
+	title := "💬 New Dispute Message"
+	body := fmt.Sprintf("There is a new message in the dispute about '%s'.", auctionTitle)
+	if fromAdmin {
+		body = fmt.Sprintf("Our support team replied in the dispute about '%s'.", auctionTitle)
+	}
+	err := s.notifyUser(ctx, userID, auctionID, "dispute_message", title, body, map[string]interface{}{
+		"dispute_id": disputeID,
+		"auction_id": auctionID,
+		"from_admin": fromAdmin,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create dispute message notification: %w", err)
+	}
+	return nil
+}
+
+// SendDisputeReturnSentNotification tells the seller the buyer has sent the item back
+func (s *NotificationService) SendDisputeReturnSentNotification(ctx context.Context, sellerID, disputeID, auctionID uuid.UUID, auctionTitle string, courier, trackingNumber *string) error {
+	title := "📦 Item Returned"
+	body := fmt.Sprintf("The buyer has sent '%s' back to you. Confirm when it arrives so the dispute can be closed.", auctionTitle)
+	if trackingNumber != nil && *trackingNumber != "" {
+		body = fmt.Sprintf("The buyer has sent '%s' back to you (tracking %s). Confirm when it arrives so the dispute can be closed.", auctionTitle, *trackingNumber)
+	}
+	err := s.notifyUser(ctx, sellerID, auctionID, "dispute_return_sent", title, body, map[string]interface{}{
+		"dispute_id":      disputeID,
+		"auction_id":      auctionID,
+		"courier":         courier,
+		"tracking_number": trackingNumber,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create dispute return notification: %w", err)
+	}
+	return nil
+}
+
+// SendDisputeResolvedNotification tells the buyer or seller (role) how a dispute ended
+func (s *NotificationService) SendDisputeResolvedNotification(ctx context.Context, userID uuid.UUID, dispute *models.Dispute, auctionTitle, role string) error {
+	title := "⚖️ Dispute Resolved"
+	var body string
+	outcome := ""
+	if dispute.Outcome != nil {
+		outcome = *dispute.Outcome
+	}
+	switch {
+	case dispute.Status == models.DisputeStatusWithdrawn:
+		title = "⚖️ Dispute Withdrawn"
+		body = fmt.Sprintf("The dispute about '%s' was withdrawn and the order continues as normal.", auctionTitle)
+	case dispute.Status == models.DisputeStatusAwaitingReturn && role == "buyer":
+		body = fmt.Sprintf("Please return '%s' to the seller. You will be refunded once they confirm it arrived.", auctionTitle)
+	case dispute.Status == models.DisputeStatusAwaitingReturn:
+		body = fmt.Sprintf("The buyer will return '%s' to you. Please confirm when it arrives; they are refunded then.", auctionTitle)
+	case outcome == models.DisputeOutcomeNoRefund:
+		body = fmt.Sprintf("The dispute about '%s' was closed without a refund. The payment has been released to the seller.", auctionTitle)
+	case outcome == models.DisputeOutcomePartialRefund && dispute.RefundAmount != nil:
+		body = fmt.Sprintf("The dispute about '%s' was resolved with a partial refund of R%.2f to the buyer. The rest has been released to the seller.", auctionTitle, *dispute.RefundAmount)
+	default:
+		body = fmt.Sprintf("The dispute about '%s' was resolved with a full refund to the buyer.", auctionTitle)
+	}
+	err := s.notifyUser(ctx, userID, dispute.AuctionID, "dispute_resolved", title, body, map[string]interface{}{
+		"dispute_id": dispute.ID,
+		"auction_id": dispute.AuctionID,
+		"status":     dispute.Status,
+		"outcome":    outcome,
+		"role":       role,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create dispute resolved notification: %w", err)
+	}
+	s.sendPush(userID, title, body, map[string]string{
+		"type":       "dispute_resolved",
+		"dispute_id": dispute.ID.String(),
+	})
+	return nil
+}
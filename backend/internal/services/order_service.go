@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Defaults for the order settings in app_settings
+const (
+	defaultOrderPaymentDueHours      = 48
+	defaultOrderPaymentReminderHours = 12
+	defaultOrderReleaseDays          = 7
+)
+
+// OrderService opens orders for sold auctions, collects the buyer's payment and
+// releases it to the seller once the item has arrived
+type OrderService struct {
+	db              *database.DB
+	payments        *PaymentService
+	notificationSvc *NotificationService
+}
+
+func NewOrderService(db *database.DB, paymentSvc *PaymentService, notificationSvc *NotificationService) *OrderService {
+	return &OrderService{db: db, payments: paymentSvc, notificationSvc: notificationSvc}
+}
+
+// Create opens an order awaiting the buyer's payment. Call it inside the transaction
+// that sells the auction so a sale never exists without its order.
+func (s *OrderService) Create(ctx context.Context, tx pgx.Tx, auctionID, buyerID, sellerID uuid.UUID, amount float64, source string) (uuid.UUID, error) {
+	dueHours := intSetting(ctx, s.db, "order_payment_due_hours", defaultOrderPaymentDueHours)
+
+	var orderID uuid.UUID
+	err := tx.QueryRow(ctx, `
+		INSERT INTO orders (auction_id, buyer_id, seller_id, amount, source, payment_due_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id`,
+		auctionID, buyerID, sellerID, amount, source, time.Now().Add(time.Duration(dueHours)*time.Hour),
+	).Scan(&orderID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to create order for auction %s: %w", auctionID, err)
+	}
+	return orderID, nil
+}
+
+// Pay starts the buyer's payment for an order that awaits it. A payment that was
+// started earlier and not completed yet is returned instead of a new one.
+func (s *OrderService) Pay(ctx context.Context, order *models.Order, auctionTitle string) (*models.Payment, error) {
+	if order.PaymentID != nil {
+		var payment models.Payment
+		err := s.db.Pool.QueryRow(ctx,
+			"SELECT "+paymentColumns+" FROM payments WHERE id = $1 AND status = 'pending'",
+			*order.PaymentID,
+		).Scan(paymentScanArgs(&payment)...)
+		if err == nil {
+			return &payment, nil
+		}
+	}
+
+	payment, err := s.payments.Start(ctx, order.BuyerID, models.PaymentPurposeOrder, order.ID, order.Amount,
+		fmt.Sprintf("Order for '%s'", auctionTitle))
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.db.Pool.Exec(ctx,
+		"UPDATE orders SET payment_id = $1, updated_at = NOW() WHERE id = $2 AND status = 'awaiting_payment'",
+		payment.ID, order.ID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to attach payment to order %s: %w", order.ID, err)
+	}
+	order.PaymentID = &payment.ID
+	return payment, nil
+}
+
+// ReleaseDue is when held money goes to the seller if the buyer has not confirmed
+// receipt of an item dispatched or collected now
+func (s *OrderService) ReleaseDue(ctx context.Context) time.Time {
+	days := intSetting(ctx, s.db, "order_release_days", defaultOrderReleaseDays)
+	return time.Now().AddDate(0, 0, days)
+}
+
+// ReminderLead is how long before the payment deadline the buyer is reminded
+func (s *OrderService) ReminderLead(ctx context.Context) time.Duration {
+	hours := intSetting(ctx, s.db, "order_payment_reminder_hours", defaultOrderPaymentReminderHours)
+	return time.Duration(hours) * time.Hour
+}
+
+// Release completes a paid order and releases the held money to the seller. received
+// is true when the buyer confirmed receipt, false when the release window ran out.
+// It returns false when the order is not in a releasable state.
+func (s *OrderService) Release(ctx context.Context, orderID uuid.UUID, received bool) (bool, error) {
+	var buyerID, sellerID, auctionID uuid.UUID
+	var amount float64
+	err := s.db.Pool.QueryRow(ctx, `
+		UPDATE orders o
+		SET status = 'completed',
+			received_at = CASE WHEN $2 THEN NOW() ELSE o.received_at END,
+			released_at = NOW(),
+			updated_at = NOW()
+		WHERE o.id = $1 AND o.status IN ('paid', 'dispatched', 'collected')
+		AND ($2 OR o.release_due_at <= NOW())
+		RETURNING o.buyer_id, o.seller_id, o.auction_id, o.amount`,
+		orderID, received,
+	).Scan(&buyerID, &sellerID, &auctionID, &amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to release order %s: %w", orderID, err)
+	}
+
+	if err := recordTransaction(ctx, s.db, buyerID, sellerID, ""); err != nil {
+		log.Printf("Error recording transaction for order %s: %v", orderID, err)
+	}
+
+	var title string
+	s.db.Pool.QueryRow(ctx, "SELECT title FROM auctions WHERE id = $1", auctionID).Scan(&title)
+	s.notificationSvc.SendOrderReleasedNotification(ctx, sellerID, orderID, auctionID, title, amount, received)
+	return true, nil
+}
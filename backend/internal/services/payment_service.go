@@ -0,0 +1,400 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/payments"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ErrPaymentNotFound is returned for webhooks about intents missing from the ledger
+var ErrPaymentNotFound = errors.New("payment not found")
+
+const paymentColumns = `id, user_id, purpose, reference_id, provider, provider_intent_id, amount, currency, status,
+	refunded_amount, description, failure_reason, confirmed_at, refunded_at, created_at, updated_at`
+
+func paymentScanArgs(p *models.Payment) []interface{} {
+	return []interface{}{&p.ID, &p.UserID, &p.Purpose, &p.ReferenceID, &p.Provider, &p.ProviderIntentID,
+		&p.Amount, &p.Currency, &p.Status, &p.RefundedAmount, &p.Description, &p.FailureReason,
+		&p.ConfirmedAt, &p.RefundedAt, &p.CreatedAt, &p.UpdatedAt}
+}
+
+// PaymentService records payments in the ledger and applies what they pay for once
+// the provider confirms them
+type PaymentService struct {
+	db              *database.DB
+	provider        payments.Provider
+	notificationSvc *NotificationService
+}
+
+func NewPaymentService(db *database.DB, provider payments.Provider, notificationSvc *NotificationService) *PaymentService {
+	return &PaymentService{db: db, provider: provider, notificationSvc: notificationSvc}
+}
+
+// Provider returns the payment provider behind the ledger
+func (s *PaymentService) Provider() payments.Provider {
+	return s.provider
+}
+
+// Start creates an intent for the client to confirm and records it as pending. What
+// the payment is for takes effect when the provider's webhook confirms it.
+func (s *PaymentService) Start(ctx context.Context, userID uuid.UUID, purpose string, referenceID uuid.UUID, amount float64, description string) (*models.Payment, error) {
+	intent, err := s.provider.CreateIntent(ctx, payments.IntentRequest{
+		Amount:      amount,
+		Description: description,
+		Metadata: map[string]string{
+			"purpose":      purpose,
+			"reference_id": referenceID.String(),
+			"user_id":      userID.String(),
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment intent: %w", err)
+	}
+
+	var payment models.Payment
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO payments (user_id, purpose, reference_id, provider, provider_intent_id, amount, currency, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+paymentColumns,
+		userID, purpose, referenceID, s.provider.Name(), intent.ID, amount, intent.Currency, description,
+	).Scan(paymentScanArgs(&payment)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+	return &payment, nil
+}
+
+// Charge collects a payment in one step, for fees the server confirms itself.
+// A declined payment is recorded as failed and returns payments.ErrPaymentDeclined.
+func (s *PaymentService) Charge(ctx context.Context, userID uuid.UUID, purpose string, referenceID uuid.UUID, amount float64, description string) (*models.Payment, error) {
+	payment, err := s.Start(ctx, userID, purpose, referenceID, amount, description)
+	if err != nil {
+		return nil, err
+	}
+
+	intent, err := s.provider.Confirm(ctx, payment.ProviderIntentID)
+	if err != nil {
+		return payment, fmt.Errorf("failed to confirm payment %s: %w", payment.ID, err)
+	}
+
+	status := models.PaymentStatusSucceeded
+	if intent.Status != payments.IntentSucceeded {
+		status = models.PaymentStatusFailed
+	}
+	if err := s.transition(ctx, payment, status, intent.RefundedAmount, "declined", nil); err != nil {
+		return payment, err
+	}
+	if status == models.PaymentStatusFailed {
+		return payment, payments.ErrPaymentDeclined
+	}
+	return payment, nil
+}
+
+// Refund returns amount of a successful payment (the whole remaining amount when
+// amount is 0) and undoes what it paid for
+func (s *PaymentService) Refund(ctx context.Context, paymentID uuid.UUID, amount float64, reason string) error {
+	var payment models.Payment
+	err := s.db.Pool.QueryRow(ctx, "SELECT "+paymentColumns+" FROM payments WHERE id = $1", paymentID).
+		Scan(paymentScanArgs(&payment)...)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPaymentNotFound, paymentID)
+	}
+	if payment.Status != models.PaymentStatusSucceeded && payment.Status != models.PaymentStatusPartiallyRefunded {
+		return fmt.Errorf("payment %s is %s and cannot be refunded", paymentID, payment.Status)
+	}
+
+	remaining := math.Round((payment.Amount-payment.RefundedAmount)*100) / 100
+	if amount <= 0 {
+		amount = remaining
+	}
+	if amount > remaining {
+		return fmt.Errorf("refund of %.2f exceeds the %.2f left on payment %s", amount, remaining, paymentID)
+	}
+
+	if _, err := s.provider.Refund(ctx, payment.ProviderIntentID, amount); err != nil {
+		return fmt.Errorf("failed to refund payment %s: %w", paymentID, err)
+	}
+
+	refunded := payment.RefundedAmount + amount
+	log.Printf("💸 Refunded %.2f of payment %s (%s)", amount, paymentID, reason)
+	return s.transition(ctx, &payment, refundStatus(payment.Amount, refunded), refunded, reason, nil)
+}
+
+// refundStatus is the status of a payment of amount once refunded has been returned
+func refundStatus(amount, refunded float64) models.PaymentStatus {
+	if refunded >= amount-0.001 {
+		return models.PaymentStatusRefunded
+	}
+	return models.PaymentStatusPartiallyRefunded
+}
+
+// HandleWebhook verifies and applies a provider webhook. Events that were already
+// applied are ignored, so providers can safely retry deliveries; an event whose
+// transition fails is not recorded, so its retry is applied again.
+func (s *PaymentService) HandleWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.provider.VerifyWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	var payment models.Payment
+	err = s.db.Pool.QueryRow(ctx,
+		"SELECT "+paymentColumns+" FROM payments WHERE provider = $1 AND provider_intent_id = $2",
+		s.provider.Name(), event.Intent.ID,
+	).Scan(paymentScanArgs(&payment)...)
+	if err != nil {
+		return fmt.Errorf("%w: intent %s", ErrPaymentNotFound, event.Intent.ID)
+	}
+
+	switch event.Type {
+	case payments.EventPaymentSucceeded:
+		return s.transition(ctx, &payment, models.PaymentStatusSucceeded, event.Intent.RefundedAmount, "", event)
+	case payments.EventPaymentFailed:
+		return s.transition(ctx, &payment, models.PaymentStatusFailed, 0, "declined", event)
+	case payments.EventPaymentRefunded:
+		status := refundStatus(payment.Amount, event.Intent.RefundedAmount)
+		return s.transition(ctx, &payment, status, event.Intent.RefundedAmount, "refunded by provider", event)
+	default:
+		log.Printf("Ignoring payment webhook %s of type %s", event.ID, event.Type)
+		return nil
+	}
+}
+
+// transition moves a payment to a new status and applies the effect on what it pays
+// for, in one transaction. Moves that don't make sense from the current status (a late
+// "failed" after success, a repeated success) are ignored. When event is set it is
+// recorded in the same transaction, and an event that was already recorded is a no-op.
+func (s *PaymentService) transition(ctx context.Context, payment *models.Payment, status models.PaymentStatus, refundedAmount float64, reason string, event *payments.WebhookEvent) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if event != nil {
+		result, err := tx.Exec(ctx, `
+			INSERT INTO payment_webhook_events (provider, event_id, event_type, payment_id)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (provider, event_id) DO NOTHING`,
+			s.provider.Name(), event.ID, event.Type, payment.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to record webhook event: %w", err)
+		}
+		if result.RowsAffected() == 0 {
+			return nil
+		}
+	}
+
+	var current models.PaymentStatus
+	if err := tx.QueryRow(ctx, "SELECT status FROM payments WHERE id = $1 FOR UPDATE", payment.ID).Scan(&current); err != nil {
+		return fmt.Errorf("failed to lock payment %s: %w", payment.ID, err)
+	}
+
+	allowed := false
+	switch status {
+	case models.PaymentStatusSucceeded, models.PaymentStatusFailed:
+		allowed = current == models.PaymentStatusPending
+	case models.PaymentStatusPartiallyRefunded, models.PaymentStatusRefunded:
+		allowed = current == models.PaymentStatusSucceeded || current == models.PaymentStatusPartiallyRefunded
+	}
+	if !allowed {
+		payment.Status = current
+		if event != nil {
+			if err := tx.Commit(ctx); err != nil {
+				return fmt.Errorf("failed to record webhook event: %w", err)
+			}
+		}
+		return nil
+	}
+
+	var failureReason *string
+	if status == models.PaymentStatusFailed && reason != "" {
+		failureReason = &reason
+	}
+	err = tx.QueryRow(ctx, `
+		UPDATE payments SET
+			status = $1,
+			refunded_amount = $2,
+			failure_reason = COALESCE($3, failure_reason),
+			confirmed_at = CASE WHEN $1 = 'succeeded' THEN NOW() ELSE confirmed_at END,
+			refunded_at = CASE WHEN $1 IN ('refunded', 'partially_refunded') THEN NOW() ELSE refunded_at END,
+			updated_at = NOW()
+		WHERE id = $4
+		RETURNING `+paymentColumns,
+		string(status), refundedAmount, failureReason, payment.ID,
+	).Scan(paymentScanArgs(payment)...)
+	if err != nil {
+		return fmt.Errorf("failed to update payment %s: %w", payment.ID, err)
+	}
+
+	refundNeeded, err := s.applyEffect(ctx, tx, payment)
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit payment %s: %w", payment.ID, err)
+	}
+
+	// Paid for something that can no longer be delivered
+	if refundNeeded {
+		if err := s.Refund(ctx, payment.ID, 0, "could not be fulfilled"); err != nil {
+			log.Printf("Error refunding unfulfilled payment %s: %v", payment.ID, err)
+		}
+		return nil
+	}
+
+	if payment.Purpose == models.PaymentPurposeOrder && payment.Status == models.PaymentStatusSucceeded {
+		s.notifyOrderPaid(ctx, *payment.ReferenceID)
+	}
+	return nil
+}
+
+// applyEffect updates what a payment pays for after its status changed. It reports
+// whether a successful payment could not be fulfilled and must be refunded.
+func (s *PaymentService) applyEffect(ctx context.Context, tx pgx.Tx, payment *models.Payment) (bool, error) {
+	if payment.ReferenceID == nil {
+		return false, nil
+	}
+	switch payment.Purpose {
+	case models.PaymentPurposePromotion:
+		return s.applyPromotionEffect(ctx, tx, payment, *payment.ReferenceID)
+	case models.PaymentPurposeOrder:
+		return s.applyOrderEffect(ctx, tx, payment, *payment.ReferenceID)
+	}
+	// Slot purchases are charged synchronously and settled by SlotPurchaseService
+	return false, nil
+}
+
+// applyPromotionEffect switches a promotion on once paid and off when its payment fails
+// or is refunded
+func (s *PaymentService) applyPromotionEffect(ctx context.Context, tx pgx.Tx, payment *models.Payment, promotionID uuid.UUID) (bool, error) {
+	switch payment.Status {
+	case models.PaymentStatusSucceeded:
+		// The promotion runs for its full length from the moment it is paid
+		var auctionID uuid.UUID
+		var promotionType string
+		err := tx.QueryRow(ctx, `
+			UPDATE promoted_auctions pa
+			SET payment_status = 'paid', is_active = true, ends_at = NOW() + (pa.ends_at - pa.starts_at), starts_at = NOW()
+			FROM auctions a
+			WHERE pa.id = $1 AND pa.payment_status = 'pending'
+			AND a.id = pa.auction_id AND a.status IN ('active', 'ending_soon')
+			RETURNING pa.auction_id, pa.promotion_type`,
+			promotionID,
+		).Scan(&auctionID, &promotionType)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The auction ended or the promotion was withdrawn while the buyer paid
+			_, err = tx.Exec(ctx,
+				"UPDATE promoted_auctions SET payment_status = 'refunded', is_active = false WHERE id = $1",
+				promotionID,
+			)
+			if err != nil {
+				return false, fmt.Errorf("failed to cancel promotion %s: %w", promotionID, err)
+			}
+			return true, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to activate promotion %s: %w", promotionID, err)
+		}
+		if promotionType == "featured" || promotionType == "pinned" {
+			if _, err := tx.Exec(ctx, "UPDATE auctions SET is_featured = true WHERE id = $1", auctionID); err != nil {
+				return false, fmt.Errorf("failed to feature auction %s: %w", auctionID, err)
+			}
+		}
+
+	case models.PaymentStatusFailed:
+		if _, err := tx.Exec(ctx,
+			"UPDATE promoted_auctions SET payment_status = 'failed', is_active = false WHERE id = $1 AND payment_status = 'pending'",
+			promotionID,
+		); err != nil {
+			return false, fmt.Errorf("failed to mark promotion %s failed: %w", promotionID, err)
+		}
+
+	case models.PaymentStatusRefunded:
+		var auctionID uuid.UUID
+		err := tx.QueryRow(ctx,
+			`UPDATE promoted_auctions
+			SET payment_status = 'refunded', ended_at = CASE WHEN is_active THEN NOW() ELSE ended_at END, is_active = false
+			WHERE id = $1 RETURNING auction_id`,
+			promotionID,
+		).Scan(&auctionID)
+		if err != nil {
+			return false, fmt.Errorf("failed to withdraw promotion %s: %w", promotionID, err)
+		}
+		if _, err := tx.Exec(ctx, `
+			UPDATE auctions SET is_featured = EXISTS(
+				SELECT 1 FROM promoted_auctions
+				WHERE auction_id = $1 AND is_active = true AND ends_at > NOW() AND promotion_type IN ('featured', 'pinned')
+			)
+			WHERE id = $1`,
+			auctionID,
+		); err != nil {
+			return false, fmt.Errorf("failed to update featured flag of auction %s: %w", auctionID, err)
+		}
+	}
+	return false, nil
+}
+
+// applyOrderEffect holds a successful payment against its order. A payment that arrives
+// after the order was cancelled or already paid must be refunded.
+func (s *PaymentService) applyOrderEffect(ctx context.Context, tx pgx.Tx, payment *models.Payment, orderID uuid.UUID) (bool, error) {
+	switch payment.Status {
+	case models.PaymentStatusSucceeded:
+		result, err := tx.Exec(ctx, `
+			UPDATE orders SET status = 'paid', paid_at = NOW(), payment_id = $2, updated_at = NOW()
+			WHERE id = $1 AND status = 'awaiting_payment'`,
+			orderID, payment.ID,
+		)
+		if err != nil {
+			return false, fmt.Errorf("failed to mark order %s paid: %w", orderID, err)
+		}
+		return result.RowsAffected() == 0, nil
+
+	case models.PaymentStatusFailed:
+		// Let the buyer start a fresh payment
+		if _, err := tx.Exec(ctx,
+			"UPDATE orders SET payment_id = NULL, updated_at = NOW() WHERE id = $1 AND payment_id = $2 AND status = 'awaiting_payment'",
+			orderID, payment.ID,
+		); err != nil {
+			return false, fmt.Errorf("failed to detach payment from order %s: %w", orderID, err)
+		}
+
+	case models.PaymentStatusRefunded:
+		// Money released to the seller is not clawed back here
+		if _, err := tx.Exec(ctx, `
+			UPDATE orders SET status = 'refunded', updated_at = NOW()
+			WHERE id = $1 AND payment_id = $2 AND status IN ('paid', 'dispatched', 'collected', 'disputed')`,
+			orderID, payment.ID,
+		); err != nil {
+			return false, fmt.Errorf("failed to mark order %s refunded: %w", orderID, err)
+		}
+	}
+	return false, nil
+}
+
+// notifyOrderPaid tells the seller the buyer's money is held and the item can go out
+func (s *PaymentService) notifyOrderPaid(ctx context.Context, orderID uuid.UUID) {
+	var sellerID, auctionID uuid.UUID
+	var title string
+	var amount float64
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT o.seller_id, o.auction_id, a.title, o.amount
+		FROM orders o JOIN auctions a ON a.id = o.auction_id
+		WHERE o.id = $1`,
+		orderID,
+	).Scan(&sellerID, &auctionID, &title, &amount)
+	if err != nil {
+		log.Printf("Error loading paid order %s: %v", orderID, err)
+		return
+	}
+	s.notificationSvc.SendOrderPaidNotification(ctx, sellerID, orderID, auctionID, title, amount)
+}
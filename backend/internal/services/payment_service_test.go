@@ -0,0 +1,29 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/airmass/backend/internal/models"
+)
+
+func TestRefundStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   float64
+		refunded float64
+		want     models.PaymentStatus
+	}{
+		{"part of the payment", 100, 40, models.PaymentStatusPartiallyRefunded},
+		{"all but a cent", 100, 99.99, models.PaymentStatusPartiallyRefunded},
+		{"the whole payment", 100, 100, models.PaymentStatusRefunded},
+		{"float rounding on the whole payment", 0.3, 0.1 + 0.2, models.PaymentStatusRefunded},
+		{"whole payment in instalments", 30, 10 + 20, models.PaymentStatusRefunded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := refundStatus(tt.amount, tt.refunded); got != tt.want {
+				t.Errorf("refundStatus(%.2f, %.2f) = %s, want %s", tt.amount, tt.refunded, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/google/uuid"
+)
+
+// recordTransaction counts a finished order in the buyer's and the seller's
+// transaction stats. atFault names the side a dispute went against ("buyer",
+// "seller"), which counts as disputed instead of successful for that user.
+func recordTransaction(ctx context.Context, db *database.DB, buyerID, sellerID uuid.UUID, atFault string) error {
+	for role, userID := range map[string]uuid.UUID{"buyer": buyerID, "seller": sellerID} {
+		lost := role == atFault
+		if _, err := db.Pool.Exec(ctx, `
+			UPDATE users SET
+				total_transactions = COALESCE(total_transactions, 0) + 1,
+				successful_transactions = COALESCE(successful_transactions, 0) + CASE WHEN $2 THEN 0 ELSE 1 END,
+				disputed_transactions = COALESCE(disputed_transactions, 0) + CASE WHEN $2 THEN 1 ELSE 0 END
+			WHERE id = $1`,
+			userID, lost,
+		); err != nil {
+			return fmt.Errorf("failed to record transaction for user %s: %w", userID, err)
+		}
+	}
+	return nil
+}
+
+// recordLostDispute turns a transaction that was already counted as successful into a
+// disputed one, for disputes opened after the order completed
+func recordLostDispute(ctx context.Context, db *database.DB, userID uuid.UUID) error {
+	if _, err := db.Pool.Exec(ctx, `
+		UPDATE users SET
+			successful_transactions = GREATEST(COALESCE(successful_transactions, 0) - 1, 0),
+			disputed_transactions = COALESCE(disputed_transactions, 0) + 1
+		WHERE id = $1`,
+		userID,
+	); err != nil {
+		return fmt.Errorf("failed to record lost dispute for user %s: %w", userID, err)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/pkg/jwt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// refreshTokenReuseGrace is how long a rotated refresh token can still be presented
+// without revoking its session, so a client refreshing twice at once is not signed out
+const refreshTokenReuseGrace = "10 seconds"
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reused")
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrAccessCheckFailed   = errors.New("could not verify token access")
+)
+
+// SessionService manages device sessions and their rotating refresh tokens
+type SessionService struct {
+	db      *database.DB
+	ttlDays int
+}
+
+// NewSessionService creates a session service whose refresh tokens last ttlDays
+// from their last use
+func NewSessionService(db *database.DB, ttlDays int) *SessionService {
+	return &SessionService{db: db, ttlDays: ttlDays}
+}
+
+// newRefreshToken returns an opaque refresh token and the hash stored for it
+func newRefreshToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+const sessionColumns = `
+	s.id, s.user_id, s.device_name, s.device_id, host(s.ip_address), s.user_agent,
+	s.created_at, s.last_seen_at, s.expires_at`
+
+func sessionScanArgs(s *models.UserSession) []interface{} {
+	return []interface{}{
+		&s.ID, &s.UserID, &s.DeviceName, &s.DeviceID, &s.IPAddress, &s.UserAgent,
+		&s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt,
+	}
+}
+
+// Create opens a session for a new sign-in and returns it with its refresh token
+func (s *SessionService) Create(ctx context.Context, userID uuid.UUID, deviceName string, client models.ClientInfo) (*models.UserSession, string, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var session models.UserSession
+	err = s.db.Pool.QueryRow(ctx, fmt.Sprintf(`
+		INSERT INTO user_sessions AS s (user_id, refresh_token_hash, device_name, device_id, ip_address, user_agent, expires_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, '')::inet, NULLIF($6, ''), NOW() + make_interval(days => $7))
+		RETURNING %s`, sessionColumns),
+		userID, hash, deviceName, client.DeviceID, client.IPAddress, client.UserAgent, s.ttlDays,
+	).Scan(sessionScanArgs(&session)...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create session: %w", err)
+	}
+	return &session, token, nil
+}
+
+// Rotate exchanges a refresh token for a new one and extends the session. Presenting
+// a token that was already rotated revokes the session, since someone else holds it.
+func (s *SessionService) Rotate(ctx context.Context, refreshToken string, client models.ClientInfo) (*models.UserSession, string, error) {
+	hash := hashRefreshToken(refreshToken)
+	token, newHash, err := newRefreshToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var session models.UserSession
+	var usable bool
+	err = tx.QueryRow(ctx, fmt.Sprintf(`
+		SELECT %s, s.revoked_at IS NULL AND s.expires_at > NOW() AND u.is_active
+		FROM user_sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.refresh_token_hash = $1
+		FOR UPDATE OF s`, sessionColumns),
+		hash,
+	).Scan(append(sessionScanArgs(&session), &usable)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		tx.Rollback(ctx)
+		return nil, "", s.checkReuse(ctx, hash)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch session: %w", err)
+	}
+	if !usable {
+		return nil, "", ErrInvalidRefreshToken
+	}
+
+	if _, err := tx.Exec(ctx,
+		"INSERT INTO user_session_retired_tokens (token_hash, session_id) VALUES ($1, $2)",
+		hash, session.ID,
+	); err != nil {
+		return nil, "", fmt.Errorf("failed to retire refresh token: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, fmt.Sprintf(`
+		UPDATE user_sessions s
+		SET refresh_token_hash = $2, last_seen_at = NOW(),
+			ip_address = COALESCE(NULLIF($3, '')::inet, s.ip_address),
+			user_agent = COALESCE(NULLIF($4, ''), s.user_agent),
+			expires_at = NOW() + make_interval(days => $5)
+		WHERE s.id = $1
+		RETURNING %s`, sessionColumns),
+		session.ID, newHash, client.IPAddress, client.UserAgent, s.ttlDays,
+	).Scan(sessionScanArgs(&session)...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, "", fmt.Errorf("failed to commit refresh: %w", err)
+	}
+	return &session, token, nil
+}
+
+// checkReuse handles a refresh token that is not any session's current token. A
+// retired one means the token was copied, and whoever holds it may have refreshed on
+// other sessions or kept access tokens, so the user is signed out everywhere.
+func (s *SessionService) checkReuse(ctx context.Context, hash string) error {
+	var sessionID, userID uuid.UUID
+	var inGrace bool
+	err := s.db.Pool.QueryRow(ctx, `
+		SELECT t.session_id, s.user_id, t.retired_at > NOW() - $2::interval
+		FROM user_session_retired_tokens t
+		JOIN user_sessions s ON s.id = t.session_id
+		WHERE t.token_hash = $1`,
+		hash, refreshTokenReuseGrace,
+	).Scan(&sessionID, &userID, &inGrace)
+	if err != nil || inGrace {
+		return ErrInvalidRefreshToken
+	}
+
+	if err := s.RevokeAll(ctx, userID, models.SessionRevokedTokenReuse); err != nil {
+		log.Printf("Failed to revoke sessions of user %s after refresh token reuse: %v", userID, err)
+	}
+	log.Printf("⚠️ Refresh token reused on session %s, all sessions of user %s revoked", sessionID, userID)
+	return ErrRefreshTokenReused
+}
+
+// List returns the user's active sessions, most recently used first
+func (s *SessionService) List(ctx context.Context, userID uuid.UUID) ([]models.UserSession, error) {
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM user_sessions s
+		WHERE s.user_id = $1 AND s.revoked_at IS NULL AND s.expires_at > NOW()
+		ORDER BY s.last_seen_at DESC`, sessionColumns),
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sessions: %w", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.UserSession{}
+	for rows.Next() {
+		var session models.UserSession
+		if err := rows.Scan(sessionScanArgs(&session)...); err != nil {
+			return nil, fmt.Errorf("failed to read session: %w", err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// Revoke signs one of the user's sessions out
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID uuid.UUID, reason string) error {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`,
+		sessionID, userID, reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// RevokeOthers signs the user out everywhere except the keep session
+func (s *SessionService) RevokeOthers(ctx context.Context, userID, keep uuid.UUID, reason string) (int64, error) {
+	result, err := s.db.Pool.Exec(ctx, `
+		UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $3
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL`,
+		userID, keep, reason,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	return result.RowsAffected(), nil
+}
+
+// RevokeAll signs the user out everywhere and rejects every access token issued so
+// far, including ones from before sessions existed
+func (s *SessionService) RevokeAll(ctx context.Context, userID uuid.UUID, reason string) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := s.RevokeAllTx(ctx, tx, userID, reason); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// RevokeAllTx is RevokeAll within tx, for changes to the user that must not be
+// committed while their old tokens are still accepted
+func (s *SessionService) RevokeAllTx(ctx context.Context, tx pgx.Tx, userID uuid.UUID, reason string) error {
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_sessions SET revoked_at = NOW(), revoked_reason = $2
+		WHERE user_id = $1 AND revoked_at IS NULL`,
+		userID, reason,
+	); err != nil {
+		return fmt.Errorf("failed to revoke sessions: %w", err)
+	}
+	if _, err := tx.Exec(ctx, "UPDATE users SET tokens_valid_after = NOW() WHERE id = $1", userID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
+
+// CheckAccess is run on every access token. Tokens are refused (jwt.ErrTokenRevoked)
+// once their session is revoked or when issued before the user's tokens_valid_after,
+// and fail with ErrAccountDisabled or an AccountSuspendedError while the user is
+// banned or suspended. Lookup failures refuse the token with ErrAccessCheckFailed,
+// which is reported as temporary, so a revoked or banned user never slips through
+// while the database is unavailable.
+func (s *SessionService) CheckAccess(claims *jwt.Claims) error {
+	var issuedAt int64
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Unix()
+	}
+
+	var revoked, active bool
+	var suspendedUntil *time.Time
+	var suspensionReason *string
+	err := s.db.Pool.QueryRow(context.Background(), `
+		SELECT
+			(u.tokens_valid_after IS NOT NULL
+				AND to_timestamp($2)::timestamp < date_trunc('second', u.tokens_valid_after))
+			OR EXISTS (SELECT 1 FROM user_sessions WHERE id = $3 AND revoked_at IS NOT NULL),
+			u.is_active,
+			CASE WHEN u.suspended_until > NOW() THEN u.suspended_until END,
+			u.suspension_reason
+		FROM users u
+		WHERE u.id = $1`,
+		claims.UserID, issuedAt, claims.SessionID,
+	).Scan(&revoked, &active, &suspendedUntil, &suspensionReason)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return jwt.ErrTokenRevoked
+	}
+	if err != nil {
+		log.Printf("Error checking token access for user %s: %v", claims.UserID, err)
+		return ErrAccessCheckFailed
+	}
+
+	switch {
+	case revoked:
+		return jwt.ErrTokenRevoked
+	case !active:
+		return ErrAccountDisabled
+	case suspendedUntil != nil:
+		suspended := &AccountSuspendedError{Until: *suspendedUntil}
+		if suspensionReason != nil {
+			suspended.Reason = *suspensionReason
+		}
+		return suspended
+	}
+	return nil
+}
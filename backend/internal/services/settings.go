@@ -0,0 +1,22 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/airmass/backend/internal/database"
+)
+
+// intSetting reads a positive integer from app_settings, falling back to def
+func intSetting(ctx context.Context, db *database.DB, key string, def int) int {
+	var value string
+	if err := db.Pool.QueryRow(ctx, "SELECT value FROM app_settings WHERE key = $1", key).Scan(&value); err != nil {
+		return def
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
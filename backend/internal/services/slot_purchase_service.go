@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Slot purchase types
+const (
+	SlotPurchaseSkipQueue = "skip_queue"
+	SlotPurchaseExtraSlot = "extra_slot"
+)
+
+// Prices for category/town pairs without a slot_pricing row (match the column defaults)
+const (
+	defaultSkipQueuePrice = 2.99
+	defaultExtraSlotPrice = 4.99
+)
+
+// SlotPurchaseFulfilmentWindow is how long a paid skip or extra slot may stay unused
+// before it is refunded
+const SlotPurchaseFulfilmentWindow = 7 * 24 * time.Hour
+
+// SlotPurchaseService takes payment for skip-queue and extra-slot purchases and
+// refunds the ones that could not be used
+type SlotPurchaseService struct {
+	db              *database.DB
+	payments        *PaymentService
+	notificationSvc *NotificationService
+}
+
+func NewSlotPurchaseService(db *database.DB, paymentSvc *PaymentService, notificationSvc *NotificationService) *SlotPurchaseService {
+	return &SlotPurchaseService{db: db, payments: paymentSvc, notificationSvc: notificationSvc}
+}
+
+// Pricing returns the slot prices of a category in a town, falling back to the
+// defaults when none are stored. IsActive is false when purchases are switched off.
+func (s *SlotPurchaseService) Pricing(ctx context.Context, categoryID, townID uuid.UUID) models.SlotPricing {
+	pricing := models.SlotPricing{
+		CategoryID:     categoryID,
+		TownID:         townID,
+		SkipQueuePrice: defaultSkipQueuePrice,
+		ExtraSlotPrice: defaultExtraSlotPrice,
+		IsActive:       true,
+	}
+	s.db.Pool.QueryRow(ctx,
+		`SELECT id, COALESCE(skip_queue_price, $3), COALESCE(extra_slot_price, $4), COALESCE(is_active, true)
+		FROM slot_pricing WHERE category_id = $1 AND town_id = $2`,
+		categoryID, townID, defaultSkipQueuePrice, defaultExtraSlotPrice,
+	).Scan(&pricing.ID, &pricing.SkipQueuePrice, &pricing.ExtraSlotPrice, &pricing.IsActive)
+	return pricing
+}
+
+// Checkout collects payment for a pending purchase and marks it completed, or failed
+// when the payment does not go through. A declined payment returns
+// payments.ErrPaymentDeclined.
+func (s *SlotPurchaseService) Checkout(ctx context.Context, purchase *models.SlotPurchase) error {
+	payment, err := s.payments.Charge(ctx, purchase.UserID, models.PaymentPurposeSlotPurchase, purchase.ID,
+		purchase.AmountPaid, fmt.Sprintf("Slot purchase (%s)", purchase.PurchaseType))
+	if err != nil {
+		var paymentID *uuid.UUID
+		if payment != nil {
+			paymentID = &payment.ID
+		}
+		s.db.Pool.Exec(ctx,
+			"UPDATE slot_purchases SET status = 'failed', payment_id = $1 WHERE id = $2 AND status = 'pending'",
+			paymentID, purchase.ID,
+		)
+		purchase.Status = "failed"
+		return err
+	}
+
+	_, err = s.db.Pool.Exec(ctx, `
+		UPDATE slot_purchases SET status = 'completed', payment_id = $1, payment_provider = $2, payment_intent_id = $3
+		WHERE id = $4 AND status = 'pending'`,
+		payment.ID, payment.Provider, payment.ProviderIntentID, purchase.ID,
+	)
+	if err != nil {
+		// Paid but not recorded: hand the money straight back
+		if refundErr := s.payments.Refund(ctx, payment.ID, 0, "slot purchase not recorded"); refundErr != nil {
+			return fmt.Errorf("failed to record slot purchase %s (refund also failed: %v): %w", purchase.ID, refundErr, err)
+		}
+		return fmt.Errorf("failed to record slot purchase %s: %w", purchase.ID, err)
+	}
+
+	purchase.Status = "completed"
+	purchase.PaymentProvider = &payment.Provider
+	purchase.PaymentIntentID = &payment.ProviderIntentID
+	return nil
+}
+
+// Refund returns the payment for a completed purchase that was never used and tells
+// the seller why. Purchases that were used or already refunded are left alone.
+func (s *SlotPurchaseService) Refund(ctx context.Context, purchaseID uuid.UUID, reason string) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID, categoryID uuid.UUID
+	var amount float64
+	var paymentID *uuid.UUID
+	err = tx.QueryRow(ctx,
+		`SELECT user_id, category_id, amount_paid, payment_id FROM slot_purchases
+		WHERE id = $1 AND status = 'completed' AND auction_id IS NULL AND processed_at IS NULL
+		FOR UPDATE`,
+		purchaseID,
+	).Scan(&userID, &categoryID, &amount, &paymentID)
+	if err != nil {
+		return fmt.Errorf("slot purchase %s is not refundable: %w", purchaseID, err)
+	}
+
+	// Purchases made before checkout existed have no payment to return
+	if paymentID != nil {
+		if err := s.payments.Refund(ctx, *paymentID, 0, reason); err != nil {
+			return fmt.Errorf("failed to refund slot purchase %s: %w", purchaseID, err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx,
+		"UPDATE slot_purchases SET status = 'refunded', refunded_at = NOW(), refund_reason = $1 WHERE id = $2",
+		reason, purchaseID,
+	); err != nil {
+		return fmt.Errorf("failed to mark slot purchase %s refunded: %w", purchaseID, err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit refund: %w", err)
+	}
+
+	var categoryName string
+	s.db.Pool.QueryRow(ctx, "SELECT name FROM categories WHERE id = $1", categoryID).Scan(&categoryName)
+	s.notificationSvc.SendSlotPurchaseRefundedNotification(ctx, userID, purchaseID, categoryID, categoryName, amount)
+	return nil
+}
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+)
+
+// Slot configuration for category/town pairs without a category_slots row
+const (
+	defaultMaxActiveAuctions = 10
+	defaultDurationHours     = 168 // 7 days
+	defaultMinDurationHours  = 1
+	defaultMaxDurationHours  = 720 // 30 days
+)
+
+// SlotClaimWindow is how long a waiting-list member has to use a slot offered to them
+const SlotClaimWindow = 12 * time.Hour
+
+// SlotService answers category slot capacity questions for auction activation
+// and orders the queue for freed slots
+type SlotService struct {
+	db *database.DB
+}
+
+func NewSlotService(db *database.DB) *SlotService {
+	return &SlotService{db: db}
+}
+
+// QueueItem is one entry in a category's slot queue: either a pending auction waiting
+// to go live or a waiting-list member waiting to be offered a slot
+type QueueItem struct {
+	Kind          string // "auction" or "waiting"
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Title         string
+	DurationHours int  // Requested by the seller; 0 for the category default
+	SkipQueue     bool // Paid skip-queue purchase moves the item to the front
+	QueuedAt      time.Time
+}
+
+// Queue item kinds
+const (
+	QueueItemAuction = "auction"
+	QueueItemWaiting = "waiting"
+)
+
+// Config returns the slot configuration of a category in a town, falling back to the
+// platform defaults when none is stored
+func (s *SlotService) Config(ctx context.Context, categoryID, townID uuid.UUID) models.CategorySlot {
+	slot := models.CategorySlot{
+		CategoryID:           categoryID,
+		TownID:               townID,
+		MaxActiveAuctions:    defaultMaxActiveAuctions,
+		AuctionDurationHours: defaultDurationHours,
+		MinDurationHours:     defaultMinDurationHours,
+		MaxDurationHours:     defaultMaxDurationHours,
+	}
+
+	var maxActive, duration, minDuration, maxDuration *int
+	err := s.db.Pool.QueryRow(ctx,
+		`SELECT id, max_active_auctions, auction_duration_hours, min_duration_hours, max_duration_hours,
+		created_at, COALESCE(updated_at, created_at)
+		FROM category_slots WHERE category_id = $1 AND town_id = $2`,
+		categoryID, townID,
+	).Scan(&slot.ID, &maxActive, &duration, &minDuration, &maxDuration, &slot.CreatedAt, &slot.UpdatedAt)
+	if err != nil {
+		return slot
+	}
+
+	if maxActive != nil && *maxActive > 0 {
+		slot.MaxActiveAuctions = *maxActive
+	}
+	if duration != nil && *duration > 0 {
+		slot.AuctionDurationHours = *duration
+	}
+	if minDuration != nil && *minDuration > 0 {
+		slot.MinDurationHours = *minDuration
+	}
+	if maxDuration != nil && *maxDuration > 0 {
+		slot.MaxDurationHours = *maxDuration
+	}
+	return slot
+}
+
+// DurationHours returns how long an auction runs once it goes live: the seller's
+// requested duration (0 when they didn't choose one) or the category default, kept
+// within the category's current limits
+func (s *SlotService) DurationHours(ctx context.Context, categoryID, townID uuid.UUID, requested int) int {
+	cfg := s.Config(ctx, categoryID, townID)
+	hours := requested
+	if hours <= 0 {
+		hours = cfg.AuctionDurationHours
+	}
+	if hours < cfg.MinDurationHours {
+		hours = cfg.MinDurationHours
+	}
+	if hours > cfg.MaxDurationHours {
+		hours = cfg.MaxDurationHours
+	}
+	return hours
+}
+
+// MaxActive returns how many auctions may run at once in a category and town
+func (s *SlotService) MaxActive(ctx context.Context, categoryID, townID uuid.UUID) int {
+	return s.Config(ctx, categoryID, townID).MaxActiveAuctions
+}
+
+// ActiveCount returns how many auctions currently occupy a slot in a category and town.
+// Auctions running in a paid extra slot sit on top of the limit and are not counted.
+func (s *SlotService) ActiveCount(ctx context.Context, categoryID, townID uuid.UUID) int {
+	var count int
+	s.db.Pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM auctions a
+		WHERE a.category_id = $1 AND a.town_id = $2 AND a.status IN ('active', 'ending_soon')
+		AND NOT EXISTS (
+			SELECT 1 FROM slot_purchases sp
+			WHERE sp.auction_id = a.id AND sp.purchase_type = 'extra_slot' AND sp.status = 'completed'
+		)`,
+		categoryID, townID,
+	).Scan(&count)
+	return count
+}
+
+// ReservedCount returns how many slots are held for waiting-list members who have
+// been offered one and have not used it yet
+func (s *SlotService) ReservedCount(ctx context.Context, categoryID, townID uuid.UUID) int {
+	var count int
+	s.db.Pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM waiting_list
+		WHERE category_id = $1 AND town_id = $2 AND status = 'slot_available' AND expires_at > NOW()`,
+		categoryID, townID,
+	).Scan(&count)
+	return count
+}
+
+// AvailableSlots returns the number of free, unreserved slots (never negative)
+func (s *SlotService) AvailableSlots(ctx context.Context, categoryID, townID uuid.UUID) int {
+	available := s.MaxActive(ctx, categoryID, townID) - s.ActiveCount(ctx, categoryID, townID) -
+		s.ReservedCount(ctx, categoryID, townID)
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
+// HasCapacity reports whether another auction can go live right now
+func (s *SlotService) HasCapacity(ctx context.Context, categoryID, townID uuid.UUID) bool {
+	return s.AvailableSlots(ctx, categoryID, townID) > 0
+}
+
+// ActiveClaim returns the user's unexpired slot offer for a category and town, if any
+func (s *SlotService) ActiveClaim(ctx context.Context, userID, categoryID, townID uuid.UUID) (uuid.UUID, bool) {
+	var entryID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx,
+		`SELECT id FROM waiting_list
+		WHERE user_id = $1 AND category_id = $2 AND town_id = $3
+		AND status = 'slot_available' AND expires_at > NOW()`,
+		userID, categoryID, townID,
+	).Scan(&entryID)
+	return entryID, err == nil
+}
+
+// ExtraSlot returns the user's paid, unused extra slot for a category and town, if any
+func (s *SlotService) ExtraSlot(ctx context.Context, userID, categoryID, townID uuid.UUID) (uuid.UUID, bool) {
+	var purchaseID uuid.UUID
+	err := s.db.Pool.QueryRow(ctx,
+		`SELECT id FROM slot_purchases
+		WHERE user_id = $1 AND category_id = $2 AND town_id = $3
+		AND purchase_type = 'extra_slot' AND status = 'completed' AND auction_id IS NULL
+		ORDER BY created_at ASC
+		LIMIT 1`,
+		userID, categoryID, townID,
+	).Scan(&purchaseID)
+	return purchaseID, err == nil
+}
+
+// SlotGrant records what lets an auction go live when it isn't simply a free slot:
+// a slot offered from the waiting list or a paid extra slot
+type SlotGrant struct {
+	ClaimID     *uuid.UUID
+	ExtraSlotID *uuid.UUID
+}
+
+// CanActivate reports whether the user's auction may go live now: through a slot
+// offered to them from the waiting list, a free slot, or a paid extra slot, in that
+// order. The grant is nil when a free slot is used.
+func (s *SlotService) CanActivate(ctx context.Context, userID, categoryID, townID uuid.UUID) (*SlotGrant, bool) {
+	if entryID, found := s.ActiveClaim(ctx, userID, categoryID, townID); found {
+		return &SlotGrant{ClaimID: &entryID}, true
+	}
+	if s.HasCapacity(ctx, categoryID, townID) {
+		return nil, true
+	}
+	if purchaseID, found := s.ExtraSlot(ctx, userID, categoryID, townID); found {
+		return &SlotGrant{ExtraSlotID: &purchaseID}, true
+	}
+	return nil, false
+}
+
+// ConsumeGrant marks the claim or extra slot an auction went live with as used
+func (s *SlotService) ConsumeGrant(ctx context.Context, grant *SlotGrant, auctionID uuid.UUID) error {
+	if grant == nil {
+		return nil
+	}
+	if grant.ClaimID != nil {
+		return s.ConsumeClaim(ctx, *grant.ClaimID, auctionID)
+	}
+	if grant.ExtraSlotID != nil {
+		return s.UseExtraSlot(ctx, *grant.ExtraSlotID, auctionID)
+	}
+	return nil
+}
+
+// ConsumeClaim marks a slot offer as used by the auction that went live
+func (s *SlotService) ConsumeClaim(ctx context.Context, entryID, auctionID uuid.UUID) error {
+	_, err := s.db.Pool.Exec(ctx,
+		"UPDATE waiting_list SET status = 'claimed', auction_id = $1 WHERE id = $2 AND status = 'slot_available'",
+		auctionID, entryID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to claim slot: %w", err)
+	}
+	return s.UseSkipQueuePurchase(ctx, auctionID)
+}
+
+// UseExtraSlot links a paid extra slot to the auction running in it
+func (s *SlotService) UseExtraSlot(ctx context.Context, purchaseID, auctionID uuid.UUID) error {
+	_, err := s.db.Pool.Exec(ctx,
+		`UPDATE slot_purchases SET auction_id = $1, processed_at = NOW()
+		WHERE id = $2 AND purchase_type = 'extra_slot' AND status = 'completed' AND auction_id IS NULL`,
+		auctionID, purchaseID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to use extra slot: %w", err)
+	}
+	return nil
+}
+
+// MarkSkipQueueUsed records that a waiting-list member's skip-queue purchase moved them
+// to the front and got them a slot offer, so it is no longer refundable
+func (s *SlotService) MarkSkipQueueUsed(ctx context.Context, userID, categoryID, townID uuid.UUID) error {
+	_, err := s.db.Pool.Exec(ctx,
+		`UPDATE slot_purchases SET processed_at = NOW()
+		WHERE purchase_type = 'skip_queue' AND status = 'completed' AND auction_id IS NULL AND processed_at IS NULL
+		AND user_id = $1 AND category_id = $2 AND town_id = $3`,
+		userID, categoryID, townID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark skip-queue purchase used: %w", err)
+	}
+	return nil
+}
+
+// UseSkipQueuePurchase links one unused skip-queue purchase of the auction's seller to the
+// auction, so the purchase does not move them up the queue a second time
+func (s *SlotService) UseSkipQueuePurchase(ctx context.Context, auctionID uuid.UUID) error {
+	_, err := s.db.Pool.Exec(ctx, `
+		UPDATE slot_purchases SET auction_id = $1, processed_at = COALESCE(processed_at, NOW())
+		WHERE id = (
+			SELECT sp.id FROM slot_purchases sp
+			JOIN auctions a ON a.id = $1
+			WHERE sp.purchase_type = 'skip_queue' AND sp.status = 'completed' AND sp.auction_id IS NULL
+			AND sp.user_id = a.seller_id AND sp.category_id = a.category_id AND sp.town_id = a.town_id
+			ORDER BY sp.created_at ASC
+			LIMIT 1
+		)`,
+		auctionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to use skip-queue purchase: %w", err)
+	}
+	return nil
+}
+
+// Queue returns the category's slot queue in the order slots are handed out: paid
+// skip-queue items first, then everyone else by the time they joined. Pending auctions
+// and waiting-list members share one queue. limit <= 0 returns the whole queue.
+func (s *SlotService) Queue(ctx context.Context, categoryID, townID uuid.UUID, limit int) ([]QueueItem, error) {
+	query := `
+		SELECT kind, id, user_id, title, duration_hours, skip_queue, queued_at FROM (
+			SELECT 'auction' AS kind, a.id, a.seller_id AS user_id, a.title,
+			COALESCE(a.duration_hours, 0) AS duration_hours, a.created_at AS queued_at,
+			EXISTS(
+				SELECT 1 FROM slot_purchases sp
+				WHERE sp.purchase_type = 'skip_queue' AND sp.status = 'completed'
+				AND sp.category_id = a.category_id AND sp.town_id = a.town_id
+				AND (sp.auction_id = a.id OR (sp.auction_id IS NULL AND sp.processed_at IS NULL AND sp.user_id = a.seller_id))
+			) AS skip_queue
+			FROM auctions a
+			WHERE a.category_id = $1 AND a.town_id = $2 AND a.status = 'pending'
+			UNION ALL
+			SELECT 'waiting' AS kind, wl.id, wl.user_id, COALESCE(wl.auction_title, '') AS title,
+			0 AS duration_hours, wl.created_at AS queued_at,
+			EXISTS(
+				SELECT 1 FROM slot_purchases sp
+				WHERE sp.purchase_type = 'skip_queue' AND sp.status = 'completed'
+				AND sp.category_id = wl.category_id AND sp.town_id = wl.town_id
+				AND sp.auction_id IS NULL AND sp.processed_at IS NULL AND sp.user_id = wl.user_id
+			) AS skip_queue
+			FROM waiting_list wl
+			WHERE wl.category_id = $1 AND wl.town_id = $2 AND wl.status = 'waiting'
+		) q
+		ORDER BY skip_queue DESC, queued_at ASC, id ASC`
+	args := []interface{}{categoryID, townID}
+	if limit > 0 {
+		query += " LIMIT $3"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load slot queue: %w", err)
+	}
+	defer rows.Close()
+
+	var queue []QueueItem
+	for rows.Next() {
+		var item QueueItem
+		if err := rows.Scan(&item.Kind, &item.ID, &item.UserID, &item.Title, &item.DurationHours,
+			&item.SkipQueue, &item.QueuedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan slot queue: %w", err)
+		}
+		queue = append(queue, item)
+	}
+	return queue, rows.Err()
+}
+
+// RenumberWaitingList rewrites waiting_list.position to match the current queue order
+func (s *SlotService) RenumberWaitingList(ctx context.Context, categoryID, townID uuid.UUID) error {
+	queue, err := s.Queue(ctx, categoryID, townID, 0)
+	if err != nil {
+		return err
+	}
+	for i, item := range queue {
+		if item.Kind != QueueItemWaiting {
+			continue
+		}
+		if _, err := s.db.Pool.Exec(ctx,
+			"UPDATE waiting_list SET position = $1 WHERE id = $2 AND position IS DISTINCT FROM $1",
+			i+1, item.ID,
+		); err != nil {
+			return fmt.Errorf("failed to renumber waiting list: %w", err)
+		}
+	}
+	return nil
+}
+
+// EstimatedWait guesses when the item at a queue position (1-based) gets a slot, from
+// the end times of the auctions currently occupying the category
+func (s *SlotService) EstimatedWait(ctx context.Context, categoryID, townID uuid.UUID, position int) time.Duration {
+	free := s.AvailableSlots(ctx, categoryID, townID)
+	if position <= free {
+		return 0
+	}
+	needed := position - free
+
+	rows, err := s.db.Pool.Query(ctx,
+		`SELECT end_time FROM auctions
+		WHERE category_id = $1 AND town_id = $2 AND status IN ('active', 'ending_soon') AND end_time IS NOT NULL
+		ORDER BY end_time ASC`,
+		categoryID, townID,
+	)
+	if err != nil {
+		return 0
+	}
+	defer rows.Close()
+
+	var endTimes []time.Time
+	for rows.Next() {
+		var t time.Time
+		if rows.Scan(&t) == nil {
+			endTimes = append(endTimes, t)
+		}
+	}
+	if len(endTimes) == 0 {
+		return 0
+	}
+
+	// Slots further down the queue than there are running auctions free up a
+	// full auction length later for each extra round
+	rounds := (needed - 1) / len(endTimes)
+	roundHours := s.Config(ctx, categoryID, townID).AuctionDurationHours
+	wait := time.Until(endTimes[(needed-1)%len(endTimes)]) + time.Duration(rounds*roundHours)*time.Hour
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Defaults for the strike settings in app_settings
+const (
+	defaultUnpaidStrikeLimit      = 3
+	defaultUnpaidStrikeWindowDays = 365
+)
+
+// Codes returned by BiddingRestriction
+const (
+	RestrictionStrikes         = "BIDDING_RESTRICTED"
+	RestrictionBlockedBySeller = "BLOCKED_BY_SELLER"
+)
+
+// ErrStrikeExists is returned when the buyer already has a strike for the auction
+var ErrStrikeExists = errors.New("unpaid item already reported")
+
+// StrikeService records unpaid item strikes and decides who may bid on whose auctions
+type StrikeService struct {
+	db              *database.DB
+	notificationSvc *NotificationService
+}
+
+func NewStrikeService(db *database.DB, notificationSvc *NotificationService) *StrikeService {
+	return &StrikeService{db: db, notificationSvc: notificationSvc}
+}
+
+// Limit is how many strikes inside the window stop a user from bidding (0 disables it)
+func (s *StrikeService) Limit(ctx context.Context) int {
+	return intSetting(ctx, s.db, "unpaid_strike_limit", defaultUnpaidStrikeLimit)
+}
+
+// ActiveCount returns the user's strikes inside the strike window
+func (s *StrikeService) ActiveCount(ctx context.Context, userID uuid.UUID) int {
+	days := intSetting(ctx, s.db, "unpaid_strike_window_days", defaultUnpaidStrikeWindowDays)
+	var count int
+	s.db.Pool.QueryRow(ctx,
+		"SELECT COUNT(*) FROM unpaid_item_strikes WHERE user_id = $1 AND created_at > $2",
+		userID, time.Now().AddDate(0, 0, -days),
+	).Scan(&count)
+	return count
+}
+
+// IsRestricted reports whether the user has reached the strike limit
+func (s *StrikeService) IsRestricted(ctx context.Context, userID uuid.UUID) bool {
+	limit := s.Limit(ctx)
+	return limit > 0 && s.ActiveCount(ctx, userID) >= limit
+}
+
+// BiddingRestriction returns a code and message when bidderID may not bid on or buy
+// sellerID's auctions, or empty strings when they may
+func (s *StrikeService) BiddingRestriction(ctx context.Context, bidderID, sellerID uuid.UUID) (string, string) {
+	var blocked bool
+	s.db.Pool.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM seller_blocked_bidders WHERE seller_id = $1 AND bidder_id = $2)",
+		sellerID, bidderID,
+	).Scan(&blocked)
+	if blocked {
+		return RestrictionBlockedBySeller, "The seller is not accepting bids from you"
+	}
+	if s.IsRestricted(ctx, bidderID) {
+		return RestrictionStrikes, "Bidding is restricted on your account because of unpaid items"
+	}
+	return "", ""
+}
+
+// RecordUnpaidStrike records a strike against a buyer who did not pay for an auction,
+// updates their behaviour metrics and tells them. Once the buyer reaches the strike
+// limit their auto-bids are switched off. Returns ErrStrikeExists for a repeat report.
+func (s *StrikeService) RecordUnpaidStrike(ctx context.Context, buyerID, auctionID uuid.UUID, orderID *uuid.UUID, sellerID uuid.UUID, notes *string) (*models.UnpaidItemStrike, error) {
+	var strike models.UnpaidItemStrike
+	err := s.db.Pool.QueryRow(ctx, `
+		INSERT INTO unpaid_item_strikes (user_id, auction_id, order_id, reported_by, notes)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (auction_id, user_id) DO NOTHING
+		RETURNING id, user_id, auction_id, order_id, reported_by, notes, created_at`,
+		buyerID, auctionID, orderID, sellerID, notes,
+	).Scan(&strike.ID, &strike.UserID, &strike.AuctionID, &strike.OrderID, &strike.ReportedBy, &strike.Notes, &strike.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrStrikeExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to record strike: %w", err)
+	}
+
+	count := s.ActiveCount(ctx, buyerID)
+
+	// The update (not the insert) runs the fraud scoring trigger
+	if _, err := s.db.Pool.Exec(ctx,
+		"INSERT INTO user_behavior_metrics (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING",
+		buyerID,
+	); err != nil {
+		log.Printf("Failed to create behaviour metrics for user %s: %v", buyerID, err)
+	}
+	if _, err := s.db.Pool.Exec(ctx,
+		"UPDATE user_behavior_metrics SET unpaid_strike_count = $1, last_calculated_at = NOW() WHERE user_id = $2",
+		count, buyerID,
+	); err != nil {
+		log.Printf("Failed to update behaviour metrics for user %s: %v", buyerID, err)
+	}
+
+	limit := s.Limit(ctx)
+	restricted := limit > 0 && count >= limit
+	if restricted {
+		if _, err := s.db.Pool.Exec(ctx, `
+			UPDATE auto_bids
+			SET is_active = false, deactivated_at = NOW(), deactivation_reason = 'bidding_restricted'
+			WHERE user_id = $1 AND is_active = true`,
+			buyerID,
+		); err != nil {
+			log.Printf("Failed to stop auto-bids of restricted user %s: %v", buyerID, err)
+		}
+	}
+
+	var title string
+	s.db.Pool.QueryRow(ctx, "SELECT title FROM auctions WHERE id = $1", auctionID).Scan(&title)
+	strike.AuctionTitle = &title
+	s.notificationSvc.SendUnpaidStrikeNotification(ctx, buyerID, auctionID, title, count, limit, restricted)
+
+	return &strike, nil
+}
+
+// Block bars a bidder from the seller's auctions and stops their auto-bids there
+func (s *StrikeService) Block(ctx context.Context, sellerID, bidderID uuid.UUID, reason *string) error {
+	if _, err := s.db.Pool.Exec(ctx, `
+		INSERT INTO seller_blocked_bidders (seller_id, bidder_id, reason)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (seller_id, bidder_id) DO UPDATE SET reason = EXCLUDED.reason`,
+		sellerID, bidderID, reason,
+	); err != nil {
+		return fmt.Errorf("failed to block bidder: %w", err)
+	}
+
+	if _, err := s.db.Pool.Exec(ctx, `
+		UPDATE auto_bids ab
+		SET is_active = false, deactivated_at = NOW(), deactivation_reason = 'blocked_by_seller'
+		FROM auctions a
+		WHERE a.id = ab.auction_id AND a.seller_id = $1 AND ab.user_id = $2 AND ab.is_active = true`,
+		sellerID, bidderID,
+	); err != nil {
+		return fmt.Errorf("failed to stop auto-bids of blocked bidder: %w", err)
+	}
+	return nil
+}
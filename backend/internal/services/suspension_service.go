@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/websocket"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Close reasons sent to websocket clients of users who lose access
+const (
+	disconnectAccountDisabled  = "account_disabled"
+	disconnectAccountSuspended = "account_suspended"
+)
+
+var (
+	ErrAccountDisabled  = errors.New("account disabled")
+	ErrUserNotFound     = errors.New("user not found")
+	ErrNotSuspended     = errors.New("user is not suspended")
+	ErrAppealExists     = errors.New("suspension already appealed")
+	ErrAppealNotFound   = errors.New("appeal not found")
+	ErrAppealNotPending = errors.New("appeal already reviewed")
+)
+
+// AccountSuspendedError is returned for requests by a suspended user
+type AccountSuspendedError struct {
+	Until  time.Time
+	Reason string
+}
+
+func (e *AccountSuspendedError) Error() string {
+	return fmt.Sprintf("account suspended until %s", e.Until.Format(time.RFC3339))
+}
+
+// SuspensionService disables, suspends and reinstates users, and handles appeals
+type SuspensionService struct {
+	db       *database.DB
+	hub      *websocket.Hub
+	sessions *SessionService
+}
+
+func NewSuspensionService(db *database.DB, hub *websocket.Hub, sessions *SessionService) *SuspensionService {
+	return &SuspensionService{db: db, hub: hub, sessions: sessions}
+}
+
+// SetActive enables or disables an account. Disabling signs the user out everywhere
+// in the same transaction, so a user is never left disabled with live tokens, and
+// closes their websocket connections.
+func (s *SuspensionService) SetActive(ctx context.Context, userID uuid.UUID, active bool) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		"UPDATE users SET is_active = $1, updated_at = NOW() WHERE id = $2",
+		active, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update user status: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	if !active {
+		if err := s.sessions.RevokeAllTx(ctx, tx, userID, models.SessionRevokedAccountDisabled); err != nil {
+			return err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit user status: %w", err)
+	}
+
+	if !active {
+		s.hub.DisconnectUser(userID, disconnectAccountDisabled)
+	}
+	return nil
+}
+
+const suspensionColumns = `
+	us.id, us.user_id, us.reason, us.suspended_by, us.starts_at, us.ends_at, us.lifted_at, us.lifted_by`
+
+func suspensionScanArgs(us *models.UserSuspension) []interface{} {
+	return []interface{}{
+		&us.ID, &us.UserID, &us.Reason, &us.SuspendedBy, &us.StartsAt, &us.EndsAt, &us.LiftedAt, &us.LiftedBy,
+	}
+}
+
+// Suspend suspends a user until the given time, replacing any current suspension, and
+// closes their websocket connections. They stay signed in so they can appeal.
+func (s *SuspensionService) Suspend(ctx context.Context, userID, adminID uuid.UUID, until time.Time, reason string) (*models.UserSuspension, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx, `
+		UPDATE users SET suspended_until = $2, suspension_reason = $3, updated_at = NOW()
+		WHERE id = $1`,
+		userID, until, reason,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suspend user: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	// The new suspension replaces the current one
+	if _, err := tx.Exec(ctx, `
+		UPDATE user_suspensions SET lifted_at = NOW(), lifted_by = $2
+		WHERE user_id = $1 AND lifted_at IS NULL AND ends_at > NOW()`,
+		userID, adminID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to end current suspension: %w", err)
+	}
+
+	var suspension models.UserSuspension
+	err = tx.QueryRow(ctx, fmt.Sprintf(`
+		INSERT INTO user_suspensions AS us (user_id, reason, suspended_by, ends_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING %s`, suspensionColumns),
+		userID, reason, adminID, until,
+	).Scan(suspensionScanArgs(&suspension)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record suspension: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit suspension: %w", err)
+	}
+
+	s.hub.DisconnectUser(userID, disconnectAccountSuspended)
+	return &suspension, nil
+}
+
+// Lift ends the user's current suspension early
+func (s *SuspensionService) Lift(ctx context.Context, userID, adminID uuid.UUID) error {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := liftSuspension(ctx, tx, userID, adminID, nil); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// liftSuspension ends the user's running suspension; when suspensionID is set, only
+// if it is that one
+func liftSuspension(ctx context.Context, tx pgx.Tx, userID, adminID uuid.UUID, suspensionID *uuid.UUID) error {
+	result, err := tx.Exec(ctx, `
+		UPDATE user_suspensions SET lifted_at = NOW(), lifted_by = $2
+		WHERE user_id = $1 AND lifted_at IS NULL AND ends_at > NOW()
+			AND ($3::uuid IS NULL OR id = $3)`,
+		userID, adminID, suspensionID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to lift suspension: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotSuspended
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE users SET suspended_until = NULL, suspension_reason = NULL, updated_at = NOW()
+		WHERE id = $1`,
+		userID,
+	); err != nil {
+		return fmt.Errorf("failed to lift suspension: %w", err)
+	}
+	return nil
+}
+
+// Current returns the user's active suspension with its appeal, or nil
+func (s *SuspensionService) Current(ctx context.Context, userID uuid.UUID) (*models.UserSuspension, error) {
+	var suspension models.UserSuspension
+	err := s.db.Pool.QueryRow(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM user_suspensions us
+		WHERE us.user_id = $1 AND us.lifted_at IS NULL AND us.ends_at > NOW()
+		ORDER BY us.starts_at DESC
+		LIMIT 1`, suspensionColumns),
+		userID,
+	).Scan(suspensionScanArgs(&suspension)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch suspension: %w", err)
+	}
+
+	appeals, _, err := s.listAppeals(ctx, "a.suspension_id = $1", []interface{}{suspension.ID}, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(appeals) > 0 {
+		suspension.Appeal = &appeals[0]
+	}
+	return &suspension, nil
+}
+
+// History returns all of the user's suspensions, newest first
+func (s *SuspensionService) History(ctx context.Context, userID uuid.UUID) ([]models.UserSuspension, error) {
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM user_suspensions us
+		WHERE us.user_id = $1
+		ORDER BY us.starts_at DESC`, suspensionColumns),
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch suspensions: %w", err)
+	}
+	defer rows.Close()
+
+	suspensions := []models.UserSuspension{}
+	for rows.Next() {
+		var suspension models.UserSuspension
+		if err := rows.Scan(suspensionScanArgs(&suspension)...); err != nil {
+			return nil, fmt.Errorf("failed to read suspension: %w", err)
+		}
+		suspensions = append(suspensions, suspension)
+	}
+	return suspensions, rows.Err()
+}
+
+// Appeal appeals the user's current suspension. Each suspension can be appealed once.
+func (s *SuspensionService) Appeal(ctx context.Context, userID uuid.UUID, message string) (*models.SuspensionAppeal, error) {
+	suspension, err := s.Current(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if suspension == nil {
+		return nil, ErrNotSuspended
+	}
+	if suspension.Appeal != nil {
+		return nil, ErrAppealExists
+	}
+
+	var appealID uuid.UUID
+	err = s.db.Pool.QueryRow(ctx, `
+		INSERT INTO suspension_appeals (suspension_id, user_id, message)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (suspension_id) DO NOTHING
+		RETURNING id`,
+		suspension.ID, userID, message,
+	).Scan(&appealID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAppealExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create appeal: %w", err)
+	}
+	return s.getAppeal(ctx, appealID)
+}
+
+const appealColumns = `
+	a.id, a.suspension_id, a.user_id, a.message, a.status, a.admin_response, a.reviewed_by, a.reviewed_at, a.created_at,
+	u.username, us.reason, us.ends_at`
+
+func appealScanArgs(a *models.SuspensionAppeal) []interface{} {
+	return []interface{}{
+		&a.ID, &a.SuspensionID, &a.UserID, &a.Message, &a.Status, &a.AdminResponse, &a.ReviewedBy, &a.ReviewedAt, &a.CreatedAt,
+		&a.Username, &a.SuspensionReason, &a.SuspensionEndsAt,
+	}
+}
+
+func (s *SuspensionService) listAppeals(ctx context.Context, where string, args []interface{}, limit, offset int) ([]models.SuspensionAppeal, int, error) {
+	args = append(args, limit, offset)
+	rows, err := s.db.Pool.Query(ctx, fmt.Sprintf(`
+		SELECT %s, COUNT(*) OVER()
+		FROM suspension_appeals a
+		JOIN users u ON u.id = a.user_id
+		JOIN user_suspensions us ON us.id = a.suspension_id
+		WHERE %s
+		ORDER BY a.created_at DESC
+		LIMIT $%d OFFSET $%d`,
+		appealColumns, where, len(args)-1, len(args)),
+		args...,
+	)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch appeals: %w", err)
+	}
+	defer rows.Close()
+
+	appeals := []models.SuspensionAppeal{}
+	total := 0
+	for rows.Next() {
+		var a models.SuspensionAppeal
+		if err := rows.Scan(append(appealScanArgs(&a), &total)...); err != nil {
+			return nil, 0, fmt.Errorf("failed to read appeal: %w", err)
+		}
+		appeals = append(appeals, a)
+	}
+	return appeals, total, rows.Err()
+}
+
+func (s *SuspensionService) getAppeal(ctx context.Context, appealID uuid.UUID) (*models.SuspensionAppeal, error) {
+	appeals, _, err := s.listAppeals(ctx, "a.id = $1", []interface{}{appealID}, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(appeals) == 0 {
+		return nil, ErrAppealNotFound
+	}
+	return &appeals[0], nil
+}
+
+// ListAppeals returns appeals for admins, newest first, optionally by status
+func (s *SuspensionService) ListAppeals(ctx context.Context, status string, limit, offset int) ([]models.SuspensionAppeal, int, error) {
+	return s.listAppeals(ctx, "($1 = '' OR a.status = $1)", []interface{}{status}, limit, offset)
+}
+
+// ReviewAppeal approves or rejects a pending appeal. Approving lifts the suspension
+// if it is still running.
+func (s *SuspensionService) ReviewAppeal(ctx context.Context, appealID, adminID uuid.UUID, decision, response string) (*models.SuspensionAppeal, error) {
+	tx, err := s.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID, suspensionID uuid.UUID
+	var status string
+	err = tx.QueryRow(ctx,
+		"SELECT user_id, suspension_id, status FROM suspension_appeals WHERE id = $1 FOR UPDATE",
+		appealID,
+	).Scan(&userID, &suspensionID, &status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAppealNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch appeal: %w", err)
+	}
+	if status != models.AppealPending {
+		return nil, ErrAppealNotPending
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE suspension_appeals
+		SET status = $2, admin_response = NULLIF($3, ''), reviewed_by = $4, reviewed_at = NOW()
+		WHERE id = $1`,
+		appealID, decision, response, adminID,
+	); err != nil {
+		return nil, fmt.Errorf("failed to review appeal: %w", err)
+	}
+
+	if decision == models.AppealApproved {
+		// The suspension may have run out or been replaced while the appeal waited
+		if err := liftSuspension(ctx, tx, userID, adminID, &suspensionID); err != nil && !errors.Is(err, ErrNotSuspended) {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit appeal review: %w", err)
+	}
+	return s.getAppeal(ctx, appealID)
+}
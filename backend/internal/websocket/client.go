@@ -56,8 +56,13 @@ func (h *Handler) HandleConnection(c *gin.Context) {
 	var userID *uuid.UUID
 
 	if token != "" {
-		claims, err := h.jwtService.ValidateToken(token)
+		claims, err := h.jwtService.Parse(token)
 		if err == nil {
+			// Banned, suspended and signed-out users may not connect at all
+			if err := h.jwtService.CheckAccess(claims); err != nil {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Access denied", "code": "ACCESS_DENIED"})
+				return
+			}
 			userID = &claims.UserID
 		}
 	}
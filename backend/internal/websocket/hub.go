@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"log"
 	"sync"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
@@ -19,18 +20,24 @@ const (
 	MessageTypePing        MessageType = "ping"
 
 	// Server -> Client
-	MessageTypeBidNew        MessageType = "bid:new"
-	MessageTypeBidOutbid     MessageType = "bid:outbid"
-	MessageTypeAuctionEnding MessageType = "auction:ending"
-	MessageTypeAuctionEnded  MessageType = "auction:ended"
-	MessageTypeAuctionWon    MessageType = "auction:won"
-	MessageTypeAuctionSold   MessageType = "auction:sold"
-	MessageTypeAuctionUpdate MessageType = "auction:update"
-	MessageTypeNotification  MessageType = "notification:new"
-	MessageTypeMessage       MessageType = "message:new"
-	MessageTypeShopMessage   MessageType = "shop_message:new"
-	MessageTypeError         MessageType = "error"
-	MessageTypePong          MessageType = "pong"
+	MessageTypeBidNew          MessageType = "bid:new"
+	MessageTypeBidOutbid       MessageType = "bid:outbid"
+	MessageTypeAuctionEnding   MessageType = "auction:ending"
+	MessageTypeAuctionEnded    MessageType = "auction:ended"
+	MessageTypeAuctionWon      MessageType = "auction:won"
+	MessageTypeAuctionSold     MessageType = "auction:sold"
+	MessageTypeAuctionUpdate   MessageType = "auction:update"
+	MessageTypeAuctionExtended MessageType = "auction:extended"
+	MessageTypeNotification    MessageType = "notification:new"
+	MessageTypeMessage         MessageType = "message:new"
+	MessageTypeShopMessage     MessageType = "shop_message:new"
+	MessageTypeOfferNew        MessageType = "offer:new"
+	MessageTypeOfferCountered  MessageType = "offer:countered"
+	MessageTypeOfferAccepted   MessageType = "offer:accepted"
+	MessageTypeOfferDeclined   MessageType = "offer:declined"
+	MessageTypeOfferExpired    MessageType = "offer:expired"
+	MessageTypeError           MessageType = "error"
+	MessageTypePong            MessageType = "pong"
 )
 
 // Message represents a WebSocket message
@@ -316,3 +323,22 @@ func (h *Hub) Register(client *Client) {
 func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
+
+// DisconnectUser closes every connection the user has open, telling the client why
+func (h *Hub) DisconnectUser(userID uuid.UUID, reason string) {
+	h.mu.RLock()
+	clients := append([]*Client(nil), h.userClients[userID]...)
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		// WriteControl is safe alongside the write pump; closing the connection ends the
+		// read pump, which unregisters the client
+		client.Conn.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason),
+			time.Now().Add(writeWait))
+		client.Conn.Close()
+	}
+	if len(clients) > 0 {
+		log.Printf("Disconnected %d websocket clients of user %s: %s", len(clients), userID, reason)
+	}
+}
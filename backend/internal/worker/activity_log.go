@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// activityPruneInterval is how often old activity log entries are deleted
+const activityPruneInterval = time.Hour
+
+// pruneActivityLog deletes activity log entries older than the retention setting, at
+// most once an hour
+func (w *AuctionWorker) pruneActivityLog(ctx context.Context) {
+	if time.Since(w.lastActivityPrune) < activityPruneInterval {
+		return
+	}
+	w.lastActivityPrune = time.Now()
+
+	deleted, err := w.activity.Prune(ctx)
+	if err != nil {
+		log.Printf("Error pruning activity log: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("🧹 Pruned %d activity log entries", deleted)
+	}
+}
@@ -7,6 +7,8 @@ import (
 
 	"github.com/airmass/backend/internal/database"
 	"github.com/airmass/backend/internal/fcm"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/payments"
 	"github.com/airmass/backend/internal/services"
 	"github.com/airmass/backend/internal/websocket"
 	"github.com/google/uuid"
@@ -17,16 +19,28 @@ type AuctionWorker struct {
 	hub             *websocket.Hub
 	fcmService      *fcm.FCMService
 	notificationSvc *services.NotificationService
+	slots           *services.SlotService
+	slotPurchases   *services.SlotPurchaseService
+	orders          *services.OrderService
 	badgeWorker     *BadgeWorker
+	activity        *services.ActivityService
+
+	lastActivityPrune time.Time
 }
 
-func NewAuctionWorker(db *database.DB, hub *websocket.Hub, fcmService *fcm.FCMService) *AuctionWorker {
+func NewAuctionWorker(db *database.DB, hub *websocket.Hub, fcmService *fcm.FCMService, paymentProvider payments.Provider) *AuctionWorker {
+	notificationSvc := services.NewNotificationService(db, hub, fcmService)
+	paymentSvc := services.NewPaymentService(db, paymentProvider, notificationSvc)
 	return &AuctionWorker{
 		db:              db,
 		hub:             hub,
 		fcmService:      fcmService,
-		notificationSvc: services.NewNotificationService(db, hub, fcmService),
+		notificationSvc: notificationSvc,
+		slots:           services.NewSlotService(db),
+		slotPurchases:   services.NewSlotPurchaseService(db, paymentSvc, notificationSvc),
+		orders:          services.NewOrderService(db, paymentSvc, notificationSvc),
 		badgeWorker:     NewBadgeWorker(db),
+		activity:        services.NewActivityService(db),
 	}
 }
 
@@ -49,96 +63,40 @@ func (w *AuctionWorker) Start(ctx context.Context) {
 func (w *AuctionWorker) processAuctions() {
 	ctx := context.Background()
 
-	// 1. End expired auctions
-	w.endExpiredAuctions(ctx)
+	// 1. Settle expired auctions (exactly once, even with several replicas)
+	w.settleExpiredAuctions(ctx)
+
+	// 1b. Deliver settlement side effects queued in the outbox
+	w.processSettlementOutbox(ctx)
 
 	// 2. Transition auctions to 'ending_soon' (e.g. 1 hour left)
 	w.updateEndingSoon(ctx)
 
-	// 3. Process waiting list (pending -> active)
-	w.processWaitingList(ctx)
-}
-
-func (w *AuctionWorker) endExpiredAuctions(ctx context.Context) {
-	rows, err := w.db.Pool.Query(ctx, `
-		SELECT id, title, seller_id, town_id, category_id 
-		FROM auctions 
-		WHERE status IN ('active', 'ending_soon') AND end_time <= NOW()
-	`)
-	if err != nil {
-		return
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var auctionID, sellerID, townID, categoryID uuid.UUID
-		var title string
-		if err := rows.Scan(&auctionID, &title, &sellerID, &townID, &categoryID); err != nil {
-			continue
-		}
-
-		// Find highest bidder
-		var winnerID uuid.UUID
-		var winnerName string
-		var finalAmount float64
-		err := w.db.Pool.QueryRow(ctx, `
-			SELECT b.bidder_id, u.full_name, b.amount
-			FROM bids b
-			JOIN users u ON u.id = b.bidder_id
-			WHERE b.auction_id = $1
-			ORDER BY b.amount DESC
-			LIMIT 1
-		`, auctionID).Scan(&winnerID, &winnerName, &finalAmount)
-
-		if err != nil {
-			// No bids - just end the auction
-			w.db.Pool.Exec(ctx, "UPDATE auctions SET status = 'ended' WHERE id = $1", auctionID)
-
-			// Notify seller that auction ended with no bids
-			w.notificationSvc.SendAuctionEndedNotification(ctx, sellerID, auctionID, title)
-
-			log.Printf("⏰ Auction ended with no bids: %s (%s)", title, auctionID)
-		} else {
-			// Update auction with winner
-			_, err = w.db.Pool.Exec(ctx, `
-				UPDATE auctions 
-				SET status = 'ended', winner_id = $1, final_amount = $2 
-				WHERE id = $3
-			`, winnerID, finalAmount, auctionID)
+	// 3. Start scheduled auctions whose start_time has arrived (scheduled -> active/pending)
+	w.activateScheduledAuctions(ctx)
 
-			if err != nil {
-				log.Printf("Error updating auction winner: %v", err)
-				continue
-			}
+	// 4. Process waiting list (pending -> active)
+	w.processWaitingList(ctx)
 
-			// Create conversation between winner and seller first
-			conversationID, err := w.notificationSvc.CreateConversation(ctx, auctionID, sellerID, winnerID)
-			if err != nil {
-				log.Printf("Error creating conversation: %v", err)
-			} else {
-				log.Printf("💬 Created conversation %s for auction %s", conversationID, auctionID)
-			}
+	// 4b. Refund skip-queue and extra-slot purchases that could not be used
+	w.refundUnfulfilledSlotPurchases(ctx)
 
-			// Send notification to winner
-			w.notificationSvc.SendAuctionWonNotification(ctx, winnerID, auctionID, conversationID, title, finalAmount)
+	// 5. Expire unanswered second-chance offers
+	w.expireSecondChanceOffers(ctx)
 
-			// Send notification to seller
-			w.notificationSvc.SendAuctionSoldNotification(ctx, sellerID, auctionID, conversationID, title, winnerName, finalAmount)
+	// 6. Expire offers that timed out or whose auction is over
+	w.expireOffers(ctx)
 
-			// Evaluate badges for seller and winner
-			go w.badgeWorker.EvaluateUserBadges(sellerID)
-			go w.badgeWorker.EvaluateUserBadges(winnerID)
+	// 7. Orders: payment reminders, non-payment and release of held payments
+	w.remindUnpaidOrders(ctx)
+	w.cancelUnpaidOrders(ctx)
+	w.releaseDueOrders(ctx)
 
-			log.Printf("🏆 Auction won: %s (%s) by %s for R%.2f", title, auctionID, winnerName, finalAmount)
-		}
+	// 8. End promotions that ran out or whose auction closed
+	w.expirePromotions(ctx)
 
-		// Broadcast update to town
-		w.hub.BroadcastToTown(townID, websocket.MessageTypeAuctionUpdate, map[string]interface{}{
-			"action":     "auction_ended",
-			"auction_id": auctionID,
-			"title":      title,
-		})
-	}
+	// 9. Drop activity log entries past their retention
+	w.pruneActivityLog(ctx)
 }
 
 func (w *AuctionWorker) updateEndingSoon(ctx context.Context) {
@@ -189,74 +147,330 @@ func (w *AuctionWorker) updateEndingSoon(ctx context.Context) {
 }
 
 func (w *AuctionWorker) processWaitingList(ctx context.Context) {
-	// Get all categories/towns that have pending auctions
-	rows, err := w.db.Pool.Query(ctx,
-		"SELECT DISTINCT category_id, town_id FROM auctions WHERE status = 'pending'")
+	// Unused slot offers go back into the pool before free slots are counted
+	w.expireSlotClaims(ctx)
+
+	// Sellers who paid for an extra slot go live on top of the category limit
+	w.activateExtraSlotAuctions(ctx)
+
+	// Every category/town with someone queued, whether a pending auction or a waiting seller
+	rows, err := w.db.Pool.Query(ctx, `
+		SELECT category_id, town_id FROM auctions WHERE status = 'pending'
+		UNION
+		SELECT category_id, town_id FROM waiting_list WHERE status = 'waiting'`)
 	if err != nil {
 		return
 	}
-	defer rows.Close()
 
+	type queueKey struct{ categoryID, townID uuid.UUID }
+	var keys []queueKey
 	for rows.Next() {
-		var catID, townID uuid.UUID
-		if err := rows.Scan(&catID, &townID); err != nil {
+		var k queueKey
+		if err := rows.Scan(&k.categoryID, &k.townID); err != nil {
 			continue
 		}
+		keys = append(keys, k)
+	}
+	rows.Close()
+
+	for _, k := range keys {
+		free := w.slots.AvailableSlots(ctx, k.categoryID, k.townID)
+		if free > 0 {
+			queue, err := w.slots.Queue(ctx, k.categoryID, k.townID, free)
+			if err != nil {
+				log.Printf("Error loading queue for category %s: %v", k.categoryID, err)
+				continue
+			}
+
+			for _, item := range queue {
+				switch item.Kind {
+				case services.QueueItemAuction:
+					w.activateQueuedAuction(ctx, k.categoryID, k.townID, item)
+				case services.QueueItemWaiting:
+					w.offerSlot(ctx, item)
+				}
+			}
+		}
+
+		if err := w.slots.RenumberWaitingList(ctx, k.categoryID, k.townID); err != nil {
+			log.Printf("Error renumbering waiting list: %v", err)
+		}
+	}
+}
+
+// activateQueuedAuction takes a pending auction live for the duration the seller chose,
+// or the category default, within the category's current limits. It reports whether
+// the auction was still pending.
+func (w *AuctionWorker) activateQueuedAuction(ctx context.Context, categoryID, townID uuid.UUID, item services.QueueItem) bool {
+	startTime := time.Now()
+	hours := w.slots.DurationHours(ctx, categoryID, townID, item.DurationHours)
+	endTime := startTime.Add(time.Duration(hours) * time.Hour)
+
+	result, err := w.db.Pool.Exec(ctx,
+		"UPDATE auctions SET status = 'active', start_time = $1, end_time = $2, original_end_time = $2, updated_at = NOW() WHERE id = $3 AND status = 'pending'",
+		startTime, endTime, item.ID)
+	if err != nil || result.RowsAffected() == 0 {
+		return false
+	}
+
+	log.Printf("Auction activated from waiting list: %s (%s)", item.Title, item.ID)
 
-		// Check available slots
-		var maxActive, currentActive int
-		w.db.Pool.QueryRow(ctx,
-			"SELECT COALESCE(max_active_auctions, 10) FROM category_slots WHERE category_id = $1 AND town_id = $2",
-			catID, townID,
-		).Scan(&maxActive)
-		if maxActive == 0 {
-			maxActive = 10
+	if item.SkipQueue {
+		if err := w.slots.UseSkipQueuePurchase(ctx, item.ID); err != nil {
+			log.Printf("Error recording skip-queue use for %s: %v", item.ID, err)
 		}
+	}
 
-		w.db.Pool.QueryRow(ctx,
-			"SELECT COUNT(*) FROM auctions WHERE category_id = $1 AND town_id = $2 AND status IN ('active', 'ending_soon')",
-			catID, townID,
-		).Scan(&currentActive)
+	w.hub.BroadcastToTown(townID, websocket.MessageTypeAuctionUpdate, map[string]interface{}{
+		"action":     "new_auction",
+		"auction_id": item.ID,
+		"title":      item.Title,
+	})
 
-		if currentActive < maxActive {
-			slotsAvailable := maxActive - currentActive
+	// Queued auctions (including scheduled ones that found the category full)
+	// reach their audience the moment they finally go live
+	w.notificationSvc.SendAuctionLiveNotifications(ctx, item.UserID, item.ID, item.Title)
+	return true
+}
 
-			// Find oldest pending auctions
-			pendingRows, err := w.db.Pool.Query(ctx,
-				"SELECT id, title FROM auctions WHERE category_id = $1 AND town_id = $2 AND status = 'pending' ORDER BY created_at ASC LIMIT $3",
-				catID, townID, slotsAvailable)
-			if err != nil {
+// activateExtraSlotAuctions starts pending auctions whose seller holds an unused extra
+// slot in the category, oldest auction first
+func (w *AuctionWorker) activateExtraSlotAuctions(ctx context.Context) {
+	rows, err := w.db.Pool.Query(ctx, `
+		SELECT sp.id, a.id, a.seller_id, a.title, COALESCE(a.duration_hours, 0), a.category_id, a.town_id
+		FROM slot_purchases sp
+		JOIN auctions a ON a.seller_id = sp.user_id AND a.category_id = sp.category_id
+			AND a.town_id = sp.town_id AND a.status = 'pending'
+		WHERE sp.purchase_type = 'extra_slot' AND sp.status = 'completed' AND sp.auction_id IS NULL
+		ORDER BY sp.created_at ASC, a.created_at ASC`)
+	if err != nil {
+		log.Printf("Error loading extra slot purchases: %v", err)
+		return
+	}
+
+	type extraSlotMatch struct {
+		purchaseID, categoryID, townID uuid.UUID
+		item                           services.QueueItem
+	}
+	var matches []extraSlotMatch
+	for rows.Next() {
+		m := extraSlotMatch{item: services.QueueItem{Kind: services.QueueItemAuction}}
+		if err := rows.Scan(&m.purchaseID, &m.item.ID, &m.item.UserID, &m.item.Title, &m.item.DurationHours,
+			&m.categoryID, &m.townID); err != nil {
+			continue
+		}
+		matches = append(matches, m)
+	}
+	rows.Close()
+
+	// A purchase matches every pending auction of its seller; each is used once, and an
+	// auction already started by an earlier purchase is skipped
+	used := make(map[uuid.UUID]bool)
+	for _, m := range matches {
+		if used[m.purchaseID] || !w.activateQueuedAuction(ctx, m.categoryID, m.townID, m.item) {
+			continue
+		}
+		used[m.purchaseID] = true
+		if err := w.slots.UseExtraSlot(ctx, m.purchaseID, m.item.ID); err != nil {
+			log.Printf("Error recording extra slot use for %s: %v", m.item.ID, err)
+		}
+	}
+}
+
+// offerSlot reserves a free slot for a waiting seller, who has SlotClaimWindow to list into it
+func (w *AuctionWorker) offerSlot(ctx context.Context, item services.QueueItem) {
+	var categoryID, townID uuid.UUID
+	var expiresAt time.Time
+	err := w.db.Pool.QueryRow(ctx, `
+		UPDATE waiting_list
+		SET status = 'slot_available', notified_at = NOW(), expires_at = $1
+		WHERE id = $2 AND status = 'waiting'
+		RETURNING category_id, town_id, expires_at`,
+		time.Now().Add(services.SlotClaimWindow), item.ID,
+	).Scan(&categoryID, &townID, &expiresAt)
+	if err != nil {
+		return
+	}
+
+	// The skip did its job once it got the seller a slot
+	if item.SkipQueue {
+		if err := w.slots.MarkSkipQueueUsed(ctx, item.UserID, categoryID, townID); err != nil {
+			log.Printf("Error recording skip-queue use for %s: %v", item.UserID, err)
+		}
+	}
+
+	var categoryName string
+	w.db.Pool.QueryRow(ctx, "SELECT name FROM categories WHERE id = $1", categoryID).Scan(&categoryName)
+
+	log.Printf("🎟️ Slot offered to waiting seller %s in %s", item.UserID, categoryName)
+	w.notificationSvc.SendSlotAvailableNotification(ctx, item.UserID, item.ID, categoryID, categoryName, expiresAt)
+}
+
+// expireSlotClaims releases slot offers that were not used within the claim window
+func (w *AuctionWorker) expireSlotClaims(ctx context.Context) {
+	rows, err := w.db.Pool.Query(ctx, `
+		UPDATE waiting_list wl
+		SET status = 'expired'
+		FROM categories cat
+		WHERE cat.id = wl.category_id AND wl.status = 'slot_available' AND wl.expires_at <= NOW()
+		RETURNING wl.id, wl.user_id, wl.category_id, cat.name`)
+	if err != nil {
+		log.Printf("Error expiring slot claims: %v", err)
+		return
+	}
+
+	type expiredClaim struct {
+		id, userID, categoryID uuid.UUID
+		categoryName           string
+	}
+	var expired []expiredClaim
+	for rows.Next() {
+		var e expiredClaim
+		if err := rows.Scan(&e.id, &e.userID, &e.categoryID, &e.categoryName); err != nil {
+			continue
+		}
+		expired = append(expired, e)
+	}
+	rows.Close()
+
+	for _, e := range expired {
+		w.notificationSvc.SendSlotExpiredNotification(ctx, e.userID, e.id, e.categoryID, e.categoryName)
+	}
+	if len(expired) > 0 {
+		log.Printf("⌛ Expired %d unused slot offers", len(expired))
+	}
+}
+
+// activateScheduledAuctions starts scheduled auctions whose start_time has passed.
+// An auction that finds its category full is queued as pending and picked up by
+// processWaitingList when a slot frees up.
+func (w *AuctionWorker) activateScheduledAuctions(ctx context.Context) {
+	rows, err := w.db.Pool.Query(ctx, `
+		SELECT id, title, seller_id, category_id, town_id, COALESCE(duration_hours, 0)
+		FROM auctions
+		WHERE status = 'scheduled' AND start_time <= NOW()
+		ORDER BY start_time ASC
+	`)
+	if err != nil {
+		return
+	}
+
+	type scheduledAuction struct {
+		id, sellerID, categoryID, townID uuid.UUID
+		title                            string
+		durationHours                    int
+	}
+	var due []scheduledAuction
+	for rows.Next() {
+		var a scheduledAuction
+		if err := rows.Scan(&a.id, &a.title, &a.sellerID, &a.categoryID, &a.townID, &a.durationHours); err != nil {
+			continue
+		}
+		due = append(due, a)
+	}
+	rows.Close()
+
+	for _, a := range due {
+		// Capacity is re-checked per auction since each activation takes a slot.
+		// A seller holding a waiting-list slot offer goes live in that slot.
+		grant, ok := w.slots.CanActivate(ctx, a.sellerID, a.categoryID, a.townID)
+		if !ok {
+			result, err := w.db.Pool.Exec(ctx,
+				"UPDATE auctions SET status = 'pending', updated_at = NOW() WHERE id = $1 AND status = 'scheduled'",
+				a.id)
+			if err != nil || result.RowsAffected() == 0 {
 				continue
 			}
+			w.notificationSvc.SendAuctionQueuedNotification(ctx, a.sellerID, a.id, a.title)
+			log.Printf("⏳ Scheduled auction queued, category full: %s (%s)", a.title, a.id)
+			continue
+		}
 
-			var activatedIDs []uuid.UUID
-			for pendingRows.Next() {
-				var pID uuid.UUID
-				var pTitle string
-				pendingRows.Scan(&pID, &pTitle)
-
-				// Activate: reset start/end time
-				// original duration is usually 7 days (168h)
-				duration := 168 * time.Hour
-				startTime := time.Now()
-				endTime := startTime.Add(duration)
-
-				_, err := w.db.Pool.Exec(ctx,
-					"UPDATE auctions SET status = 'active', start_time = $1, end_time = $2, original_end_time = $2 WHERE id = $3",
-					startTime, endTime, pID)
-
-				if err == nil {
-					activatedIDs = append(activatedIDs, pID)
-					log.Printf("Auction activated from waiting list: %s (%s)", pTitle, pID)
-
-					w.hub.BroadcastToTown(townID, websocket.MessageTypeAuctionUpdate, map[string]interface{}{
-						"action":     "new_auction",
-						"auction_id": pID,
-						"title":      pTitle,
-					})
-				}
-			}
-			pendingRows.Close()
+		startTime := time.Now()
+		hours := w.slots.DurationHours(ctx, a.categoryID, a.townID, a.durationHours)
+		endTime := startTime.Add(time.Duration(hours) * time.Hour)
+		result, err := w.db.Pool.Exec(ctx, `
+			UPDATE auctions
+			SET status = 'active', start_time = $1, end_time = $2, original_end_time = $2, updated_at = NOW()
+			WHERE id = $3 AND status = 'scheduled'
+		`, startTime, endTime, a.id)
+		if err != nil {
+			log.Printf("Error activating scheduled auction %s: %v", a.id, err)
+			continue
+		}
+		if result.RowsAffected() == 0 {
+			// Cancelled or edited back to draft since we read it
+			continue
+		}
+
+		log.Printf("🚀 Scheduled auction live: %s (%s)", a.title, a.id)
+
+		if err := w.slots.ConsumeGrant(ctx, grant, a.id); err != nil {
+			log.Printf("Error recording slot used by auction %s: %v", a.id, err)
 		}
+
+		w.hub.BroadcastToTown(a.townID, websocket.MessageTypeAuctionUpdate, map[string]interface{}{
+			"action":     "new_auction",
+			"auction_id": a.id,
+			"title":      a.title,
+		})
+
+		w.notificationSvc.SendAuctionLiveNotifications(ctx, a.sellerID, a.id, a.title)
+	}
+}
+
+func (w *AuctionWorker) expireSecondChanceOffers(ctx context.Context) {
+	result, err := w.db.Pool.Exec(ctx, `
+		UPDATE second_chance_offers
+		SET status = 'expired'
+		WHERE status = 'pending' AND expires_at <= NOW()
+	`)
+	if err != nil {
+		log.Printf("Error expiring second-chance offers: %v", err)
+		return
+	}
+	if result.RowsAffected() > 0 {
+		log.Printf("⏰ Expired %d second-chance offers", result.RowsAffected())
+	}
+}
+
+func (w *AuctionWorker) expireOffers(ctx context.Context) {
+	rows, err := w.db.Pool.Query(ctx, `
+		UPDATE auction_offers o
+		SET status = 'expired', updated_at = NOW()
+		FROM auctions a
+		WHERE a.id = o.auction_id
+		AND o.status IN ('pending', 'countered')
+		AND (o.expires_at <= NOW() OR a.status NOT IN ('active', 'ending_soon'))
+		RETURNING o.id, o.auction_id, o.buyer_id, o.seller_id, o.amount, o.counter_amount, o.status, o.expires_at, a.title
+	`)
+	if err != nil {
+		log.Printf("Error expiring offers: %v", err)
+		return
+	}
+
+	type expiredOffer struct {
+		offer models.Offer
+		title string
+	}
+	var expired []expiredOffer
+	for rows.Next() {
+		var e expiredOffer
+		if err := rows.Scan(&e.offer.ID, &e.offer.AuctionID, &e.offer.BuyerID, &e.offer.SellerID, &e.offer.Amount,
+			&e.offer.CounterAmount, &e.offer.Status, &e.offer.ExpiresAt, &e.title); err != nil {
+			continue
+		}
+		expired = append(expired, e)
+	}
+	rows.Close()
+
+	// Both sides learn the negotiation is over
+	for i := range expired {
+		e := &expired[i]
+		w.notificationSvc.SendOfferNotification(ctx, e.offer.BuyerID, websocket.MessageTypeOfferExpired, &e.offer, e.title)
+		w.notificationSvc.SendOfferNotification(ctx, e.offer.SellerID, websocket.MessageTypeOfferExpired, &e.offer, e.title)
+	}
+	if len(expired) > 0 {
+		log.Printf("⏰ Expired %d offers", len(expired))
 	}
 }
@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/services"
+	"github.com/google/uuid"
+)
+
+const (
+	// fraudScanBatch is how many users one run analyses at most
+	fraudScanBatch = 200
+	// fraudMetricsMaxAge is how often recent bidders are re-analysed even without new bids
+	fraudMetricsMaxAge = 24 * time.Hour
+)
+
+// FraudWorker analyses recent bidders for shill bidding and other fraud patterns
+type FraudWorker struct {
+	db    *database.DB
+	fraud *services.FraudService
+}
+
+// NewFraudWorker creates a new fraud worker
+func NewFraudWorker(db *database.DB) *FraudWorker {
+	return &FraudWorker{db: db, fraud: services.NewFraudService(db)}
+}
+
+// Start begins the fraud scan loop
+func (w *FraudWorker) Start(ctx context.Context) {
+	log.Println("🕵️ Fraud Worker started")
+
+	w.scan(ctx)
+
+	// Then run every 10 minutes
+	ticker := time.NewTicker(10 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🕵️ Fraud Worker stopped")
+			return
+		case <-ticker.C:
+			w.scan(ctx)
+		}
+	}
+}
+
+// scan analyses users who bid in the last 30 days and have bid since they were last
+// analysed, or were last analysed over a day ago
+func (w *FraudWorker) scan(ctx context.Context) {
+	rows, err := w.db.Pool.Query(ctx, `
+		SELECT b.bidder_id
+		FROM bids b
+		LEFT JOIN user_behavior_metrics m ON m.user_id = b.bidder_id
+		WHERE b.created_at > $1
+		GROUP BY b.bidder_id, m.last_calculated_at
+		HAVING m.last_calculated_at IS NULL
+			OR m.last_calculated_at < MAX(b.created_at)
+			OR m.last_calculated_at < $2
+		ORDER BY MAX(b.created_at) DESC
+		LIMIT $3`,
+		time.Now().AddDate(0, 0, -30), time.Now().Add(-fraudMetricsMaxAge), fraudScanBatch,
+	)
+	if err != nil {
+		log.Printf("Error finding users to analyse for fraud: %v", err)
+		return
+	}
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err == nil {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	rows.Close()
+
+	flagged := 0
+	for _, userID := range userIDs {
+		if ctx.Err() != nil {
+			return
+		}
+		metrics, err := w.fraud.Analyze(ctx, userID)
+		if err != nil {
+			log.Printf("Error analysing user %s for fraud: %v", userID, err)
+			continue
+		}
+		if metrics.RiskLevel == "high" || metrics.RiskLevel == "critical" {
+			flagged++
+		}
+	}
+
+	if len(userIDs) > 0 {
+		log.Printf("🕵️ Analysed %d bidders for fraud, %d high risk", len(userIDs), flagged)
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/services"
+)
+
+// JobWorker runs each background job once its interval has passed since its last run
+type JobWorker struct {
+	jobs *services.JobService
+}
+
+// NewJobWorker creates a new job worker
+func NewJobWorker(db *database.DB) *JobWorker {
+	return &JobWorker{jobs: services.NewJobService(db)}
+}
+
+// Start begins the job scheduling loop
+func (w *JobWorker) Start(ctx context.Context) {
+	log.Println("🗓️ Job Worker started")
+
+	w.runDue(ctx)
+
+	// Schedules are in hours; checking every 15 minutes is close enough
+	ticker := time.NewTicker(15 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("🗓️ Job Worker stopped")
+			return
+		case <-ticker.C:
+			w.runDue(ctx)
+		}
+	}
+}
+
+// runDue runs every job that is due
+func (w *JobWorker) runDue(ctx context.Context) {
+	for _, name := range w.jobs.Names() {
+		if ctx.Err() != nil || !w.jobs.Due(ctx, name) {
+			continue
+		}
+		run, err := w.jobs.Run(ctx, name, models.JobTriggerSchedule, nil)
+		if errors.Is(err, services.ErrJobRunning) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Error running job %s: %v", name, err)
+			continue
+		}
+		if run.Status == models.JobRunFailed {
+			log.Printf("Job %s failed: %s", name, *run.Error)
+			continue
+		}
+		log.Printf("🗓️ Job %s finished: %v", name, run.Result)
+	}
+}
@@ -0,0 +1,199 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/websocket"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// nextBidderOfferTTL is how long the next bidder has to take an item the winner did
+// not pay for, the same as a seller's second-chance offer
+const nextBidderOfferTTL = 48 * time.Hour
+
+// remindUnpaidOrders sends each buyer one payment reminder once their deadline is
+// within the reminder window
+func (w *AuctionWorker) remindUnpaidOrders(ctx context.Context) {
+	rows, err := w.db.Pool.Query(ctx, `
+		UPDATE orders o
+		SET payment_reminder_sent_at = NOW()
+		FROM auctions a
+		WHERE a.id = o.auction_id AND o.status = 'awaiting_payment' AND o.payment_reminder_sent_at IS NULL
+		AND o.payment_due_at > NOW() AND o.payment_due_at <= $1
+		RETURNING o.id, o.buyer_id, o.auction_id, o.amount, o.payment_due_at, a.title
+	`, time.Now().Add(w.orders.ReminderLead(ctx)))
+	if err != nil {
+		log.Printf("Error selecting orders to remind: %v", err)
+		return
+	}
+
+	type reminder struct {
+		orderID, buyerID, auctionID uuid.UUID
+		amount                      float64
+		dueAt                       time.Time
+		title                       string
+	}
+	var reminders []reminder
+	for rows.Next() {
+		var r reminder
+		if err := rows.Scan(&r.orderID, &r.buyerID, &r.auctionID, &r.amount, &r.dueAt, &r.title); err != nil {
+			continue
+		}
+		reminders = append(reminders, r)
+	}
+	rows.Close()
+
+	for _, r := range reminders {
+		if err := w.notificationSvc.SendPaymentReminderNotification(ctx, r.buyerID, r.orderID, r.auctionID, r.title, r.amount, r.dueAt); err != nil {
+			log.Printf("Error sending payment reminder for order %s: %v", r.orderID, err)
+		}
+	}
+}
+
+// cancelUnpaidOrders cancels orders whose payment deadline has passed, one
+// transaction per order
+func (w *AuctionWorker) cancelUnpaidOrders(ctx context.Context) {
+	for i := 0; i < settlementBatchSize; i++ {
+		cancelled, err := w.cancelNextUnpaidOrder(ctx)
+		if err != nil {
+			log.Printf("Error cancelling unpaid order: %v", err)
+			return
+		}
+		if !cancelled {
+			return
+		}
+	}
+}
+
+// cancelNextUnpaidOrder cancels one overdue order, marks its auction unpaid and offers
+// the item to the highest bidder who has not had it yet, at their own highest bid. No
+// offer goes out when that bid is below the reserve. A payment that completes after
+// this point is refunded by PaymentService.
+func (w *AuctionWorker) cancelNextUnpaidOrder(ctx context.Context) (bool, error) {
+	tx, err := w.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var orderID, auctionID, buyerID, sellerID uuid.UUID
+	var title string
+	var reservePrice *float64
+	err = tx.QueryRow(ctx, `
+		SELECT o.id, o.auction_id, o.buyer_id, o.seller_id, a.title, a.reserve_price
+		FROM orders o
+		JOIN auctions a ON a.id = o.auction_id
+		WHERE o.status = 'awaiting_payment' AND o.payment_due_at <= NOW()
+		ORDER BY o.payment_due_at ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`).Scan(&orderID, &auctionID, &buyerID, &sellerID, &title, &reservePrice)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim unpaid order: %w", err)
+	}
+
+	if _, err = tx.Exec(ctx, `
+		UPDATE orders
+		SET status = 'cancelled', cancelled_at = NOW(), cancel_reason = 'non_payment', updated_at = NOW()
+		WHERE id = $1
+	`, orderID); err != nil {
+		return false, fmt.Errorf("failed to cancel order %s: %w", orderID, err)
+	}
+
+	if _, err = tx.Exec(ctx, `
+		UPDATE auctions SET status = 'unpaid', winner_id = NULL, updated_at = NOW()
+		WHERE id = $1
+	`, auctionID); err != nil {
+		return false, fmt.Errorf("failed to mark auction %s unpaid: %w", auctionID, err)
+	}
+
+	// Earlier buyers and bidders who were already offered the item are skipped
+	var nextBidderID *uuid.UUID
+	var nextAmount float64
+	tx.QueryRow(ctx, `
+		SELECT b.bidder_id, b.amount
+		FROM bids b
+		WHERE b.auction_id = $1 AND b.bidder_id <> $2
+		AND NOT EXISTS (SELECT 1 FROM orders o WHERE o.auction_id = b.auction_id AND o.buyer_id = b.bidder_id)
+		AND NOT EXISTS (SELECT 1 FROM second_chance_offers s WHERE s.auction_id = b.auction_id AND s.bidder_id = b.bidder_id)
+		ORDER BY b.amount DESC, b.created_at ASC
+		LIMIT 1
+	`, auctionID, sellerID).Scan(&nextBidderID, &nextAmount)
+	if nextBidderID != nil && reservePrice != nil && nextAmount < *reservePrice {
+		nextBidderID = nil
+	}
+
+	var offerID uuid.UUID
+	if nextBidderID != nil {
+		if err = tx.QueryRow(ctx, `
+			INSERT INTO second_chance_offers (auction_id, seller_id, bidder_id, amount, expires_at, reason)
+			VALUES ($1, $2, $3, $4, $5, 'non_payment')
+			RETURNING id
+		`, auctionID, sellerID, *nextBidderID, nextAmount, time.Now().Add(nextBidderOfferTTL)).Scan(&offerID); err != nil {
+			return false, fmt.Errorf("failed to offer auction %s to the next bidder: %w", auctionID, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit cancellation of order %s: %w", orderID, err)
+	}
+
+	w.notificationSvc.SendOrderCancelledNotification(ctx, buyerID, orderID, auctionID, title, "buyer", false)
+	w.notificationSvc.SendOrderCancelledNotification(ctx, sellerID, orderID, auctionID, title, "seller", nextBidderID != nil)
+	if nextBidderID != nil {
+		w.notificationSvc.SendSecondChanceOfferNotification(ctx, *nextBidderID, offerID, auctionID, title, nextAmount)
+	}
+
+	w.hub.BroadcastToAuction(auctionID, websocket.MessageTypeAuctionUpdate, map[string]interface{}{
+		"action":     "status_change",
+		"auction_id": auctionID,
+		"status":     models.AuctionStatusUnpaid,
+	})
+
+	log.Printf("❌ Cancelled unpaid order %s for auction %s (%s)", orderID, title, auctionID)
+	return true, nil
+}
+
+// releaseDueOrders releases held payments of dispatched or collected orders whose
+// buyer did not confirm receipt within the release window
+func (w *AuctionWorker) releaseDueOrders(ctx context.Context) {
+	rows, err := w.db.Pool.Query(ctx, `
+		SELECT id FROM orders
+		WHERE status IN ('dispatched', 'collected') AND release_due_at <= NOW()
+		ORDER BY release_due_at ASC
+		LIMIT $1
+	`, settlementBatchSize)
+	if err != nil {
+		log.Printf("Error selecting orders to release: %v", err)
+		return
+	}
+
+	var orderIDs []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err == nil {
+			orderIDs = append(orderIDs, id)
+		}
+	}
+	rows.Close()
+
+	for _, id := range orderIDs {
+		released, err := w.orders.Release(ctx, id, false)
+		if err != nil {
+			log.Printf("Error releasing order %s: %v", id, err)
+			continue
+		}
+		if released {
+			log.Printf("✅ Released payment of order %s", id)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// expirePromotions switches off promotions that ran out or whose auction is no longer
+// live, clears is_featured on auctions left without a featured or pinned promotion and
+// tells each seller how their promotion did
+func (w *AuctionWorker) expirePromotions(ctx context.Context) {
+	rows, err := w.db.Pool.Query(ctx, `
+		UPDATE promoted_auctions pa
+		SET is_active = false, ended_at = NOW()
+		FROM auctions a
+		WHERE a.id = pa.auction_id AND pa.is_active = true
+		AND (pa.ends_at <= NOW() OR a.status NOT IN ('active', 'ending_soon'))
+		RETURNING pa.id, pa.auction_id, pa.user_id, pa.promotion_type, pa.impressions, pa.clicks, a.title
+	`)
+	if err != nil {
+		log.Printf("Error expiring promotions: %v", err)
+		return
+	}
+
+	type ended struct {
+		id, auctionID, sellerID uuid.UUID
+		promotionType           string
+		impressions, clicks     int
+		title                   string
+	}
+	var promotions []ended
+	for rows.Next() {
+		var p ended
+		if err := rows.Scan(&p.id, &p.auctionID, &p.sellerID, &p.promotionType, &p.impressions, &p.clicks, &p.title); err != nil {
+			continue
+		}
+		promotions = append(promotions, p)
+	}
+	rows.Close()
+
+	if len(promotions) == 0 {
+		return
+	}
+	log.Printf("📣 Ended %d promotions", len(promotions))
+
+	auctionIDs := make([]uuid.UUID, 0, len(promotions))
+	for _, p := range promotions {
+		auctionIDs = append(auctionIDs, p.auctionID)
+	}
+	if _, err := w.db.Pool.Exec(ctx, `
+		UPDATE auctions a SET is_featured = false
+		WHERE a.id = ANY($1) AND a.is_featured = true
+		AND NOT EXISTS (
+			SELECT 1 FROM promoted_auctions pa
+			WHERE pa.auction_id = a.id AND pa.is_active = true AND pa.ends_at > NOW()
+			AND pa.promotion_type IN ('featured', 'pinned')
+		)`,
+		auctionIDs,
+	); err != nil {
+		log.Printf("Error clearing featured flag of auctions: %v", err)
+	}
+
+	for _, p := range promotions {
+		if err := w.notificationSvc.SendPromotionEndedNotification(ctx, p.sellerID, p.id, p.auctionID, p.title, p.promotionType, p.impressions, p.clicks); err != nil {
+			log.Printf("Error sending promotion ended notification for %s: %v", p.id, err)
+		}
+	}
+}
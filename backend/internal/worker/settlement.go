@@ -0,0 +1,386 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/models"
+	"github.com/airmass/backend/internal/websocket"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// settlementBatchSize bounds how many auctions or outbox events one tick handles
+	settlementBatchSize = 100
+
+	// outboxMaxAttempts is how often a side effect is retried before it is marked failed
+	outboxMaxAttempts = 8
+
+	// outboxRetryBase is the first retry delay; it grows quadratically with attempts
+	outboxRetryBase = 30 * time.Second
+)
+
+// Settlement outcomes, stored in auction_settlements.outcome
+const (
+	settlementWon           = "won"
+	settlementNoBids        = "no_bids"
+	settlementReserveNotMet = "reserve_not_met"
+)
+
+// Outbox event types, delivered by deliverOutboxEvent
+const (
+	outboxCreateConversation = "create_conversation"
+	outboxNotifyWon          = "notify_won"
+	outboxNotifySold         = "notify_sold"
+	outboxNotifyNoBids       = "notify_no_bids"
+	outboxNotifyReserveSell  = "notify_reserve_not_met_seller"
+	outboxNotifyReserveBid   = "notify_reserve_not_met_bidder"
+	outboxEvaluateBadges     = "evaluate_badges"
+	outboxBroadcastEnded     = "broadcast_auction_ended"
+)
+
+// outboxPayload carries what a side effect needs beyond its settlement row
+type outboxPayload struct {
+	UserID uuid.UUID `json:"user_id,omitempty"`
+}
+
+// queuedEvent is an outbox event written by settleNextAuction
+type queuedEvent struct {
+	eventType string
+	payload   outboxPayload
+}
+
+// outboxEvent is a claimed settlement_outbox row joined with its settlement
+type outboxEvent struct {
+	id             int64
+	settlementID   uuid.UUID
+	auctionID      uuid.UUID
+	eventType      string
+	payload        outboxPayload
+	attempts       int
+	winnerID       *uuid.UUID
+	finalAmount    *float64
+	conversationID *uuid.UUID
+	sellerID       uuid.UUID
+	townID         uuid.UUID
+	title          string
+}
+
+// settleExpiredAuctions settles every auction whose end time has passed, one
+// transaction per auction
+func (w *AuctionWorker) settleExpiredAuctions(ctx context.Context) {
+	for i := 0; i < settlementBatchSize; i++ {
+		settled, err := w.settleNextAuction(ctx)
+		if err != nil {
+			log.Printf("Error settling auction: %v", err)
+			return
+		}
+		if !settled {
+			return
+		}
+	}
+}
+
+// settleNextAuction claims one expired auction and settles it: the auction status, the
+// auto-bid shutdown, the winner's order, the settlement record and its outbox events
+// commit together.
+// SKIP LOCKED lets several replicas settle different auctions at the same time.
+func (w *AuctionWorker) settleNextAuction(ctx context.Context) (bool, error) {
+	tx, err := w.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var auctionID, sellerID uuid.UUID
+	var title string
+	var reservePrice *float64
+	err = tx.QueryRow(ctx, `
+		SELECT id, title, seller_id, reserve_price
+		FROM auctions
+		WHERE status IN ('active', 'ending_soon') AND end_time <= NOW()
+		ORDER BY end_time ASC
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`).Scan(&auctionID, &title, &sellerID, &reservePrice)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim expired auction: %w", err)
+	}
+
+	// The leading bid. A tied proxy round writes the runner-up's bid and then the
+	// winner's at the same amount, so only is_winning names the winner.
+	var winnerID *uuid.UUID
+	var finalAmount *float64
+	tx.QueryRow(ctx, `
+		SELECT bidder_id, amount
+		FROM bids
+		WHERE auction_id = $1 AND is_winning = true
+	`, auctionID).Scan(&winnerID, &finalAmount)
+
+	outcome, status := settlementWon, "ended"
+	switch {
+	case winnerID == nil:
+		outcome = settlementNoBids
+	case reservePrice != nil && *finalAmount < *reservePrice:
+		// Highest bid is below the reserve - nobody wins
+		outcome, status = settlementReserveNotMet, "reserve_not_met"
+	}
+
+	auctionWinner := winnerID
+	if outcome != settlementWon {
+		auctionWinner = nil
+	}
+	if _, err = tx.Exec(ctx, `
+		UPDATE auctions
+		SET status = $1, winner_id = $2, final_amount = $3, updated_at = NOW()
+		WHERE id = $4
+	`, status, auctionWinner, finalAmount, auctionID); err != nil {
+		return false, fmt.Errorf("failed to end auction %s: %w", auctionID, err)
+	}
+
+	// Auto-bids stop with the auction
+	if _, err = tx.Exec(ctx, `
+		UPDATE auto_bids
+		SET is_active = false, deactivated_at = NOW(), deactivation_reason = 'auction_ended'
+		WHERE auction_id = $1 AND is_active = true
+	`, auctionID); err != nil {
+		return false, fmt.Errorf("failed to stop auto-bids for auction %s: %w", auctionID, err)
+	}
+
+	// The winner's order opens with the sale
+	if outcome == settlementWon {
+		if _, err = w.orders.Create(ctx, tx, auctionID, *winnerID, sellerID, *finalAmount, models.OrderSourceAuction); err != nil {
+			return false, err
+		}
+	}
+
+	var settlementID uuid.UUID
+	if err = tx.QueryRow(ctx, `
+		INSERT INTO auction_settlements (auction_id, outcome, winner_id, final_amount)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`, auctionID, outcome, auctionWinner, finalAmount).Scan(&settlementID); err != nil {
+		return false, fmt.Errorf("failed to record settlement for auction %s: %w", auctionID, err)
+	}
+
+	// Side effects in delivery order; the conversation must exist before the
+	// notifications that link to it
+	var events []queuedEvent
+	add := func(eventType string, userID uuid.UUID) {
+		events = append(events, queuedEvent{eventType: eventType, payload: outboxPayload{UserID: userID}})
+	}
+	switch outcome {
+	case settlementNoBids:
+		add(outboxNotifyNoBids, sellerID)
+	case settlementReserveNotMet:
+		// Seller and top bidder get different messages; the reserve amount is never disclosed
+		add(outboxNotifyReserveSell, sellerID)
+		add(outboxNotifyReserveBid, *winnerID)
+	case settlementWon:
+		add(outboxCreateConversation, uuid.Nil)
+		add(outboxNotifyWon, *winnerID)
+		add(outboxNotifySold, sellerID)
+		add(outboxEvaluateBadges, sellerID)
+		add(outboxEvaluateBadges, *winnerID)
+	}
+	add(outboxBroadcastEnded, uuid.Nil)
+
+	for _, e := range events {
+		payload, _ := json.Marshal(e.payload)
+		if _, err = tx.Exec(ctx, `
+			INSERT INTO settlement_outbox (settlement_id, auction_id, event_type, payload)
+			VALUES ($1, $2, $3, $4)
+		`, settlementID, auctionID, e.eventType, payload); err != nil {
+			return false, fmt.Errorf("failed to queue settlement event for auction %s: %w", auctionID, err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit settlement for auction %s: %w", auctionID, err)
+	}
+
+	switch outcome {
+	case settlementNoBids:
+		log.Printf("⏰ Auction ended with no bids: %s (%s)", title, auctionID)
+	case settlementReserveNotMet:
+		log.Printf("⏰ Auction ended below reserve: %s (%s) at R%.2f", title, auctionID, *finalAmount)
+	default:
+		log.Printf("🏆 Auction won: %s (%s) by %s for R%.2f", title, auctionID, *winnerID, *finalAmount)
+	}
+	return true, nil
+}
+
+// processSettlementOutbox delivers pending settlement side effects, one transaction per event
+func (w *AuctionWorker) processSettlementOutbox(ctx context.Context) {
+	for i := 0; i < settlementBatchSize; i++ {
+		processed, err := w.processNextOutboxEvent(ctx)
+		if err != nil {
+			log.Printf("Error processing settlement outbox: %v", err)
+			return
+		}
+		if !processed {
+			return
+		}
+	}
+}
+
+// processNextOutboxEvent claims the next deliverable event and runs it. The row stays
+// locked until the outcome is recorded, so no other replica can deliver it concurrently.
+// An event waits while an earlier event of the same settlement is still pending.
+func (w *AuctionWorker) processNextOutboxEvent(ctx context.Context) (bool, error) {
+	tx, err := w.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var e outboxEvent
+	var payload []byte
+	err = tx.QueryRow(ctx, `
+		SELECT o.id, o.settlement_id, o.auction_id, o.event_type, o.payload, o.attempts
+		FROM settlement_outbox o
+		WHERE o.status = 'pending' AND o.next_attempt_at <= NOW()
+		AND NOT EXISTS (
+			SELECT 1 FROM settlement_outbox p
+			WHERE p.settlement_id = o.settlement_id AND p.id < o.id AND p.status = 'pending'
+		)
+		ORDER BY o.id
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`).Scan(&e.id, &e.settlementID, &e.auctionID, &e.eventType, &payload, &e.attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to claim outbox event: %w", err)
+	}
+	json.Unmarshal(payload, &e.payload)
+
+	if err = tx.QueryRow(ctx, `
+		SELECT s.winner_id, s.final_amount, s.conversation_id, a.seller_id, a.town_id, a.title
+		FROM auction_settlements s
+		JOIN auctions a ON a.id = s.auction_id
+		WHERE s.id = $1
+	`, e.settlementID).Scan(&e.winnerID, &e.finalAmount, &e.conversationID, &e.sellerID, &e.townID, &e.title); err != nil {
+		return false, fmt.Errorf("failed to load settlement %s: %w", e.settlementID, err)
+	}
+
+	if deliverErr := w.deliverOutboxEvent(ctx, tx, &e); deliverErr != nil {
+		e.attempts++
+		status := "pending"
+		if e.attempts >= outboxMaxAttempts {
+			status = "failed"
+			log.Printf("❌ Settlement event %s for auction %s failed permanently: %v", e.eventType, e.auctionID, deliverErr)
+		}
+		retryAt := time.Now().Add(time.Duration(e.attempts*e.attempts) * outboxRetryBase)
+		if _, err = tx.Exec(ctx, `
+			UPDATE settlement_outbox
+			SET status = $1, attempts = $2, last_error = $3, next_attempt_at = $4
+			WHERE id = $5
+		`, status, e.attempts, deliverErr.Error(), retryAt, e.id); err != nil {
+			return false, fmt.Errorf("failed to record outbox failure: %w", err)
+		}
+	} else if _, err = tx.Exec(ctx, `
+		UPDATE settlement_outbox
+		SET status = 'done', attempts = attempts + 1, last_error = NULL, processed_at = NOW()
+		WHERE id = $1
+	`, e.id); err != nil {
+		return false, fmt.Errorf("failed to complete outbox event: %w", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("failed to commit outbox event: %w", err)
+	}
+	return true, nil
+}
+
+// deliverOutboxEvent performs one side effect. Effects that write rows check for their
+// own earlier result first, so a delivery repeated after a crash does not duplicate them.
+func (w *AuctionWorker) deliverOutboxEvent(ctx context.Context, tx pgx.Tx, e *outboxEvent) error {
+	switch e.eventType {
+	case outboxCreateConversation:
+		// CreateConversation returns the existing conversation if there is one
+		conversationID, err := w.notificationSvc.CreateConversation(ctx, e.auctionID, e.sellerID, *e.winnerID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			"UPDATE auction_settlements SET conversation_id = $1 WHERE id = $2",
+			conversationID, e.settlementID)
+		return err
+
+	case outboxNotifyWon:
+		if w.alreadyNotified(ctx, e.payload.UserID, e.auctionID, "auction_won") {
+			return nil
+		}
+		return w.notificationSvc.SendAuctionWonNotification(ctx, e.payload.UserID, e.auctionID,
+			uuidOrNil(e.conversationID), e.title, *e.finalAmount)
+
+	case outboxNotifySold:
+		if w.alreadyNotified(ctx, e.payload.UserID, e.auctionID, "auction_sold") {
+			return nil
+		}
+		var winnerName string
+		w.db.Pool.QueryRow(ctx, "SELECT full_name FROM users WHERE id = $1", *e.winnerID).Scan(&winnerName)
+		return w.notificationSvc.SendAuctionSoldNotification(ctx, e.payload.UserID, e.auctionID,
+			uuidOrNil(e.conversationID), e.title, winnerName, *e.finalAmount)
+
+	case outboxNotifyNoBids:
+		if w.alreadyNotified(ctx, e.payload.UserID, e.auctionID, "auction_ended") {
+			return nil
+		}
+		return w.notificationSvc.SendAuctionEndedNotification(ctx, e.payload.UserID, e.auctionID, e.title)
+
+	case outboxNotifyReserveSell:
+		if w.alreadyNotified(ctx, e.payload.UserID, e.auctionID, "reserve_not_met") {
+			return nil
+		}
+		return w.notificationSvc.SendReserveNotMetSellerNotification(ctx, e.payload.UserID, e.auctionID, e.title, *e.finalAmount)
+
+	case outboxNotifyReserveBid:
+		if w.alreadyNotified(ctx, e.payload.UserID, e.auctionID, "reserve_not_met") {
+			return nil
+		}
+		return w.notificationSvc.SendReserveNotMetBidderNotification(ctx, e.payload.UserID, e.auctionID, e.title, *e.finalAmount)
+
+	case outboxEvaluateBadges:
+		w.badgeWorker.EvaluateUserBadges(e.payload.UserID)
+		return nil
+
+	case outboxBroadcastEnded:
+		w.hub.BroadcastToTown(e.townID, websocket.MessageTypeAuctionUpdate, map[string]interface{}{
+			"action":     "auction_ended",
+			"auction_id": e.auctionID,
+			"title":      e.title,
+		})
+		return nil
+	}
+
+	return fmt.Errorf("unknown settlement event type %q", e.eventType)
+}
+
+// alreadyNotified reports whether a notification of this type was already stored for the
+// user and auction
+func (w *AuctionWorker) alreadyNotified(ctx context.Context, userID, auctionID uuid.UUID, notificationType string) bool {
+	var exists bool
+	w.db.Pool.QueryRow(ctx,
+		"SELECT EXISTS(SELECT 1 FROM notifications WHERE user_id = $1 AND related_auction_id = $2 AND type = $3)",
+		userID, auctionID, notificationType,
+	).Scan(&exists)
+	return exists
+}
+
+func uuidOrNil(id *uuid.UUID) uuid.UUID {
+	if id == nil {
+		return uuid.Nil
+	}
+	return *id
+}
@@ -0,0 +1,151 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/airmass/backend/internal/database"
+	"github.com/airmass/backend/internal/payments"
+	"github.com/airmass/backend/internal/services"
+	"github.com/airmass/backend/internal/websocket"
+	"github.com/google/uuid"
+)
+
+// TestSettleTiedProxyRound needs TEST_DATABASE_URL pointing at a disposable, fully
+// migrated database: it settles every expired auction it finds there, not only its own.
+func TestSettleTiedProxyRound(t *testing.T) {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+	db, err := database.New(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	suffix := uuid.NewString()[:8]
+	newUser := func(name string) uuid.UUID {
+		var id uuid.UUID
+		if err := db.Pool.QueryRow(ctx, `
+			INSERT INTO users (email, username, password_hash, full_name)
+			VALUES ($1, $2, 'x', $3) RETURNING id`,
+			name+"-"+suffix+"@example.com", name+"-"+suffix, name,
+		).Scan(&id); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Pool.Exec(ctx, "DELETE FROM users WHERE id = $1", id) })
+		return id
+	}
+	sellerID, firstID, secondID := newUser("seller"), newUser("first"), newUser("second")
+
+	var townID, categoryID, auctionID uuid.UUID
+	if err := db.Pool.QueryRow(ctx, "INSERT INTO towns (name) VALUES ($1) RETURNING id", "Settlement "+suffix).Scan(&townID); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Pool.Exec(ctx, "DELETE FROM towns WHERE id = $1", townID) })
+	if err := db.Pool.QueryRow(ctx, "INSERT INTO categories (name) VALUES ($1) RETURNING id", "Settlement "+suffix).Scan(&categoryID); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Pool.Exec(ctx, "DELETE FROM categories WHERE id = $1", categoryID) })
+	if err := db.Pool.QueryRow(ctx, `
+		INSERT INTO auctions (title, starting_price, seller_id, category_id, town_id, status, start_time, end_time, anti_snipe_minutes)
+		VALUES ('Tied proxy round', 10, $1, $2, $3, 'active', NOW(), NOW() + INTERVAL '1 hour', 0)
+		RETURNING id`,
+		sellerID, categoryID, townID,
+	).Scan(&auctionID); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Pool.Exec(ctx, "DELETE FROM auctions WHERE id = $1", auctionID) })
+
+	// Both bidders set the same maximum, first before second, each in its own bid
+	// transaction so max_set_at orders them
+	hub := websocket.NewHub()
+	bidding := services.NewBiddingService(db, hub, nil, services.NewBidIncrementService(db))
+	var outcome *services.ProxyBidOutcome
+	for _, bidderID := range []uuid.UUID{firstID, secondID} {
+		tx, err := db.Pool.Begin(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.Exec(ctx, "SELECT id FROM auctions WHERE id = $1 FOR UPDATE", auctionID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := bidding.UpsertAutoBid(ctx, tx, auctionID, bidderID, 40, ""); err != nil {
+			t.Fatal(err)
+		}
+		if outcome, err = bidding.ResolveAutoBids(ctx, tx, auctionID); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if outcome.HighBidderID == nil || *outcome.HighBidderID != firstID || outcome.FinalPrice != 40 {
+		t.Fatalf("proxy round left %v leading at %.2f, want the first bidder at 40", outcome.HighBidderID, outcome.FinalPrice)
+	}
+	var topBidder uuid.UUID
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT bidder_id FROM bids WHERE auction_id = $1 AND amount = 40
+		ORDER BY created_at ASC LIMIT 1`,
+		auctionID,
+	).Scan(&topBidder); err != nil {
+		t.Fatal(err)
+	}
+	if topBidder != secondID {
+		t.Fatalf("earliest bid at the top amount is by %s, want the runner-up's; the round no longer ties", topBidder)
+	}
+
+	if _, err := db.Pool.Exec(ctx, "UPDATE auctions SET end_time = NOW() - INTERVAL '1 second' WHERE id = $1", auctionID); err != nil {
+		t.Fatal(err)
+	}
+	provider, err := payments.NewFakeProvider("ZAR", "whsec_test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := NewAuctionWorker(db, hub, nil, provider)
+	for i := 0; i < settlementBatchSize; i++ {
+		settled, err := w.settleNextAuction(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !settled {
+			break
+		}
+	}
+
+	var status string
+	var winnerID, settledWinnerID, buyerID *uuid.UUID
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT a.status, a.winner_id, s.winner_id, o.buyer_id
+		FROM auctions a
+		LEFT JOIN auction_settlements s ON s.auction_id = a.id
+		LEFT JOIN orders o ON o.auction_id = a.id
+		WHERE a.id = $1`,
+		auctionID,
+	).Scan(&status, &winnerID, &settledWinnerID, &buyerID); err != nil {
+		t.Fatal(err)
+	}
+	if status != "ended" {
+		t.Fatalf("auction status = %s, want ended", status)
+	}
+	for name, got := range map[string]*uuid.UUID{"auction winner": winnerID, "settlement winner": settledWinnerID, "order buyer": buyerID} {
+		if got == nil || *got != firstID {
+			t.Errorf("%s = %v, want the first bidder %s", name, got, firstID)
+		}
+	}
+
+	var notified uuid.UUID
+	if err := db.Pool.QueryRow(ctx, `
+		SELECT (payload->>'user_id')::uuid FROM settlement_outbox
+		WHERE auction_id = $1 AND event_type = $2`,
+		auctionID, outboxNotifyWon,
+	).Scan(&notified); err != nil {
+		t.Fatal(err)
+	}
+	if notified != firstID {
+		t.Errorf("won notification goes to %s, want the first bidder %s", notified, firstID)
+	}
+}
@@ -0,0 +1,70 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/airmass/backend/internal/services"
+	"github.com/google/uuid"
+)
+
+// refundUnfulfilledSlotPurchases refunds paid slot purchases that can no longer be used:
+// skips whose seller has left the queue without the skip getting them a slot, and any
+// purchase still unused after services.SlotPurchaseFulfilmentWindow
+func (w *AuctionWorker) refundUnfulfilledSlotPurchases(ctx context.Context) {
+	rows, err := w.db.Pool.Query(ctx, `
+		SELECT sp.id, sp.created_at <= $1 AS stale
+		FROM slot_purchases sp
+		WHERE sp.status = 'completed' AND sp.auction_id IS NULL AND sp.processed_at IS NULL
+		AND (
+			sp.created_at <= $1
+			OR (
+				sp.purchase_type = 'skip_queue'
+				AND NOT EXISTS (
+					SELECT 1 FROM auctions a
+					WHERE a.seller_id = sp.user_id AND a.category_id = sp.category_id
+					AND a.town_id = sp.town_id AND a.status = 'pending'
+				)
+				AND NOT EXISTS (
+					SELECT 1 FROM waiting_list wl
+					WHERE wl.user_id = sp.user_id AND wl.category_id = sp.category_id
+					AND wl.town_id = sp.town_id AND wl.status IN ('waiting', 'slot_available')
+				)
+			)
+		)
+		ORDER BY sp.created_at ASC
+		LIMIT 50`,
+		time.Now().Add(-services.SlotPurchaseFulfilmentWindow),
+	)
+	if err != nil {
+		log.Printf("Error loading unfulfilled slot purchases: %v", err)
+		return
+	}
+
+	type refundCandidate struct {
+		id    uuid.UUID
+		stale bool
+	}
+	var candidates []refundCandidate
+	for rows.Next() {
+		var rc refundCandidate
+		if err := rows.Scan(&rc.id, &rc.stale); err != nil {
+			continue
+		}
+		candidates = append(candidates, rc)
+	}
+	rows.Close()
+
+	for _, rc := range candidates {
+		reason := "left_queue"
+		if rc.stale {
+			reason = "unused"
+		}
+		if err := w.slotPurchases.Refund(ctx, rc.id, reason); err != nil {
+			log.Printf("Error refunding slot purchase %s: %v", rc.id, err)
+			continue
+		}
+		log.Printf("💸 Refunded slot purchase %s (%s)", rc.id, reason)
+	}
+}
@@ -8,11 +8,20 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrTokenRevoked is returned for tokens whose session has been revoked
+var ErrTokenRevoked = errors.New("token revoked")
+
+// AccessCheck decides whether a validly signed token may still be used, returning
+// ErrTokenRevoked or another error when it may not
+type AccessCheck func(claims *Claims) error
+
 // Claims represents JWT claims
 type Claims struct {
-	UserID   uuid.UUID `json:"user_id"`
-	Email    string    `json:"email"`
-	Username string    `json:"username"`
+	UserID    uuid.UUID `json:"user_id"`
+	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role,omitempty"` // Admin role, empty for regular users
+	SessionID uuid.UUID `json:"sid"`            // Device session the token was issued for
 	jwt.RegisteredClaims
 }
 
@@ -20,6 +29,7 @@ type Claims struct {
 type Service struct {
 	secret      string
 	expiryHours int
+	check       AccessCheck
 }
 
 // NewService creates a new JWT service
@@ -30,15 +40,32 @@ func NewService(secret string, expiryHours int) *Service {
 	}
 }
 
-// GenerateToken creates a new JWT token
-func (s *Service) GenerateToken(userID uuid.UUID, email, username string) (string, int64, error) {
+// SetAccessCheck makes ValidateToken also run check on every token
+func (s *Service) SetAccessCheck(check AccessCheck) {
+	s.check = check
+}
+
+// CheckAccess runs the access check on parsed claims
+func (s *Service) CheckAccess(claims *Claims) error {
+	if s.check == nil {
+		return nil
+	}
+	return s.check(claims)
+}
+
+// GenerateToken creates a new JWT token for a device session. role is the user's
+// admin role, if any.
+func (s *Service) GenerateToken(userID uuid.UUID, email, username, role string, sessionID uuid.UUID) (string, int64, error) {
 	expiresAt := time.Now().Add(time.Duration(s.expiryHours) * time.Hour)
 
 	claims := &Claims{
-		UserID:   userID,
-		Email:    email,
-		Username: username,
+		UserID:    userID,
+		Email:     email,
+		Username:  username,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "airmass",
@@ -54,8 +81,21 @@ func (s *Service) GenerateToken(userID uuid.UUID, email, username string) (strin
 	return tokenString, expiresAt.Unix(), nil
 }
 
-// ValidateToken validates and parses a JWT token
+// ValidateToken validates and parses a JWT token, and checks it may still be used
 func (s *Service) ValidateToken(tokenString string) (*Claims, error) {
+	claims, err := s.Parse(tokenString)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.CheckAccess(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// Parse validates a JWT token's signature and expiry and returns its claims, without
+// the access check
+func (s *Service) Parse(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, errors.New("unexpected signing method")